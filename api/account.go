@@ -14,6 +14,18 @@ type getAccountRequest struct {
 	ID int64 `uri:"id" binding:"required,min=1"`
 }
 
+type accountResponse struct {
+	db.Account
+	AvailableBalance int64 `json:"available_balance"`
+}
+
+func newAccountResponse(account db.Account) accountResponse {
+	return accountResponse{
+		Account:          account,
+		AvailableBalance: account.Balance - account.HeldBalance,
+	}
+}
+
 func (s *Server) getAccount(ctx *gin.Context) {
 	var req getAccountRequest
 	if err := ctx.ShouldBindUri(&req); err != nil {
@@ -39,7 +51,7 @@ func (s *Server) getAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, newAccountResponse(account))
 }
 
 type listAccountRequest struct {
@@ -72,6 +84,7 @@ func (s *Server) listAccount(ctx *gin.Context) {
 
 type createAccountRequest struct {
 	Currency string `json:"currency" binding:"required,currency"`
+	Type     string `json:"type" binding:"omitempty,oneof=checking savings"`
 }
 
 func (s *Server) createAccount(ctx *gin.Context) {
@@ -81,14 +94,23 @@ func (s *Server) createAccount(ctx *gin.Context) {
 		return
 	}
 
+	accountType := req.Type
+	if accountType == "" {
+		accountType = db.AccountTypeChecking
+	}
+
 	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
 	arg := db.CreateAccountParams{
 		Owner:    authPayload.Username,
 		Currency: req.Currency,
 		Balance:  0,
+		Type:     accountType,
 	}
 
-	account, err := s.store.CreateAccount(ctx, arg)
+	result, err := s.store.CreateAccountTx(ctx, db.CreateAccountTxParams{
+		CreateAccountParams: arg,
+		Audit:               auditInfo(ctx, authPayload.Username),
+	})
 	if err != nil {
 		if pqErr, ok := err.(*pq.Error); ok {
 			switch pqErr.Code.Name() {
@@ -101,53 +123,7 @@ func (s *Server) createAccount(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, account)
-}
-
-type updateAccountRequestURI struct {
-	ID int64 `uri:"id" binding:"required,min=1"`
-}
-
-type updateAccountRequestJSON struct {
-	Balance int64 `json:"balance" binding:"required,min=1"`
-}
-
-func (s *Server) updateAccount(ctx *gin.Context) {
-	var reqJSON updateAccountRequestJSON
-	if err := ctx.ShouldBind(&reqJSON); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
-	}
-
-	var reqURI updateAccountRequestURI
-	if err := ctx.ShouldBindUri(&reqURI); err != nil {
-		ctx.JSON(http.StatusBadRequest, errorResponse(err))
-		return
-	}
-
-	authPayload := ctx.MustGet(authorizationPayloadKey).(*token.Payload)
-	account, err := s.store.GetAccount(ctx, reqURI.ID)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-		return
-	}
-
-	if authPayload.Username != account.Owner {
-		err = errors.New("account doesn't belong to the authenticated user")
-		ctx.JSON(http.StatusUnauthorized, errorResponse(err))
-		return
-	}
-
-	arg := db.UpdateAccountParams{
-		ID:      reqURI.ID,
-		Balance: reqJSON.Balance,
-	}
-	account, err = s.store.UpdateAccount(ctx, arg)
-	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
-	}
-
-	ctx.JSON(http.StatusOK, account)
+	ctx.JSON(http.StatusOK, result.Account)
 }
 
 type deleteAccountRequest struct {
@@ -174,13 +150,24 @@ func (s *Server) deleteAccount(ctx *gin.Context) {
 		return
 	}
 
-	err = s.store.DeleteAccount(ctx, req.ID)
+	// accounts are closed instead of deleted, their entries must be kept
+	_, err = s.store.ChangeAccountStatusTx(ctx, db.ChangeAccountStatusTxParams{
+		AccountID: req.ID,
+		Status:    db.AccountStatusClosed,
+		Reason:    "closed by the owner",
+		Audit:     auditInfo(ctx, authPayload.Username),
+	})
 	if err != nil {
+		if errors.Is(err, db.ErrAccountNotEmpty) || errors.Is(err, db.ErrInvalidAccountStatusChange) ||
+			errors.Is(err, db.ErrAccountFrozenByBanker) {
+			ctx.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
-		"message": "account deleted",
+		"message": "account closed",
 	})
 }
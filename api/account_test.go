@@ -33,7 +33,7 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "OK",
 			accountID: account.ID,
 			setupAuth: func(t *testing.T, r *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, r, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, r, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -50,7 +50,7 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "NotFound",
 			accountID: account.ID,
 			setupAuth: func(t *testing.T, r *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, r, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, r, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -66,7 +66,7 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "InternalError",
 			accountID: account.ID,
 			setupAuth: func(t *testing.T, r *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, r, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, r, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -82,7 +82,7 @@ func TestGetAccountAPI(t *testing.T) {
 			name:      "BadRequest",
 			accountID: 0,
 			setupAuth: func(t *testing.T, r *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, r, tokenMaker, authorizationTypeBearer, user.Username, time.Minute)
+				addAuthorization(t, r, tokenMaker, authorizationTypeBearer, user.Username, user.Role, time.Minute)
 			},
 			buildStubs: func(store *mockdb.MockStore) {
 				store.EXPECT().
@@ -122,10 +122,11 @@ func TestGetAccountAPI(t *testing.T) {
 
 func randomAccount(owner string) db.Account {
 	return db.Account{
-		ID:       util.RandomInt(1, 1000),
-		Owner:    owner,
-		Balance:  util.RandomMoney(),
-		Currency: util.RandomCurrency(),
+		ID:          util.RandomInt(1, 1000),
+		Owner:       owner,
+		Balance:     util.RandomMoney(),
+		Currency:    util.RandomCurrency(),
+		HeldBalance: util.RandomInt(0, 100),
 	}
 }
 
@@ -133,8 +134,9 @@ func requireBodyMatchAccount(t *testing.T, body *bytes.Buffer, account db.Accoun
 	data, err := io.ReadAll(body)
 	require.NoError(t, err)
 
-	var gotAccount db.Account
+	var gotAccount accountResponse
 	err = json.Unmarshal(data, &gotAccount)
 	require.NoError(t, err)
-	require.Equal(t, account, gotAccount)
+	require.Equal(t, account, gotAccount.Account)
+	require.Equal(t, account.Balance-account.HeldBalance, gotAccount.AvailableBalance)
 }
@@ -18,9 +18,10 @@ func addAuthorization(
 	tokenMaker token.Maker,
 	authorizationType string,
 	username string,
+	role string,
 	duration time.Duration,
 ) {
-	accessToken, payload, err := tokenMaker.CreateToken(username, duration)
+	accessToken, payload, err := tokenMaker.CreateToken(username, role, duration)
 	require.NoError(t, err)
 	require.NotEmpty(t, payload)
 
@@ -37,7 +38,7 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "OK",
 			setupAuth: func(t *testing.T, r *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, r, tokenMaker, authorizationTypeBearer, util.RandomOwner(), time.Minute)
+				addAuthorization(t, r, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.DepositorRole, time.Minute)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusOK, recorder.Code)
@@ -53,7 +54,7 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "UnsuportedAuthorization",
 			setupAuth: func(t *testing.T, r *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, r, tokenMaker, "unsupported", util.RandomOwner(), time.Minute)
+				addAuthorization(t, r, tokenMaker, "unsupported", util.RandomOwner(), util.DepositorRole, time.Minute)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -62,7 +63,7 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "InvalidAuthorizationFormat",
 			setupAuth: func(t *testing.T, r *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, r, tokenMaker, "", util.RandomOwner(), time.Minute)
+				addAuthorization(t, r, tokenMaker, "", util.RandomOwner(), util.DepositorRole, time.Minute)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
@@ -71,7 +72,7 @@ func TestAuthMiddleware(t *testing.T) {
 		{
 			name: "ExpiredToken",
 			setupAuth: func(t *testing.T, r *http.Request, tokenMaker token.Maker) {
-				addAuthorization(t, r, tokenMaker, authorizationTypeBearer, util.RandomOwner(), -time.Minute)
+				addAuthorization(t, r, tokenMaker, authorizationTypeBearer, util.RandomOwner(), util.DepositorRole, -time.Minute)
 			},
 			checkResponse: func(t *testing.T, recorder *httptest.ResponseRecorder) {
 				require.Equal(t, http.StatusUnauthorized, recorder.Code)
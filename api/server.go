@@ -56,7 +56,6 @@ func (s *Server) setupRouter() {
 	authRoutes.GET("/accounts/:id", s.getAccount)
 	authRoutes.GET("/accounts", s.listAccount)
 	authRoutes.POST("/accounts", s.createAccount)
-	authRoutes.PUT("/accounts/:id", s.updateAccount)
 	authRoutes.DELETE("/accounts/:id", s.deleteAccount)
 
 	authRoutes.POST("/transfers", s.createTransfer)
@@ -72,3 +71,12 @@ func (s *Server) Start(address string) error {
 func errorResponse(err error) gin.H {
 	return gin.H{"error": err.Error()}
 }
+
+// auditInfo describes who does an audited action and from which client
+func auditInfo(ctx *gin.Context, username string) db.AuditInfo {
+	return db.AuditInfo{
+		Actor:     username,
+		ClientIP:  ctx.ClientIP(),
+		UserAgent: ctx.Request.UserAgent(),
+	}
+}
@@ -66,6 +66,7 @@ func (s *Server) renewAccessToekn(c *gin.Context) {
 
 	accessToken, accessPayload, err := s.tokenMaker.CreateToken(
 		refreshPayload.Username,
+		refreshPayload.Role,
 		s.config.AccessTokenDuration,
 	)
 	if err != nil {
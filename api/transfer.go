@@ -45,9 +45,14 @@ func (s *Server) createTransfer(c *gin.Context) {
 		FromAccountID: req.FromAccountID,
 		ToAccountID:   req.ToAccountID,
 		Amount:        req.Amount,
+		Audit:         auditInfo(c, authPayload.Username),
 	}
 	result, err := s.store.TransferTx(c, arg)
 	if err != nil {
+		if errors.Is(err, db.ErrTransferLimitExceeded) || errors.Is(err, db.ErrAccountNotActive) {
+			c.JSON(http.StatusForbidden, errorResponse(err))
+			return
+		}
 		c.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
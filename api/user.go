@@ -111,6 +111,7 @@ func (s *Server) loginUser(c *gin.Context) {
 
 	accessToken, accessPayload, err := s.tokenMaker.CreateToken(
 		user.Username,
+		user.Role,
 		s.config.AccessTokenDuration,
 	)
 	if err != nil {
@@ -118,20 +119,24 @@ func (s *Server) loginUser(c *gin.Context) {
 		return
 	}
 
-	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(user.Username, s.config.RefreshTokenDuration)
+	refreshToken, refreshPayload, err := s.tokenMaker.CreateToken(user.Username, user.Role, s.config.RefreshTokenDuration)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(err))
 		return
 	}
 
-	session, err := s.store.CreateSession(c, db.CreateSessionParams{
-		ID:           refreshPayload.ID,
-		Username:     user.Username,
-		RefreshToken: refreshToken,
-		UserAgent:    c.Request.UserAgent(),
-		ClientIp:     c.ClientIP(),
-		IsBlocked:    false,
-		ExpiresAt:    refreshPayload.ExpiredAt,
+	audit := auditInfo(c, user.Username)
+	result, err := s.store.CreateSessionTx(c, db.CreateSessionTxParams{
+		CreateSessionParams: db.CreateSessionParams{
+			ID:           refreshPayload.ID,
+			Username:     user.Username,
+			RefreshToken: refreshToken,
+			UserAgent:    audit.UserAgent,
+			ClientIp:     audit.ClientIP,
+			IsBlocked:    false,
+			ExpiresAt:    refreshPayload.ExpiredAt,
+		},
+		Audit: audit,
 	})
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse(err))
@@ -139,7 +144,7 @@ func (s *Server) loginUser(c *gin.Context) {
 	}
 
 	rsp := loginUserResponse{
-		SessionID:             session.ID.String(),
+		SessionID:             result.Session.ID.String(),
 		AccessToken:           accessToken,
 		AccessTokenExpiresAt:  accessPayload.ExpiredAt,
 		RefreshToken:          refreshToken,
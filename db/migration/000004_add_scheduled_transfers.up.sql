@@ -0,0 +1,45 @@
+CREATE TABLE "scheduled_transfers"
+(
+    "id"              bigserial PRIMARY KEY,
+    "owner"           varchar     NOT NULL,
+    "from_account_id" bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "recurrence"      varchar     NOT NULL,
+    "start_at"        timestamptz NOT NULL,
+    "end_at"          timestamptz,
+    "next_run_at"     timestamptz NOT NULL,
+    "status"          varchar     NOT NULL DEFAULT 'active',
+    "failure_count"   int         NOT NULL DEFAULT 0,
+    "created_at"      timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "scheduled_transfer_executions"
+(
+    "id"                    bigserial PRIMARY KEY,
+    "scheduled_transfer_id" bigint      NOT NULL,
+    "transfer_id"           bigint,
+    "status"                varchar     NOT NULL,
+    "error"                 varchar     NOT NULL DEFAULT '',
+    "executed_at"           timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "scheduled_transfers"
+    ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+ALTER TABLE "scheduled_transfers"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "scheduled_transfers"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "scheduled_transfer_executions"
+    ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id");
+ALTER TABLE "scheduled_transfer_executions"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "scheduled_transfers" ("owner");
+CREATE INDEX ON "scheduled_transfers" ("status", "next_run_at");
+CREATE INDEX ON "scheduled_transfer_executions" ("scheduled_transfer_id");
+
+COMMENT ON COLUMN "scheduled_transfers"."recurrence" IS 'cron expression or @every interval';
+COMMENT ON COLUMN "scheduled_transfers"."status" IS 'active, paused, completed or cancelled';
+COMMENT ON COLUMN "scheduled_transfers"."failure_count" IS 'consecutive insufficient funds failures';
+COMMENT ON COLUMN "scheduled_transfer_executions"."status" IS 'succeeded or failed';
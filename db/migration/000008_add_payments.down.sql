@@ -0,0 +1,12 @@
+DROP TABLE IF EXISTS "payments";
+
+DELETE FROM "entries"
+WHERE "account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'simple_bank');
+
+DELETE FROM "transfers"
+WHERE "from_account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'simple_bank')
+   OR "to_account_id" IN (SELECT "id" FROM "accounts" WHERE "owner" = 'simple_bank');
+
+DELETE FROM "accounts" WHERE "owner" = 'simple_bank';
+
+DELETE FROM "users" WHERE "username" = 'simple_bank';
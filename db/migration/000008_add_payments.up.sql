@@ -0,0 +1,34 @@
+-- the system user owns one clearing account per currency, which is the
+-- counterparty of every deposit and withdrawal, so its balance goes negative
+-- by the amount of money held on behalf of the customers
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('simple_bank', '', 'Simple Bank', 'clearing@simplebank.local');
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+VALUES ('simple_bank', 0, 'USD'),
+       ('simple_bank', 0, 'EUR'),
+       ('simple_bank', 0, 'CAD');
+
+CREATE TABLE "payments"
+(
+    "id"           bigserial PRIMARY KEY,
+    "account_id"   bigint      NOT NULL,
+    "kind"         varchar     NOT NULL,
+    "amount"       bigint      NOT NULL,
+    "rail"         varchar     NOT NULL,
+    "external_ref" varchar     NOT NULL,
+    "transfer_id"  bigint      NOT NULL,
+    "created_at"   timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "payments"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "payments"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "payments" ("account_id");
+CREATE UNIQUE INDEX ON "payments" ("rail", "external_ref");
+
+COMMENT ON COLUMN "payments"."kind" IS 'deposit or withdrawal';
+COMMENT ON COLUMN "payments"."amount" IS 'must be positive';
+COMMENT ON COLUMN "payments"."external_ref" IS 'reference of the payment on the rail';
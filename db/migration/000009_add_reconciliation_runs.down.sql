@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS "reconciliation_runs";
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "transfer_id";
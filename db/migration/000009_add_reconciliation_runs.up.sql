@@ -0,0 +1,32 @@
+ALTER TABLE "entries" ADD COLUMN "transfer_id" bigint;
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "entries" ("transfer_id");
+
+-- the entries of a transfer are created in the same transaction,
+-- so they share its created_at which is the transaction start time
+UPDATE "entries" e
+SET "transfer_id" = t."id"
+FROM "transfers" t
+WHERE e."transfer_id" IS NULL
+  AND e."created_at" = t."created_at"
+  AND ((e."account_id" = t."from_account_id" AND e."amount" = -t."amount") OR
+       (e."account_id" = t."to_account_id" AND e."amount" = t."amount"));
+
+CREATE TABLE "reconciliation_runs"
+(
+    "id"                     bigserial PRIMARY KEY,
+    "accounts_checked"       bigint      NOT NULL,
+    "transfers_checked"      bigint      NOT NULL,
+    "account_discrepancies"  bigint      NOT NULL,
+    "transfer_discrepancies" bigint      NOT NULL,
+    "details"                jsonb       NOT NULL,
+    "started_at"             timestamptz NOT NULL,
+    "finished_at"            timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "reconciliation_runs" ("started_at");
+
+COMMENT ON COLUMN "entries"."transfer_id" IS 'transfer which posted the entry';
+COMMENT ON COLUMN "reconciliation_runs"."details" IS 'mismatching accounts and transfers';
@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS "reject_entry_truncate" ON "entries";
+DROP TRIGGER IF EXISTS "reject_entry_change" ON "entries";
+DROP TRIGGER IF EXISTS "chain_entry" ON "entries";
+
+DROP FUNCTION IF EXISTS reject_entry_change();
+DROP FUNCTION IF EXISTS chain_entry();
+DROP FUNCTION IF EXISTS entry_hash(bytea, bigint, bigint, bigint, bigint, timestamptz);
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "hash";
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "prev_hash";
@@ -0,0 +1,80 @@
+ALTER TABLE "entries" ADD COLUMN "prev_hash" bytea;
+ALTER TABLE "entries" ADD COLUMN "hash" bytea;
+
+-- entry_hash must stay in sync with db.ComputeEntryHash which verifies the chain
+CREATE FUNCTION entry_hash(prev_hash bytea, id bigint, account_id bigint, amount bigint, transfer_id bigint,
+                           created_at timestamptz) RETURNS bytea AS
+$$
+SELECT sha256(prev_hash || convert_to(concat_ws('|', id, account_id, amount, COALESCE(transfer_id::text, ''),
+                                                (EXTRACT(EPOCH FROM created_at) * 1000000)::bigint), 'UTF8'))
+$$ LANGUAGE sql IMMUTABLE;
+
+-- chain the existing entries of every account in order
+DO
+$$
+    DECLARE
+        e         record;
+        last_hash bytea;
+        last_id   bigint;
+    BEGIN
+        FOR e IN SELECT * FROM "entries" ORDER BY "account_id", "id"
+            LOOP
+                IF last_id IS DISTINCT FROM e.account_id THEN
+                    last_hash := ''::bytea;
+                    last_id := e.account_id;
+                END IF;
+                UPDATE "entries"
+                SET "prev_hash" = last_hash,
+                    "hash"      = entry_hash(last_hash, e.id, e.account_id, e.amount, e.transfer_id, e.created_at)
+                WHERE "id" = e.id
+                RETURNING "hash" INTO last_hash;
+            END LOOP;
+    END
+$$;
+
+ALTER TABLE "entries" ALTER COLUMN "prev_hash" SET NOT NULL;
+ALTER TABLE "entries" ALTER COLUMN "hash" SET NOT NULL;
+
+-- the hash of a new entry covers its contents and the hash of the previous entry of the account,
+-- the advisory lock serializes the inserts of an account so its chain cannot fork
+CREATE FUNCTION chain_entry() RETURNS trigger AS
+$$
+BEGIN
+    PERFORM pg_advisory_xact_lock(NEW.account_id);
+    NEW.prev_hash := COALESCE((SELECT "hash"
+                               FROM "entries"
+                               WHERE "account_id" = NEW.account_id
+                               ORDER BY "id" DESC
+                               LIMIT 1), ''::bytea);
+    NEW.hash := entry_hash(NEW.prev_hash, NEW.id, NEW.account_id, NEW.amount, NEW.transfer_id, NEW.created_at);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "chain_entry"
+    BEFORE INSERT
+    ON "entries"
+    FOR EACH ROW
+EXECUTE FUNCTION chain_entry();
+
+CREATE FUNCTION reject_entry_change() RETURNS trigger AS
+$$
+BEGIN
+    RAISE EXCEPTION 'entries are append-only, % is not allowed', TG_OP;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "reject_entry_change"
+    BEFORE UPDATE OR DELETE
+    ON "entries"
+    FOR EACH ROW
+EXECUTE FUNCTION reject_entry_change();
+
+CREATE TRIGGER "reject_entry_truncate"
+    BEFORE TRUNCATE
+    ON "entries"
+    FOR EACH STATEMENT
+EXECUTE FUNCTION reject_entry_change();
+
+COMMENT ON COLUMN "entries"."prev_hash" IS 'hash of the previous entry of the account, empty for the first one';
+COMMENT ON COLUMN "entries"."hash" IS 'sha256 of prev_hash and the entry contents';
@@ -0,0 +1,4 @@
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "fee_journal_id";
+ALTER TABLE IF EXISTS "transfers" DROP COLUMN IF EXISTS "fee";
+
+DROP TABLE IF EXISTS "fee_tiers";
\ No newline at end of file
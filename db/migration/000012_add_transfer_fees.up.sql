@@ -0,0 +1,46 @@
+-- the revenue accounts, one per currency, collect the fees charged on transfers,
+-- they are kept by the down migration since their entries cannot be removed, so their user
+-- may already exist, but a customer who took its name must not get the accounts
+DO
+$$
+BEGIN
+    IF EXISTS(SELECT 1 FROM "users" WHERE "username" = 'simple_bank_revenue' AND "hashed_password" <> '') THEN
+        RAISE EXCEPTION 'username simple_bank_revenue is taken by a customer';
+    END IF;
+END
+$$;
+
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('simple_bank_revenue', '', 'Simple Bank Revenue', 'revenue@simplebank.local')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+VALUES ('simple_bank_revenue', 0, 'USD'),
+       ('simple_bank_revenue', 0, 'EUR'),
+       ('simple_bank_revenue', 0, 'CAD')
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE "fee_tiers"
+(
+    "id"             bigserial PRIMARY KEY,
+    "currency"       varchar     NOT NULL,
+    "min_amount"     bigint      NOT NULL DEFAULT 0,
+    "flat_fee"       bigint      NOT NULL DEFAULT 0,
+    "percentage_bps" bigint      NOT NULL DEFAULT 0,
+    "min_fee"        bigint      NOT NULL DEFAULT 0,
+    "max_fee"        bigint,
+    "created_at"     timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE UNIQUE INDEX ON "fee_tiers" ("currency", "min_amount");
+
+ALTER TABLE "transfers" ADD COLUMN "fee" bigint NOT NULL DEFAULT 0;
+ALTER TABLE "transfers" ADD COLUMN "fee_journal_id" bigint;
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("fee_journal_id") REFERENCES "journals" ("id");
+
+COMMENT ON COLUMN "fee_tiers"."min_amount" IS 'smallest transfer amount the tier applies to';
+COMMENT ON COLUMN "fee_tiers"."percentage_bps" IS 'percentage of the amount in basis points';
+COMMENT ON COLUMN "fee_tiers"."max_fee" IS 'no maximum when null';
+COMMENT ON COLUMN "transfers"."fee" IS 'charged to the sender on top of the amount';
+COMMENT ON COLUMN "transfers"."fee_journal_id" IS 'journal which moved the fee to the revenue account';
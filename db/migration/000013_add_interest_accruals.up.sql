@@ -0,0 +1,55 @@
+ALTER TABLE "accounts" ADD COLUMN "type" varchar NOT NULL DEFAULT 'checking';
+
+-- an owner can have a checking and a savings account in the same currency
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "owner_currency_key";
+DROP INDEX IF EXISTS "accounts_owner_currency_idx";
+ALTER TABLE "accounts"
+    ADD CONSTRAINT "owner_currency_type_key" UNIQUE ("owner", "currency", "type");
+
+-- the interest expense accounts, one per currency, pay the interest of the savings accounts,
+-- they are kept by the down migration since their entries cannot be removed, so their user
+-- may already exist, but a customer who took its name must not get the accounts
+DO
+$$
+BEGIN
+    IF EXISTS(SELECT 1 FROM "users" WHERE "username" = 'simple_bank_interest' AND "hashed_password" <> '') THEN
+        RAISE EXCEPTION 'username simple_bank_interest is taken by a customer';
+    END IF;
+END
+$$;
+
+INSERT INTO "users" ("username", "hashed_password", "full_name", "email")
+VALUES ('simple_bank_interest', '', 'Simple Bank Interest', 'interest@simplebank.local')
+ON CONFLICT DO NOTHING;
+
+INSERT INTO "accounts" ("owner", "balance", "currency")
+VALUES ('simple_bank_interest', 0, 'USD'),
+       ('simple_bank_interest', 0, 'EUR'),
+       ('simple_bank_interest', 0, 'CAD')
+ON CONFLICT DO NOTHING;
+
+CREATE TABLE "interest_accruals"
+(
+    "id"           bigserial PRIMARY KEY,
+    "account_id"   bigint      NOT NULL,
+    "accrual_date" date        NOT NULL,
+    "balance"      bigint      NOT NULL,
+    "apr_bps"      bigint      NOT NULL,
+    "amount"       bigint      NOT NULL,
+    "transfer_id"  bigint,
+    "created_at"   timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "interest_accruals"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "interest_accruals"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE UNIQUE INDEX ON "interest_accruals" ("account_id", "accrual_date");
+CREATE INDEX ON "interest_accruals" ("transfer_id");
+
+COMMENT ON COLUMN "accounts"."type" IS 'checking or savings';
+COMMENT ON COLUMN "interest_accruals"."balance" IS 'balance the interest accrued on';
+COMMENT ON COLUMN "interest_accruals"."apr_bps" IS 'annual percentage rate in basis points';
+COMMENT ON COLUMN "interest_accruals"."amount" IS 'interest of the day rounded half to even';
+COMMENT ON COLUMN "interest_accruals"."transfer_id" IS 'monthly transfer which paid the interest, null until then';
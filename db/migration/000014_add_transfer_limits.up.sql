@@ -0,0 +1,31 @@
+CREATE TABLE "transfer_limits"
+(
+    "id"                bigserial PRIMARY KEY,
+    "username"          varchar,
+    "account_id"        bigint,
+    "max_single_amount" bigint,
+    "daily_amount"      bigint,
+    "monthly_amount"    bigint,
+    "hourly_count"      bigint,
+    "updated_at"        timestamptz NOT NULL DEFAULT (now()),
+    "created_at"        timestamptz NOT NULL DEFAULT (now()),
+    CHECK (("username" IS NULL) <> ("account_id" IS NULL))
+);
+
+ALTER TABLE "transfer_limits"
+    ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+ALTER TABLE "transfer_limits"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE UNIQUE INDEX ON "transfer_limits" ("username");
+CREATE UNIQUE INDEX ON "transfer_limits" ("account_id");
+
+-- the usage of the limits is computed from the outgoing transfers
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
+
+COMMENT ON COLUMN "transfer_limits"."username" IS 'limits all the accounts of the user together, null for account limits';
+COMMENT ON COLUMN "transfer_limits"."account_id" IS 'limits a single account, null for user limits';
+COMMENT ON COLUMN "transfer_limits"."max_single_amount" IS 'the limits are unlimited when null';
+COMMENT ON COLUMN "transfer_limits"."daily_amount" IS 'total amount per calendar day in UTC';
+COMMENT ON COLUMN "transfer_limits"."monthly_amount" IS 'total amount per calendar month in UTC';
+COMMENT ON COLUMN "transfer_limits"."hourly_count" IS 'number of transfers in the last hour';
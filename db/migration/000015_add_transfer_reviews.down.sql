@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "sessions_username_created_at_idx";
+
+DROP TABLE IF EXISTS "transfer_reviews";
+
+COMMENT ON COLUMN "accounts"."held_balance" IS 'sum of the authorized holds, the available balance is balance - held_balance';
\ No newline at end of file
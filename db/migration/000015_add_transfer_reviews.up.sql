@@ -0,0 +1,34 @@
+CREATE TABLE "transfer_reviews"
+(
+    "id"              bigserial PRIMARY KEY,
+    "from_account_id" bigint      NOT NULL,
+    "to_account_id"   bigint      NOT NULL,
+    "amount"          bigint      NOT NULL,
+    "reasons"         varchar     NOT NULL,
+    "status"          varchar     NOT NULL DEFAULT 'pending',
+    "reviewed_by"     varchar,
+    "reviewed_at"     timestamptz,
+    "transfer_id"     bigint,
+    "created_at"      timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "transfer_reviews"
+    ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "transfer_reviews"
+    ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "transfer_reviews"
+    ADD FOREIGN KEY ("reviewed_by") REFERENCES "users" ("username");
+ALTER TABLE "transfer_reviews"
+    ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+CREATE INDEX ON "transfer_reviews" ("status", "created_at");
+CREATE INDEX ON "transfer_reviews" ("from_account_id");
+
+-- the new device rule looks up the previous sessions of the user
+CREATE INDEX ON "sessions" ("username", "created_at");
+
+COMMENT ON COLUMN "accounts"."held_balance" IS 'sum of the authorized holds and of the transfers pending review, the available balance is balance - held_balance';
+COMMENT ON COLUMN "transfer_reviews"."amount" IS 'held on the source account while pending';
+COMMENT ON COLUMN "transfer_reviews"."reasons" IS 'risk rules which flagged the transfer';
+COMMENT ON COLUMN "transfer_reviews"."status" IS 'pending, approved or rejected';
+COMMENT ON COLUMN "transfer_reviews"."transfer_id" IS 'transfer executed on approval';
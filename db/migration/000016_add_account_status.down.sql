@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "account_status_changes";
+
+DROP INDEX IF EXISTS "accounts_owner_currency_type_key";
+ALTER TABLE IF EXISTS "accounts"
+    ADD CONSTRAINT "owner_currency_type_key" UNIQUE ("owner", "currency", "type");
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "status";
\ No newline at end of file
@@ -0,0 +1,26 @@
+ALTER TABLE "accounts" ADD COLUMN "status" varchar NOT NULL DEFAULT 'active';
+
+-- closed accounts are kept for their entries, so the owner can open a new account in their place
+ALTER TABLE "accounts" DROP CONSTRAINT IF EXISTS "owner_currency_type_key";
+CREATE UNIQUE INDEX "accounts_owner_currency_type_key" ON "accounts" ("owner", "currency", "type") WHERE "status" <> 'closed';
+
+CREATE TABLE "account_status_changes"
+(
+    "id"          bigserial PRIMARY KEY,
+    "account_id"  bigint      NOT NULL,
+    "from_status" varchar     NOT NULL,
+    "to_status"   varchar     NOT NULL,
+    "reason"      varchar     NOT NULL,
+    "changed_by"  varchar     NOT NULL,
+    "created_at"  timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "account_status_changes"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+ALTER TABLE "account_status_changes"
+    ADD FOREIGN KEY ("changed_by") REFERENCES "users" ("username");
+
+CREATE INDEX ON "account_status_changes" ("account_id");
+
+COMMENT ON COLUMN "accounts"."status" IS 'active, frozen or closed';
+COMMENT ON COLUMN "account_status_changes"."changed_by" IS 'banker or owner who changed the status';
\ No newline at end of file
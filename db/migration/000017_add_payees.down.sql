@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS "payees";
+
+DROP TRIGGER IF EXISTS "set_account_number" ON "accounts";
+DROP FUNCTION IF EXISTS set_account_number();
+
+ALTER TABLE IF EXISTS "accounts" DROP COLUMN IF EXISTS "number";
+DROP FUNCTION IF EXISTS account_number(bigint);
\ No newline at end of file
@@ -0,0 +1,47 @@
+ALTER TABLE "accounts" ADD COLUMN "number" varchar;
+
+-- account numbers are derived from the id, so they are known when the row is inserted
+CREATE FUNCTION account_number(id bigint) RETURNS varchar AS
+$$
+SELECT lpad(id::text, 12, '0')
+$$ LANGUAGE sql IMMUTABLE;
+
+UPDATE "accounts" SET "number" = account_number("id");
+
+ALTER TABLE "accounts" ALTER COLUMN "number" SET NOT NULL;
+ALTER TABLE "accounts"
+    ADD CONSTRAINT "accounts_number_key" UNIQUE ("number");
+
+CREATE FUNCTION set_account_number() RETURNS trigger AS
+$$
+BEGIN
+    NEW.number := account_number(NEW.id);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER "set_account_number"
+    BEFORE INSERT
+    ON "accounts"
+    FOR EACH ROW
+EXECUTE FUNCTION set_account_number();
+
+CREATE TABLE "payees"
+(
+    "id"         bigserial PRIMARY KEY,
+    "owner"      varchar     NOT NULL,
+    "nickname"   varchar     NOT NULL,
+    "account_id" bigint      NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+ALTER TABLE "payees"
+    ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+ALTER TABLE "payees"
+    ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+CREATE UNIQUE INDEX ON "payees" ("owner", "nickname");
+CREATE UNIQUE INDEX ON "payees" ("owner", "account_id");
+
+COMMENT ON COLUMN "accounts"."number" IS 'human-friendly number to share instead of the id';
+COMMENT ON COLUMN "payees"."nickname" IS 'name the owner gave to the payee';
\ No newline at end of file
@@ -0,0 +1,18 @@
+CREATE FUNCTION account_number(id bigint) RETURNS varchar AS
+$$
+SELECT lpad(id::text, 12, '0')
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION set_account_number() RETURNS trigger AS
+$$
+BEGIN
+    NEW.number := account_number(NEW.id);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+UPDATE "accounts" SET "number" = account_number("id");
+
+DROP FUNCTION IF EXISTS new_account_number();
+
+COMMENT ON COLUMN "accounts"."number" IS 'human-friendly number to share instead of the id';
\ No newline at end of file
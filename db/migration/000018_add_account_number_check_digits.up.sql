@@ -0,0 +1,29 @@
+-- account numbers are IBAN-style: the SB country code, the ISO 7064 mod 97-10 check digits
+-- and 12 random digits, so they cannot be guessed from the sequential ids,
+-- new_account_number must stay in sync with util.AccountNumber which validates them
+CREATE FUNCTION new_account_number() RETURNS varchar AS
+$$
+DECLARE
+    digits varchar := lpad(floor(random() * 1000000000000)::bigint::text, 12, '0');
+BEGIN
+    -- the check digits are computed with the country code moved after the digits, S = 28 and B = 11
+    RETURN 'SB' || lpad((98 - (digits || '281100')::numeric % 97)::text, 2, '0') || digits;
+END
+$$ LANGUAGE plpgsql VOLATILE;
+
+CREATE OR REPLACE FUNCTION set_account_number() RETURNS trigger AS
+$$
+BEGIN
+    LOOP
+        NEW.number := new_account_number();
+        EXIT WHEN NOT EXISTS (SELECT 1 FROM "accounts" WHERE "number" = NEW.number);
+    END LOOP;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+UPDATE "accounts" SET "number" = new_account_number();
+
+DROP FUNCTION account_number(bigint);
+
+COMMENT ON COLUMN "accounts"."number" IS 'IBAN-style number with check digits to share instead of the id';
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS "audit_events";
\ No newline at end of file
@@ -0,0 +1,23 @@
+CREATE TABLE "audit_events"
+(
+    "id"          bigserial PRIMARY KEY,
+    "actor"       varchar     NOT NULL,
+    "action"      varchar     NOT NULL,
+    "target_type" varchar     NOT NULL,
+    "target_id"   varchar     NOT NULL,
+    "before"      jsonb       NOT NULL DEFAULT '{}',
+    "after"       jsonb       NOT NULL DEFAULT '{}',
+    "client_ip"   varchar     NOT NULL DEFAULT '',
+    "user_agent"  varchar     NOT NULL DEFAULT '',
+    "created_at"  timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "audit_events" ("created_at");
+CREATE INDEX ON "audit_events" ("actor", "created_at");
+CREATE INDEX ON "audit_events" ("action", "created_at");
+CREATE INDEX ON "audit_events" ("target_type", "target_id", "created_at");
+
+COMMENT ON COLUMN "audit_events"."actor" IS 'username of the user who did the action, kept without a foreign key so the log is append only';
+COMMENT ON COLUMN "audit_events"."action" IS 'like user.updated or account.closed';
+COMMENT ON COLUMN "audit_events"."before" IS 'changed fields before the action, empty for creations';
+COMMENT ON COLUMN "audit_events"."after" IS 'changed fields after the action';
\ No newline at end of file
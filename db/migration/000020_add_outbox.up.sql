@@ -0,0 +1,19 @@
+CREATE TABLE "outbox"
+(
+    "id"         bigserial PRIMARY KEY,
+    "task_type"  varchar     NOT NULL,
+    "payload"    jsonb       NOT NULL,
+    "queue"      varchar     NOT NULL,
+    "max_retry"  integer     NOT NULL,
+    "process_at" timestamptz NOT NULL,
+    "attempts"   integer     NOT NULL DEFAULT 0,
+    "last_error" varchar     NOT NULL DEFAULT '',
+    "sent_at"    timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "outbox" ("id") WHERE "sent_at" IS NULL;
+
+COMMENT ON COLUMN "outbox"."process_at" IS 'when the task must be processed, it is published to the queue right away';
+COMMENT ON COLUMN "outbox"."attempts" IS 'failed attempts to publish the task';
+COMMENT ON COLUMN "outbox"."sent_at" IS 'when the task was published to the queue';
\ No newline at end of file
@@ -0,0 +1,44 @@
+CREATE TABLE "domain_events"
+(
+    "id"         bigserial PRIMARY KEY,
+    "event_type" varchar     NOT NULL,
+    "username"   varchar     NOT NULL REFERENCES "users" ("username"),
+    "data"       jsonb       NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_subscriptions"
+(
+    "id"          bigserial PRIMARY KEY,
+    "owner"       varchar     NOT NULL REFERENCES "users" ("username"),
+    "url"         varchar     NOT NULL,
+    "secret"      varchar     NOT NULL,
+    "event_types" varchar     NOT NULL DEFAULT '',
+    "is_active"   boolean     NOT NULL DEFAULT true,
+    "created_at"  timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_deliveries"
+(
+    "id"              bigserial PRIMARY KEY,
+    "subscription_id" bigint      NOT NULL REFERENCES "webhook_subscriptions" ("id"),
+    "event_id"        bigint      NOT NULL REFERENCES "domain_events" ("id"),
+    "status"          varchar     NOT NULL DEFAULT 'pending',
+    "attempts"        integer     NOT NULL DEFAULT 0,
+    "response_status" integer     NOT NULL DEFAULT 0,
+    "last_error"      varchar     NOT NULL DEFAULT '',
+    "delivered_at"    timestamptz,
+    "created_at"      timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE INDEX ON "domain_events" ("username", "created_at");
+CREATE INDEX ON "webhook_subscriptions" ("owner");
+CREATE INDEX ON "webhook_deliveries" ("subscription_id", "created_at");
+CREATE INDEX ON "webhook_deliveries" ("event_id");
+
+COMMENT ON COLUMN "domain_events"."event_type" IS 'like transfer.completed';
+COMMENT ON COLUMN "domain_events"."username" IS 'user notified of the event';
+COMMENT ON COLUMN "webhook_subscriptions"."secret" IS 'key signing the payloads with HMAC-SHA256';
+COMMENT ON COLUMN "webhook_subscriptions"."event_types" IS 'comma separated event types to deliver, all of them when empty';
+COMMENT ON COLUMN "webhook_deliveries"."status" IS 'pending, succeeded or failed';
+COMMENT ON COLUMN "webhook_deliveries"."response_status" IS 'HTTP status of the last attempt, 0 when no response was received';
\ No newline at end of file
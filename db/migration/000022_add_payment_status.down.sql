@@ -0,0 +1,13 @@
+DELETE FROM "payments" WHERE "transfer_id" IS NULL;
+
+DROP INDEX IF EXISTS "payments_status_created_at_idx";
+DROP INDEX IF EXISTS "payments_rail_external_ref_idx";
+CREATE UNIQUE INDEX ON "payments" ("rail", "external_ref");
+
+ALTER TABLE "payments" ALTER COLUMN "transfer_id" SET NOT NULL;
+ALTER TABLE "payments" ALTER COLUMN "external_ref" DROP DEFAULT;
+ALTER TABLE "payments" DROP COLUMN IF EXISTS "updated_at";
+ALTER TABLE "payments" DROP COLUMN IF EXISTS "refund_transfer_id";
+ALTER TABLE "payments" DROP COLUMN IF EXISTS "last_error";
+ALTER TABLE "payments" DROP COLUMN IF EXISTS "external_account";
+ALTER TABLE "payments" DROP COLUMN IF EXISTS "status";
\ No newline at end of file
@@ -0,0 +1,23 @@
+-- the payments recorded before are all settled
+ALTER TABLE "payments" ADD COLUMN "status" varchar NOT NULL DEFAULT 'completed';
+ALTER TABLE "payments" ALTER COLUMN "status" SET DEFAULT 'pending';
+ALTER TABLE "payments" ADD COLUMN "external_account" varchar NOT NULL DEFAULT '';
+ALTER TABLE "payments" ADD COLUMN "last_error" varchar NOT NULL DEFAULT '';
+ALTER TABLE "payments" ADD COLUMN "refund_transfer_id" bigint;
+ALTER TABLE "payments" ADD COLUMN "updated_at" timestamptz NOT NULL DEFAULT (now());
+ALTER TABLE "payments" ALTER COLUMN "external_ref" SET DEFAULT '';
+ALTER TABLE "payments" ALTER COLUMN "transfer_id" DROP NOT NULL;
+
+ALTER TABLE "payments"
+    ADD FOREIGN KEY ("refund_transfer_id") REFERENCES "transfers" ("id");
+
+-- pending payments have no reference on the rail yet
+DROP INDEX IF EXISTS "payments_rail_external_ref_idx";
+CREATE UNIQUE INDEX ON "payments" ("rail", "external_ref") WHERE "external_ref" <> '';
+CREATE INDEX ON "payments" ("status", "created_at");
+
+COMMENT ON COLUMN "payments"."status" IS 'pending, completed or failed';
+COMMENT ON COLUMN "payments"."external_account" IS 'funding source of a deposit or destination of a withdrawal';
+COMMENT ON COLUMN "payments"."external_ref" IS 'reference of the payment on the rail, empty until the rail accepts it';
+COMMENT ON COLUMN "payments"."transfer_id" IS 'transfer crediting a deposit once collected, or debiting a withdrawal before it is paid out';
+COMMENT ON COLUMN "payments"."refund_transfer_id" IS 'transfer giving the money of a failed withdrawal back';
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE "holds" DROP COLUMN IF EXISTS "fee";
+
+COMMENT ON COLUMN "accounts"."held_balance" IS 'sum of the authorized holds and of the transfers pending review, the available balance is balance - held_balance';
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- holds authorized before only reserved their amount, they are captured without a fee
+ALTER TABLE "holds" ADD COLUMN "fee" bigint NOT NULL DEFAULT 0;
+
+COMMENT ON COLUMN "holds"."fee" IS 'quoted when authorized, held with the amount and charged on capture';
+COMMENT ON COLUMN "accounts"."held_balance" IS 'sum of the authorized holds with their fees and of the transfers pending review, the available balance is balance - held_balance';
\ No newline at end of file
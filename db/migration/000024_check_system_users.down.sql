@@ -0,0 +1 @@
+-- the up migration only checks the users
\ No newline at end of file
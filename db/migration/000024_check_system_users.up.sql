@@ -0,0 +1,13 @@
+-- the system users were created with ON CONFLICT DO NOTHING, so a customer who registered one of
+-- their names first would own the clearing, revenue or interest accounts; customers always have a password
+DO
+$$
+BEGIN
+    IF EXISTS(SELECT 1
+              FROM "users"
+              WHERE "username" IN ('simple_bank', 'simple_bank_revenue', 'simple_bank_interest')
+                AND "hashed_password" <> '') THEN
+        RAISE EXCEPTION 'a system username is taken by a customer';
+    END IF;
+END
+$$;
\ No newline at end of file
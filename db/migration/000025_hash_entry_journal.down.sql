@@ -0,0 +1,18 @@
+-- the entries hashed with their journal fail the verification once their version is dropped
+CREATE OR REPLACE FUNCTION chain_entry() RETURNS trigger AS
+$$
+BEGIN
+    PERFORM pg_advisory_xact_lock(NEW.account_id);
+    NEW.prev_hash := COALESCE((SELECT "hash"
+                               FROM "entries"
+                               WHERE "account_id" = NEW.account_id
+                               ORDER BY "id" DESC
+                               LIMIT 1), ''::bytea);
+    NEW.hash := entry_hash(NEW.prev_hash, NEW.id, NEW.account_id, NEW.amount, NEW.transfer_id, NEW.created_at);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS entry_hash_v2(bytea, bigint, bigint, bigint, bigint, bigint, timestamptz);
+
+ALTER TABLE IF EXISTS "entries" DROP COLUMN IF EXISTS "hash_version";
\ No newline at end of file
@@ -0,0 +1,32 @@
+-- the hash of the entries only covered their transfer, so the journal of an entry could be changed unnoticed.
+-- The existing hashes are kept, rehashing them would also accept any change made to the entries so far,
+-- so hash_version tells which contents a hash covers and the new entries are hashed with their journal
+ALTER TABLE "entries" ADD COLUMN "hash_version" smallint NOT NULL DEFAULT 1;
+ALTER TABLE "entries" ALTER COLUMN "hash_version" SET DEFAULT 2;
+
+-- entry_hash_v2 must stay in sync with db.ComputeEntryHash which verifies the chain
+CREATE FUNCTION entry_hash_v2(prev_hash bytea, id bigint, account_id bigint, amount bigint, transfer_id bigint,
+                              journal_id bigint, created_at timestamptz) RETURNS bytea AS
+$$
+SELECT sha256(prev_hash || convert_to(concat_ws('|', 2, id, account_id, amount, COALESCE(transfer_id::text, ''),
+                                                COALESCE(journal_id::text, ''),
+                                                (EXTRACT(EPOCH FROM created_at) * 1000000)::bigint), 'UTF8'))
+$$ LANGUAGE sql IMMUTABLE;
+
+CREATE OR REPLACE FUNCTION chain_entry() RETURNS trigger AS
+$$
+BEGIN
+    PERFORM pg_advisory_xact_lock(NEW.account_id);
+    NEW.prev_hash := COALESCE((SELECT "hash"
+                               FROM "entries"
+                               WHERE "account_id" = NEW.account_id
+                               ORDER BY "id" DESC
+                               LIMIT 1), ''::bytea);
+    NEW.hash_version := 2;
+    NEW.hash := entry_hash_v2(NEW.prev_hash, NEW.id, NEW.account_id, NEW.amount, NEW.transfer_id, NEW.journal_id,
+                              NEW.created_at);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+COMMENT ON COLUMN "entries"."hash_version" IS '1 for the hashes of the contents without the journal, 2 for the hashes covering it';
\ No newline at end of file
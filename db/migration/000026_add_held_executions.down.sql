@@ -0,0 +1,7 @@
+-- the older code only knows succeeded and failed executions
+UPDATE "scheduled_transfer_executions"
+SET "status" = 'failed',
+    "error"  = 'held for review'
+WHERE "status" = 'held';
+
+COMMENT ON COLUMN "scheduled_transfer_executions"."status" IS 'succeeded or failed';
\ No newline at end of file
@@ -0,0 +1 @@
+COMMENT ON COLUMN "scheduled_transfer_executions"."status" IS 'succeeded, failed or held for review';
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- the messages written before carry no trace, their tasks start a new one
+ALTER TABLE "outbox" ADD COLUMN "trace_context" jsonb NOT NULL DEFAULT '{}';
+
+COMMENT ON COLUMN "outbox"."trace_context" IS 'W3C trace context of the transaction which wrote the message, continued by its task';
\ No newline at end of file
@@ -0,0 +1,4 @@
+-- the messages written before carry no request ID, their tasks are logged without one
+ALTER TABLE "outbox" ADD COLUMN "request_id" varchar NOT NULL DEFAULT '';
+
+COMMENT ON COLUMN "outbox"."request_id" IS 'ID of the request which wrote the message, empty for the background tasks';
\ No newline at end of file
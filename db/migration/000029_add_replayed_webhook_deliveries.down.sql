@@ -0,0 +1,6 @@
+-- the older code only knows failed deliveries, which it would let be replayed again
+UPDATE "webhook_deliveries"
+SET "status" = 'failed'
+WHERE "status" = 'replayed';
+
+COMMENT ON COLUMN "webhook_deliveries"."status" IS 'pending, succeeded or failed';
\ No newline at end of file
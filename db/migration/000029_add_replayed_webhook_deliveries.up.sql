@@ -0,0 +1 @@
+COMMENT ON COLUMN "webhook_deliveries"."status" IS 'pending, succeeded, failed or replayed by a new delivery';
\ No newline at end of file
@@ -0,0 +1,26 @@
+-- name: CreateAuditEvent :one
+INSERT INTO audit_events (
+    actor,
+    action,
+    target_type,
+    target_id,
+    before,
+    after,
+    client_ip,
+    user_agent
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: ListAuditEvents :many
+SELECT * FROM audit_events
+WHERE
+    (sqlc.arg(actor)::varchar = '' OR actor = sqlc.arg(actor)) AND
+    (sqlc.arg(action)::varchar = '' OR action = sqlc.arg(action)) AND
+    (sqlc.arg(target_type)::varchar = '' OR target_type = sqlc.arg(target_type)) AND
+    (sqlc.arg(target_id)::varchar = '' OR target_id = sqlc.arg(target_id)) AND
+    created_at >= sqlc.arg(start_time) AND
+    created_at < sqlc.arg(end_time)
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg(max_events)
+OFFSET sqlc.arg(skip_events);
\ No newline at end of file
@@ -0,0 +1,25 @@
+-- name: CreateFeeTier :one
+INSERT INTO fee_tiers (
+    currency,
+    min_amount,
+    flat_fee,
+    percentage_bps,
+    min_fee,
+    max_fee
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetFeeTier :one
+SELECT * FROM fee_tiers
+WHERE currency = sqlc.arg(currency) AND min_amount <= sqlc.arg(amount)
+ORDER BY min_amount DESC
+LIMIT 1;
+
+-- name: ListFeeTiers :many
+SELECT * FROM fee_tiers
+WHERE currency = $1
+ORDER BY min_amount;
+
+-- name: DeleteFeeTier :exec
+DELETE FROM fee_tiers WHERE id = $1;
\ No newline at end of file
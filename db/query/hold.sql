@@ -0,0 +1,35 @@
+-- name: CreateHold :one
+INSERT INTO holds (
+    from_account_id,
+    to_account_id,
+    amount,
+    fee,
+    expires_at
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: GetHold :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1;
+
+-- name: GetHoldForUpdate :one
+SELECT * FROM holds
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: ListExpiredHolds :many
+SELECT * FROM holds
+WHERE status = 'authorized'
+  AND expires_at <= sqlc.arg(now)
+ORDER BY expires_at
+LIMIT sqlc.arg(max_holds);
+
+-- name: UpdateHoldStatus :one
+UPDATE holds
+SET
+    status = sqlc.arg(status),
+    transfer_id = COALESCE(sqlc.narg(transfer_id), transfer_id)
+WHERE
+    id = sqlc.arg(id)
+RETURNING *;
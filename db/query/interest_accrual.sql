@@ -0,0 +1,51 @@
+-- name: CreateInterestAccrual :one
+INSERT INTO interest_accruals (
+    account_id,
+    accrual_date,
+    balance,
+    apr_bps,
+    amount
+) VALUES (
+    $1, $2, $3, $4, $5
+) RETURNING *;
+
+-- name: ListInterestAccruals :many
+SELECT * FROM interest_accruals
+WHERE account_id = $1
+ORDER BY accrual_date DESC
+LIMIT $2
+OFFSET $3;
+
+-- name: ListAccountsToAccrue :many
+SELECT * FROM accounts a
+WHERE a.id > sqlc.arg(after_id)
+  AND a.type = 'savings'
+  AND a.status = 'active'
+  AND a.balance > 0
+  AND NOT EXISTS (
+    SELECT 1 FROM interest_accruals i
+    WHERE i.account_id = a.id AND i.accrual_date = sqlc.arg(accrual_date)
+  )
+ORDER BY a.id
+LIMIT sqlc.arg(max_accounts);
+
+-- name: ListAccountsWithUnpostedInterest :many
+SELECT i.account_id FROM interest_accruals i
+JOIN accounts a ON a.id = i.account_id
+WHERE i.account_id > sqlc.arg(after_id) AND i.transfer_id IS NULL AND i.accrual_date < sqlc.arg(before)
+  AND a.status = 'active'
+GROUP BY i.account_id
+HAVING SUM(i.amount) > 0
+ORDER BY i.account_id
+LIMIT sqlc.arg(max_accounts);
+
+-- name: ListUnpostedInterestAccrualsForUpdate :many
+SELECT * FROM interest_accruals
+WHERE account_id = sqlc.arg(account_id) AND transfer_id IS NULL AND accrual_date < sqlc.arg(before)
+ORDER BY accrual_date
+FOR UPDATE;
+
+-- name: MarkInterestAccrualsPosted :exec
+UPDATE interest_accruals
+SET transfer_id = sqlc.narg(transfer_id)
+WHERE account_id = sqlc.arg(account_id) AND transfer_id IS NULL AND accrual_date < sqlc.arg(before);
\ No newline at end of file
@@ -0,0 +1,33 @@
+-- name: CreateOutboxMessage :one
+INSERT INTO outbox (
+    task_type,
+    payload,
+    queue,
+    max_retry,
+    process_at,
+    trace_context,
+    request_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+) RETURNING *;
+
+-- name: ListUnsentOutboxMessagesForUpdate :many
+SELECT * FROM outbox
+WHERE sent_at IS NULL
+ORDER BY id
+LIMIT $1
+FOR UPDATE SKIP LOCKED;
+
+-- name: MarkOutboxMessageSent :one
+UPDATE outbox
+SET sent_at = now()
+WHERE id = $1
+RETURNING *;
+
+-- name: RecordOutboxMessageFailure :one
+UPDATE outbox
+SET
+    attempts = attempts + 1,
+    last_error = sqlc.arg(last_error)
+WHERE id = sqlc.arg(id)
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,30 @@
+-- name: CreatePayee :one
+INSERT INTO payees (
+    owner,
+    nickname,
+    account_id
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetPayee :one
+SELECT * FROM payees
+WHERE id = $1 LIMIT 1;
+
+-- name: ListPayees :many
+SELECT p.id, p.owner, p.nickname, p.account_id, p.created_at, a.number AS account_number, a.currency
+FROM payees p
+JOIN accounts a ON a.id = p.account_id
+WHERE p.owner = $1
+ORDER BY p.nickname
+LIMIT $2
+OFFSET $3;
+
+-- name: UpdatePayee :one
+UPDATE payees
+SET nickname = sqlc.arg(nickname)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: DeletePayee :exec
+DELETE FROM payees WHERE id = $1;
\ No newline at end of file
@@ -0,0 +1,46 @@
+-- name: CreatePayment :one
+INSERT INTO payments (
+    account_id,
+    kind,
+    amount,
+    rail,
+    external_account,
+    transfer_id
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetPayment :one
+SELECT * FROM payments
+WHERE id = $1 LIMIT 1;
+
+-- name: GetPaymentForUpdate :one
+SELECT * FROM payments
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: ListPayments :many
+SELECT * FROM payments
+WHERE account_id = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListStalePendingPayments :many
+SELECT * FROM payments
+WHERE status = 'pending'
+  AND created_at <= sqlc.arg(created_before)
+ORDER BY created_at
+LIMIT sqlc.arg(max_payments);
+
+-- name: UpdatePayment :one
+UPDATE payments
+SET
+    status = sqlc.arg(status),
+    external_ref = COALESCE(sqlc.narg(external_ref), external_ref),
+    transfer_id = COALESCE(sqlc.narg(transfer_id), transfer_id),
+    refund_transfer_id = COALESCE(sqlc.narg(refund_transfer_id), refund_transfer_id),
+    last_error = sqlc.arg(last_error),
+    updated_at = now()
+WHERE id = sqlc.arg(id)
+RETURNING *;
\ No newline at end of file
@@ -0,0 +1,49 @@
+-- name: CountAccounts :one
+SELECT COUNT(*) FROM accounts;
+
+-- name: CountTransfers :one
+SELECT COUNT(*) FROM transfers;
+
+-- name: ListAccountBalanceMismatches :many
+SELECT
+    a.id AS account_id,
+    a.balance,
+    COALESCE(SUM(e.amount), 0)::bigint AS entries_sum
+FROM accounts a
+LEFT JOIN entries e ON e.account_id = a.id
+GROUP BY a.id
+HAVING a.balance <> COALESCE(SUM(e.amount), 0)
+ORDER BY a.id;
+
+-- name: ListTransferEntryMismatches :many
+SELECT
+    t.id AS transfer_id,
+    t.amount,
+    COUNT(e.id) AS entry_count,
+    COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.from_account_id), 0)::bigint AS from_entries_sum,
+    COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.to_account_id), 0)::bigint AS to_entries_sum
+FROM transfers t
+LEFT JOIN entries e ON e.transfer_id = t.id
+GROUP BY t.id
+HAVING COUNT(e.id) <> 2
+    OR COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.from_account_id), 0) <> -t.amount
+    OR COALESCE(SUM(e.amount) FILTER (WHERE e.account_id = t.to_account_id), 0) <> t.amount
+ORDER BY t.id;
+
+-- name: CreateReconciliationRun :one
+INSERT INTO reconciliation_runs (
+    accounts_checked,
+    transfers_checked,
+    account_discrepancies,
+    transfer_discrepancies,
+    details,
+    started_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: ListReconciliationRuns :many
+SELECT * FROM reconciliation_runs
+ORDER BY id DESC
+LIMIT $1
+OFFSET $2;
@@ -0,0 +1,69 @@
+-- name: CreateScheduledTransfer :one
+INSERT INTO scheduled_transfers (
+    owner,
+    from_account_id,
+    to_account_id,
+    amount,
+    recurrence,
+    start_at,
+    end_at,
+    next_run_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING *;
+
+-- name: GetScheduledTransfer :one
+SELECT * FROM scheduled_transfers
+WHERE id = $1 LIMIT 1;
+
+-- name: GetDueScheduledTransferForUpdate :one
+SELECT * FROM scheduled_transfers
+WHERE id = sqlc.arg(id)
+  AND status = 'active'
+  AND next_run_at <= sqlc.arg(now)
+LIMIT 1
+FOR UPDATE SKIP LOCKED;
+
+-- name: ListScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE owner = $1
+ORDER BY id
+LIMIT $2
+OFFSET $3;
+
+-- name: ListDueScheduledTransfers :many
+SELECT * FROM scheduled_transfers
+WHERE status = 'active'
+  AND next_run_at <= sqlc.arg(now)
+ORDER BY next_run_at
+LIMIT sqlc.arg(max_schedules);
+
+-- name: UpdateScheduledTransfer :one
+UPDATE scheduled_transfers
+SET
+    amount = COALESCE(sqlc.narg(amount), amount),
+    recurrence = COALESCE(sqlc.narg(recurrence), recurrence),
+    end_at = COALESCE(sqlc.narg(end_at), end_at),
+    next_run_at = COALESCE(sqlc.narg(next_run_at), next_run_at),
+    status = COALESCE(sqlc.narg(status), status),
+    failure_count = COALESCE(sqlc.narg(failure_count), failure_count)
+WHERE
+    id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CreateScheduledTransferExecution :one
+INSERT INTO scheduled_transfer_executions (
+    scheduled_transfer_id,
+    transfer_id,
+    status,
+    error
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: ListScheduledTransferExecutions :many
+SELECT * FROM scheduled_transfer_executions
+WHERE scheduled_transfer_id = $1
+ORDER BY id DESC
+LIMIT $2
+OFFSET $3;
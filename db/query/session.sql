@@ -13,4 +13,21 @@ INSERT INTO sessions (
 
 -- name: GetSession :one
 SELECT * FROM sessions
-WHERE id = $1 LIMIT 1;
\ No newline at end of file
+WHERE id = $1 LIMIT 1;
+
+-- name: ListUserSessionsBefore :many
+SELECT * FROM sessions
+WHERE username = sqlc.arg(username) AND created_at < sqlc.arg(before)
+ORDER BY created_at DESC
+LIMIT sqlc.arg(max_sessions);
+
+-- name: GetSessionForUpdate :one
+SELECT * FROM sessions
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: BlockSession :one
+UPDATE sessions
+SET is_blocked = true
+WHERE id = $1
+RETURNING *;
\ No newline at end of file
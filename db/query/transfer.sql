@@ -2,15 +2,36 @@
 INSERT INTO transfers (
     from_account_id,
     to_account_id,
-    amount
+    amount,
+    reversal_of_id,
+    fee
 ) VALUES (
-    $1, $2, $3
+    $1, $2, $3, $4, $5
 ) RETURNING *;
 
 -- name: GetTransfer :one
 SELECT * FROM transfers
 WHERE id = $1 LIMIT 1;
 
+-- name: GetTransferForUpdate :one
+SELECT * FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE;
+
+-- name: GetReversedAmount :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS reversed_amount
+FROM transfers
+WHERE reversal_of_id = sqlc.arg(transfer_id)::bigint;
+
+-- name: IsSettlementTransfer :one
+SELECT (COUNT(*) > 0)::boolean AS settlement FROM (
+  SELECT holds.id FROM holds WHERE holds.transfer_id = sqlc.arg(transfer_id)::bigint
+  UNION ALL
+  SELECT payments.id FROM payments WHERE payments.transfer_id = sqlc.arg(transfer_id)::bigint OR payments.refund_transfer_id = sqlc.arg(transfer_id)::bigint
+  UNION ALL
+  SELECT transfer_reviews.id FROM transfer_reviews WHERE transfer_reviews.transfer_id = sqlc.arg(transfer_id)::bigint
+) AS settlements;
+
 -- name: ListTransfers :many
 SELECT * FROM transfers
 WHERE
@@ -18,4 +39,18 @@ WHERE
     to_account_id = $2
 ORDER BY id
 LIMIT $3
-OFFSET $4;
\ No newline at end of file
+OFFSET $4;
+
+-- name: UpdateTransferFeeJournal :one
+UPDATE transfers
+SET fee_journal_id = sqlc.narg(fee_journal_id)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: CountTransfersBetween :one
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = $1 AND to_account_id = $2 AND reversal_of_id IS NULL;
+
+-- name: CountTransfersSince :one
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = sqlc.arg(from_account_id) AND created_at >= sqlc.arg(since) AND reversal_of_id IS NULL;
\ No newline at end of file
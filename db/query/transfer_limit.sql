@@ -0,0 +1,84 @@
+-- name: UpsertUserTransferLimit :one
+INSERT INTO transfer_limits (
+    username,
+    max_single_amount,
+    daily_amount,
+    monthly_amount,
+    hourly_count
+) VALUES (
+    $1, $2, $3, $4, $5
+) ON CONFLICT (username) DO UPDATE
+SET max_single_amount = EXCLUDED.max_single_amount,
+    daily_amount = EXCLUDED.daily_amount,
+    monthly_amount = EXCLUDED.monthly_amount,
+    hourly_count = EXCLUDED.hourly_count,
+    updated_at = now()
+RETURNING *;
+
+-- name: UpsertAccountTransferLimit :one
+INSERT INTO transfer_limits (
+    account_id,
+    max_single_amount,
+    daily_amount,
+    monthly_amount,
+    hourly_count
+) VALUES (
+    $1, $2, $3, $4, $5
+) ON CONFLICT (account_id) DO UPDATE
+SET max_single_amount = EXCLUDED.max_single_amount,
+    daily_amount = EXCLUDED.daily_amount,
+    monthly_amount = EXCLUDED.monthly_amount,
+    hourly_count = EXCLUDED.hourly_count,
+    updated_at = now()
+RETURNING *;
+
+-- name: GetUserTransferLimit :one
+SELECT * FROM transfer_limits
+WHERE username = $1 LIMIT 1;
+
+-- name: GetAccountTransferLimit :one
+SELECT * FROM transfer_limits
+WHERE account_id = $1 LIMIT 1;
+
+-- name: GetAccountTransferUsage :one
+SELECT
+    COALESCE(SUM(amount) FILTER (WHERE created_at >= sqlc.arg(day_start)::timestamptz), 0)::bigint AS daily_amount,
+    COALESCE(SUM(amount) FILTER (WHERE created_at >= sqlc.arg(month_start)::timestamptz), 0)::bigint AS monthly_amount,
+    COUNT(*) FILTER (WHERE created_at >= sqlc.arg(hour_start)::timestamptz) AS hourly_count
+FROM (
+    SELECT amount, created_at FROM transfers
+    WHERE from_account_id = sqlc.arg(account_id)
+      AND reversal_of_id IS NULL
+    UNION ALL
+    SELECT amount, created_at FROM holds
+    WHERE from_account_id = sqlc.arg(account_id)
+      AND status = 'authorized'
+    UNION ALL
+    SELECT amount, created_at FROM transfer_reviews
+    WHERE from_account_id = sqlc.arg(account_id)
+      AND status = 'pending'
+) AS outgoing
+WHERE created_at >= LEAST(sqlc.arg(month_start)::timestamptz, sqlc.arg(hour_start)::timestamptz);
+
+-- name: GetUserTransferUsage :one
+SELECT
+    COALESCE(SUM(amount) FILTER (WHERE currency = sqlc.arg(currency) AND created_at >= sqlc.arg(day_start)::timestamptz), 0)::bigint AS daily_amount,
+    COALESCE(SUM(amount) FILTER (WHERE currency = sqlc.arg(currency) AND created_at >= sqlc.arg(month_start)::timestamptz), 0)::bigint AS monthly_amount,
+    COUNT(*) FILTER (WHERE created_at >= sqlc.arg(hour_start)::timestamptz) AS hourly_count
+FROM (
+    SELECT t.amount, a.currency, t.created_at FROM transfers t
+    JOIN accounts a ON a.id = t.from_account_id
+    WHERE a.owner = sqlc.arg(owner)
+      AND t.reversal_of_id IS NULL
+    UNION ALL
+    SELECT h.amount, a.currency, h.created_at FROM holds h
+    JOIN accounts a ON a.id = h.from_account_id
+    WHERE a.owner = sqlc.arg(owner)
+      AND h.status = 'authorized'
+    UNION ALL
+    SELECT r.amount, a.currency, r.created_at FROM transfer_reviews r
+    JOIN accounts a ON a.id = r.from_account_id
+    WHERE a.owner = sqlc.arg(owner)
+      AND r.status = 'pending'
+) AS outgoing
+WHERE created_at >= LEAST(sqlc.arg(month_start)::timestamptz, sqlc.arg(hour_start)::timestamptz);
\ No newline at end of file
@@ -0,0 +1,83 @@
+-- name: CreateDomainEvent :one
+INSERT INTO domain_events (
+    event_type,
+    username,
+    data
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: GetDomainEvent :one
+SELECT * FROM domain_events
+WHERE id = $1 LIMIT 1;
+
+-- name: CreateWebhookSubscription :one
+INSERT INTO webhook_subscriptions (
+    owner,
+    url,
+    secret,
+    event_types
+) VALUES (
+    $1, $2, $3, $4
+) RETURNING *;
+
+-- name: GetWebhookSubscription :one
+SELECT * FROM webhook_subscriptions
+WHERE id = $1 LIMIT 1;
+
+-- name: ListWebhookSubscriptionsForEvent :many
+SELECT * FROM webhook_subscriptions
+WHERE
+    owner = sqlc.arg(owner) AND
+    is_active AND
+    (event_types = '' OR sqlc.arg(event_type)::varchar = ANY(string_to_array(event_types, ',')))
+ORDER BY id;
+
+-- name: DeactivateWebhookSubscription :one
+UPDATE webhook_subscriptions
+SET is_active = false
+WHERE id = $1
+RETURNING *;
+
+-- name: CreateWebhookDelivery :one
+INSERT INTO webhook_deliveries (
+    subscription_id,
+    event_id
+) VALUES (
+    $1, $2
+) RETURNING *;
+
+-- name: GetWebhookDelivery :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 LIMIT 1;
+
+-- name: GetWebhookDeliveryForUpdate :one
+SELECT * FROM webhook_deliveries
+WHERE id = $1 LIMIT 1
+FOR UPDATE;
+
+-- name: MarkWebhookDeliveryReplayed :one
+UPDATE webhook_deliveries
+SET status = 'replayed'
+WHERE id = $1
+RETURNING *;
+
+-- name: UpdateWebhookDelivery :one
+UPDATE webhook_deliveries
+SET
+    status = sqlc.arg(status),
+    attempts = attempts + 1,
+    response_status = sqlc.arg(response_status),
+    last_error = sqlc.arg(last_error),
+    delivered_at = sqlc.narg(delivered_at)
+WHERE id = sqlc.arg(id)
+RETURNING *;
+
+-- name: ListWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE
+    subscription_id IN (SELECT id FROM webhook_subscriptions WHERE owner = sqlc.arg(owner)) AND
+    (sqlc.arg(status)::varchar = '' OR status = sqlc.arg(status))
+ORDER BY id DESC
+LIMIT sqlc.arg(max_deliveries)
+OFFSET sqlc.arg(skip_deliveries);
\ No newline at end of file
@@ -0,0 +1,124 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func changeAccountStatus(t *testing.T, account Account, status string) (ChangeAccountStatusTxResult, error) {
+	return NewStore(testDB).ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+		AccountID: account.ID,
+		Status:    status,
+		Reason:    util.RandomString(10),
+		Banker:    true,
+		Audit:     AuditInfo{Actor: account.Owner},
+	})
+}
+
+func TestStore_ChangeAccountStatusTxFreeze(t *testing.T) {
+	store := NewStore(testDB)
+
+	currency := util.RandomCurrency()
+	account1 := addRandomAccountBalance(t, createRandomAccountWithCurrency(t, currency), 100)
+	account2 := addRandomAccountBalance(t, createRandomAccountWithCurrency(t, currency), 100)
+	require.Equal(t, AccountStatusActive, account1.Status)
+
+	result, err := changeAccountStatus(t, account1, AccountStatusFrozen)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusFrozen, result.Account.Status)
+	require.Equal(t, AccountStatusActive, result.Change.FromStatus)
+	require.Equal(t, AccountStatusFrozen, result.Change.ToStatus)
+	require.NotEmpty(t, result.Change.Reason)
+	require.Equal(t, account1.Owner, result.Change.ChangedBy)
+
+	_, err = changeAccountStatus(t, account1, AccountStatusFrozen)
+	require.ErrorIs(t, err, ErrInvalidAccountStatusChange)
+
+	// transfers are blocked in both directions
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountNotActive)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account2.ID,
+		ToAccountID:   account1.ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrAccountNotActive)
+
+	_, err = changeAccountStatus(t, account1, AccountStatusActive)
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	changes, err := testQueries.ListAccountStatusChanges(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Len(t, changes, 2)
+	require.Equal(t, AccountStatusFrozen, changes[0].ToStatus)
+	require.Equal(t, AccountStatusActive, changes[1].ToStatus)
+}
+
+func TestStore_ChangeAccountStatusTxClose(t *testing.T) {
+	account := addRandomAccountBalance(t, createRandomAccount(t), 1)
+
+	_, err := changeAccountStatus(t, account, AccountStatusClosed)
+	require.ErrorIs(t, err, ErrAccountNotEmpty)
+
+	account, err = testQueries.UpdateAccount(context.Background(), UpdateAccountParams{
+		ID:      account.ID,
+		Balance: 0,
+	})
+	require.NoError(t, err)
+
+	result, err := changeAccountStatus(t, account, AccountStatusClosed)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusClosed, result.Account.Status)
+
+	// closed accounts are final
+	_, err = changeAccountStatus(t, account, AccountStatusActive)
+	require.ErrorIs(t, err, ErrInvalidAccountStatusChange)
+
+	// the owner can open a new account in place of the closed one
+	newAccount, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    account.Owner,
+		Currency: account.Currency,
+		Type:     account.Type,
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, account.ID, newAccount.ID)
+}
+
+func TestStore_ChangeAccountStatusTxFrozenByBanker(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+
+	_, err := changeAccountStatus(t, account, AccountStatusFrozen)
+	require.NoError(t, err)
+
+	// the owner cannot move the account out of the freeze, not even by closing it
+	for _, status := range []string{AccountStatusActive, AccountStatusClosed} {
+		_, err = store.ChangeAccountStatusTx(context.Background(), ChangeAccountStatusTxParams{
+			AccountID: account.ID,
+			Status:    status,
+			Reason:    util.RandomString(10),
+			Audit:     AuditInfo{Actor: account.Owner},
+		})
+		require.ErrorIs(t, err, ErrAccountFrozenByBanker)
+	}
+
+	result, err := changeAccountStatus(t, account, AccountStatusClosed)
+	require.NoError(t, err)
+	require.Equal(t, AccountStatusClosed, result.Account.Status)
+}
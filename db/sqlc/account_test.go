@@ -4,17 +4,23 @@ import (
 	"context"
 	"database/sql"
 	"github.com/Dejan91/simple_bank/util"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/require"
 	"testing"
 	"time"
 )
 
 func createRandomAccount(t *testing.T) Account {
+	return createRandomAccountWithCurrency(t, util.RandomCurrency())
+}
+
+func createRandomAccountWithCurrency(t *testing.T, currency string) Account {
 	user := createRandomUser(t)
 	arg := CreateAccountParams{
 		Owner:    user.Username,
 		Balance:  util.RandomMoney(),
-		Currency: util.RandomCurrency(),
+		Currency: currency,
+		Type:     AccountTypeChecking,
 	}
 
 	account, err := testQueries.CreateAccount(context.Background(), arg)
@@ -24,17 +30,53 @@ func createRandomAccount(t *testing.T) Account {
 	require.Equal(t, arg.Owner, account.Owner)
 	require.Equal(t, arg.Balance, account.Balance)
 	require.Equal(t, arg.Currency, account.Currency)
+	require.Equal(t, arg.Type, account.Type)
 
 	require.NotZero(t, account.ID)
 	require.NotZero(t, account.CreatedAt)
+	require.True(t, util.ValidAccountNumber(account.Number))
 
 	return account
 }
 
+// addRandomAccountBalance credits an account with at least minAmount
+func addRandomAccountBalance(t *testing.T, account Account, minAmount int64) Account {
+	updatedAccount, err := testQueries.AddAccountBalance(context.Background(), AddAccountBalanceParams{
+		ID:     account.ID,
+		Amount: minAmount + util.RandomMoney(),
+	})
+	require.NoError(t, err)
+
+	return updatedAccount
+}
+
 func TestQueries_CreateAccount(t *testing.T) {
 	createRandomAccount(t)
 }
 
+func TestQueries_CreateAccountTypes(t *testing.T) {
+	checking := createRandomAccount(t)
+
+	// an owner can have a checking and a savings account in the same currency
+	savings, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    checking.Owner,
+		Currency: checking.Currency,
+		Type:     AccountTypeSavings,
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, checking.ID, savings.ID)
+	require.Equal(t, AccountTypeSavings, savings.Type)
+
+	// but only one of each type
+	_, err = testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    checking.Owner,
+		Currency: checking.Currency,
+		Type:     AccountTypeChecking,
+	})
+	require.Error(t, err)
+	require.Equal(t, "unique_violation", err.(*pq.Error).Code.Name())
+}
+
 func TestQueries_GetAccount(t *testing.T) {
 	account1 := createRandomAccount(t)
 	account2, err := testQueries.GetAccount(context.Background(), account1.ID)
@@ -100,3 +142,12 @@ func TestQueries_ListAccounts(t *testing.T) {
 		require.Equal(t, account.Owner, lastAccount.Owner)
 	}
 }
+
+func TestQueries_GetAccountByNumber(t *testing.T) {
+	account1 := createRandomAccount(t)
+
+	account2, err := testQueries.GetAccountByNumber(context.Background(), account1.Number)
+	require.NoError(t, err)
+	require.Equal(t, account1.ID, account2.ID)
+	require.Equal(t, account1.Number, account2.Number)
+}
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"github.com/Dejan91/simple_bank/redact"
+	"strconv"
+)
+
+const (
+	AuditActionUserUpdated      = "user.updated"
+	AuditActionUserLoggedIn     = "user.logged_in"
+	AuditActionSessionBlocked   = "session.blocked"
+	AuditActionAccountCreated   = "account.created"
+	AuditActionAccountFrozen    = "account.frozen"
+	AuditActionAccountUnfrozen  = "account.unfrozen"
+	AuditActionAccountClosed    = "account.closed"
+	AuditActionTransferCreated  = "transfer.created"
+	AuditActionTransferHeld     = "transfer.held_for_review"
+	AuditActionTransferApproved = "transfer.approved"
+	AuditActionTransferRejected = "transfer.rejected"
+	AuditActionTransferReversed = "transfer.reversed"
+	AuditActionHoldAuthorized   = "hold.authorized"
+	AuditActionHoldCaptured     = "hold.captured"
+	AuditActionHoldVoided       = "hold.voided"
+	AuditActionHoldExpired      = "hold.expired"
+	AuditActionTransferLimitSet = "transfer_limit.set"
+	AuditActionJournalPosted    = "journal.posted"
+	AuditActionInterestPosted   = "interest.posted"
+
+	AuditActionScheduledTransferExecuted = "scheduled_transfer.executed"
+
+	AuditActionDepositCompleted    = "deposit.completed"
+	AuditActionDepositFailed       = "deposit.failed"
+	AuditActionWithdrawalRequested = "withdrawal.requested"
+	AuditActionWithdrawalCompleted = "withdrawal.completed"
+	AuditActionWithdrawalFailed    = "withdrawal.failed"
+)
+
+const (
+	AuditTargetUser           = "user"
+	AuditTargetSession        = "session"
+	AuditTargetAccount        = "account"
+	AuditTargetTransfer       = "transfer"
+	AuditTargetTransferReview = "transfer_review"
+	AuditTargetHold           = "hold"
+	AuditTargetPayment        = "payment"
+	AuditTargetTransferLimit  = "transfer_limit"
+	AuditTargetJournal        = "journal"
+)
+
+// AuditActorSystem is the actor of the actions done by the background tasks
+const AuditActorSystem = "system"
+
+// auditRedactor masks the secrets of the audited rows, their changes are still recorded
+var auditRedactor = redact.New([]string{"hashed_password", "refresh_token"})
+
+// AuditInfo tells who does an audited action and from which client
+type AuditInfo struct {
+	// Actor is the username of the authenticated user
+	Actor     string `json:"actor"`
+	ClientIP  string `json:"client_ip"`
+	UserAgent string `json:"user_agent"`
+}
+
+// auditAccountStatusActions maps the new status of an account to its audit action
+var auditAccountStatusActions = map[string]string{
+	AccountStatusActive: AuditActionAccountUnfrozen,
+	AccountStatusFrozen: AuditActionAccountFrozen,
+	AccountStatusClosed: AuditActionAccountClosed,
+}
+
+// recordAudit records an action in the audit log inside the transaction doing it,
+// before and after are the target before and after the action and only their changed fields are kept.
+// A nil before records a creation with all the fields of after
+func recordAudit(ctx context.Context, q *Queries, info AuditInfo, action string, targetType string, targetID string, before interface{}, after interface{}) error {
+	beforeDiff, afterDiff, err := auditDiff(before, after)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.CreateAuditEvent(ctx, CreateAuditEventParams{
+		Actor:      info.Actor,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+		Before:     beforeDiff,
+		After:      afterDiff,
+		ClientIp:   info.ClientIP,
+		UserAgent:  info.UserAgent,
+	})
+	return err
+}
+
+// auditDiff returns the fields of before and after whose values differ, with the secrets masked
+func auditDiff(before interface{}, after interface{}) (json.RawMessage, json.RawMessage, error) {
+	beforeFields, err := auditFields(before)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	afterFields, err := auditFields(after)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for field, value := range afterFields {
+		if beforeValue, ok := beforeFields[field]; ok && bytes.Equal(beforeValue, value) {
+			delete(beforeFields, field)
+			delete(afterFields, field)
+		}
+	}
+
+	beforeDiff, err := json.Marshal(beforeFields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	afterDiff, err := json.Marshal(afterFields)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return auditRedactor.JSON(beforeDiff), auditRedactor.JSON(afterDiff), nil
+}
+
+func auditFields(value interface{}) (map[string]json.RawMessage, error) {
+	fields := map[string]json.RawMessage{}
+	if value == nil {
+		return fields, nil
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	err = json.Unmarshal(data, &fields)
+	return fields, err
+}
+
+func auditID(id int64) string {
+	return strconv.FormatInt(id, 10)
+}
@@ -0,0 +1,171 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Dejan91/simple_bank/redact"
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func listTargetAuditEvents(t *testing.T, targetType string, targetID string) []AuditEvent {
+	events, err := testQueries.ListAuditEvents(context.Background(), ListAuditEventsParams{
+		TargetType: targetType,
+		TargetID:   targetID,
+		EndTime:    time.Now().Add(time.Minute),
+		MaxEvents:  10,
+	})
+	require.NoError(t, err)
+
+	return events
+}
+
+func TestAuditDiff(t *testing.T) {
+	before := User{
+		Username:       "alice",
+		HashedPassword: "old",
+		FullName:       "Alice",
+		Email:          "alice@example.com",
+	}
+	after := before
+	after.HashedPassword = "new"
+	after.FullName = "Alice Smith"
+
+	beforeDiff, afterDiff, err := auditDiff(before, after)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"hashed_password":"`+redact.Mask+`","full_name":"Alice"}`, string(beforeDiff))
+	require.JSONEq(t, `{"hashed_password":"`+redact.Mask+`","full_name":"Alice Smith"}`, string(afterDiff))
+
+	beforeDiff, afterDiff, err = auditDiff(nil, Transfer{ID: 1, Amount: 10})
+	require.NoError(t, err)
+	require.JSONEq(t, `{}`, string(beforeDiff))
+
+	var fields map[string]interface{}
+	require.NoError(t, json.Unmarshal(afterDiff, &fields))
+	require.Equal(t, float64(10), fields["amount"])
+}
+
+func TestStore_UpdateUserTxAudit(t *testing.T) {
+	user := createRandomUser(t)
+	audit := AuditInfo{
+		Actor:     user.Username,
+		ClientIP:  "127.0.0.1",
+		UserAgent: "test",
+	}
+
+	newFullName := util.RandomOwner()
+	result, err := NewStore(testDB).UpdateUserTx(context.Background(), UpdateUserTxParams{
+		UpdateUserParams: UpdateUserParams{
+			Username: user.Username,
+			FullName: sql.NullString{String: newFullName, Valid: true},
+		},
+		Audit: audit,
+	})
+	require.NoError(t, err)
+	require.Equal(t, newFullName, result.User.FullName)
+
+	events := listTargetAuditEvents(t, AuditTargetUser, user.Username)
+	require.Len(t, events, 1)
+
+	event := events[0]
+	require.Equal(t, audit.Actor, event.Actor)
+	require.Equal(t, AuditActionUserUpdated, event.Action)
+	require.Equal(t, audit.ClientIP, event.ClientIp)
+	require.Equal(t, audit.UserAgent, event.UserAgent)
+	require.JSONEq(t, `{"full_name":"`+user.FullName+`"}`, string(event.Before))
+	require.JSONEq(t, `{"full_name":"`+newFullName+`"}`, string(event.After))
+}
+
+func TestStore_ChangeAccountStatusTxAudit(t *testing.T) {
+	account := createRandomAccount(t)
+
+	_, err := changeAccountStatus(t, account, AccountStatusFrozen)
+	require.NoError(t, err)
+
+	events := listTargetAuditEvents(t, AuditTargetAccount, auditID(account.ID))
+	require.Len(t, events, 1)
+	require.Equal(t, account.Owner, events[0].Actor)
+	require.Equal(t, AuditActionAccountFrozen, events[0].Action)
+	require.Contains(t, string(events[0].Before), AccountStatusActive)
+	require.Contains(t, string(events[0].After), AccountStatusFrozen)
+}
+
+func TestQueries_ListAuditEventsFilters(t *testing.T) {
+	actor := util.RandomOwner()
+	for _, action := range []string{AuditActionTransferCreated, AuditActionTransferReversed} {
+		_, err := testQueries.CreateAuditEvent(context.Background(), CreateAuditEventParams{
+			Actor:      actor,
+			Action:     action,
+			TargetType: AuditTargetTransfer,
+			TargetID:   util.RandomString(6),
+			Before:     json.RawMessage(`{}`),
+			After:      json.RawMessage(`{}`),
+		})
+		require.NoError(t, err)
+	}
+
+	arg := ListAuditEventsParams{
+		Actor:     actor,
+		EndTime:   time.Now().Add(time.Minute),
+		MaxEvents: 10,
+	}
+
+	events, err := testQueries.ListAuditEvents(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, events, 2)
+	// newest first
+	require.Equal(t, AuditActionTransferReversed, events[0].Action)
+
+	arg.Action = AuditActionTransferCreated
+	events, err = testQueries.ListAuditEvents(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+
+	arg.StartTime = time.Now().Add(time.Minute)
+	arg.EndTime = time.Now().Add(2 * time.Minute)
+	events, err = testQueries.ListAuditEvents(context.Background(), arg)
+	require.NoError(t, err)
+	require.Empty(t, events)
+}
+
+func TestStore_BlockSessionTxAudit(t *testing.T) {
+	store := NewStore(testDB)
+	user := createRandomUser(t)
+
+	login, err := store.CreateSessionTx(context.Background(), CreateSessionTxParams{
+		CreateSessionParams: CreateSessionParams{
+			ID:           uuid.New(),
+			Username:     user.Username,
+			RefreshToken: util.RandomString(32),
+			ExpiresAt:    time.Now().Add(time.Hour),
+		},
+		Audit: AuditInfo{Actor: user.Username},
+	})
+	require.NoError(t, err)
+	require.False(t, login.Session.IsBlocked)
+
+	result, err := store.BlockSessionTx(context.Background(), BlockSessionTxParams{
+		ID:    login.Session.ID,
+		Audit: AuditInfo{Actor: user.Username},
+	})
+	require.NoError(t, err)
+	require.True(t, result.Session.IsBlocked)
+
+	_, err = store.BlockSessionTx(context.Background(), BlockSessionTxParams{
+		ID:    login.Session.ID,
+		Audit: AuditInfo{Actor: user.Username},
+	})
+	require.ErrorIs(t, err, ErrSessionBlocked)
+
+	events := listTargetAuditEvents(t, AuditTargetSession, login.Session.ID.String())
+	require.Len(t, events, 2)
+	require.Equal(t, AuditActionSessionBlocked, events[0].Action)
+	require.JSONEq(t, `{"is_blocked":false}`, string(events[0].Before))
+	require.JSONEq(t, `{"is_blocked":true}`, string(events[0].After))
+	require.Equal(t, AuditActionUserLoggedIn, events[1].Action)
+}
@@ -0,0 +1,114 @@
+package db
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+)
+
+// verifyEntryChainBatchSize is the number of entries loaded from the db at once
+const verifyEntryChainBatchSize = 1000
+
+// ComputeEntryHash returns the hash of an entry chained to the hash of the previous entry of its account,
+// it must stay in sync with the entry_hash functions of the database. The entries of version 1 were hashed
+// before their journal was covered, the later ones are hashed with it
+func ComputeEntryHash(prevHash []byte, entry Entry) []byte {
+	transferID := ""
+	if entry.TransferID.Valid {
+		transferID = strconv.FormatInt(entry.TransferID.Int64, 10)
+	}
+
+	var content string
+	if entry.HashVersion == 1 {
+		content = fmt.Sprintf("%d|%d|%d|%s|%d",
+			entry.ID,
+			entry.AccountID,
+			entry.Amount,
+			transferID,
+			entry.CreatedAt.UnixMicro(),
+		)
+	} else {
+		journalID := ""
+		if entry.JournalID.Valid {
+			journalID = strconv.FormatInt(entry.JournalID.Int64, 10)
+		}
+
+		content = fmt.Sprintf("%d|%d|%d|%d|%s|%s|%d",
+			entry.HashVersion,
+			entry.ID,
+			entry.AccountID,
+			entry.Amount,
+			transferID,
+			journalID,
+			entry.CreatedAt.UnixMicro(),
+		)
+	}
+
+	hash := sha256.New()
+	hash.Write(prevHash)
+	hash.Write([]byte(content))
+	return hash.Sum(nil)
+}
+
+// EntryChainViolation is an entry whose hash chain has been broken
+type EntryChainViolation struct {
+	EntryID   int64  `json:"entry_id"`
+	AccountID int64  `json:"account_id"`
+	Reason    string `json:"reason"`
+}
+
+type VerifyEntryChainTxResult struct {
+	EntriesChecked int64                 `json:"entries_checked"`
+	Violations     []EntryChainViolation `json:"violations"`
+}
+
+// VerifyEntryChainTx walks the entries of every account in order and reports the entries
+// which were modified, or which follow a removed entry, since they were created
+func (store *SQLStore) VerifyEntryChainTx(ctx context.Context) (VerifyEntryChainTxResult, error) {
+	var result VerifyEntryChainTxResult
+
+	err := store.execSnapshotTx(ctx, func(q *Queries) error {
+		lastHashes := make(map[int64][]byte)
+		var lastEntryID int64
+
+		for {
+			entries, err := q.ListAllEntriesAfter(ctx, ListAllEntriesAfterParams{
+				AfterID:    lastEntryID,
+				MaxEntries: verifyEntryChainBatchSize,
+			})
+			if err != nil {
+				return err
+			}
+
+			for _, entry := range entries {
+				if !bytes.Equal(entry.PrevHash, lastHashes[entry.AccountID]) {
+					result.Violations = append(result.Violations, EntryChainViolation{
+						EntryID:   entry.ID,
+						AccountID: entry.AccountID,
+						Reason:    "previous hash doesn't match the previous entry of the account",
+					})
+				}
+
+				if !bytes.Equal(entry.Hash, ComputeEntryHash(entry.PrevHash, entry)) {
+					result.Violations = append(result.Violations, EntryChainViolation{
+						EntryID:   entry.ID,
+						AccountID: entry.AccountID,
+						Reason:    "hash doesn't match the entry contents",
+					})
+				}
+
+				lastHashes[entry.AccountID] = entry.Hash
+				lastEntryID = entry.ID
+				result.EntriesChecked++
+			}
+
+			if len(entries) < verifyEntryChainBatchSize {
+				return nil
+			}
+		}
+	})
+
+	return result, err
+}
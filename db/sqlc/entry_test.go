@@ -2,6 +2,7 @@ package db
 
 import (
 	"context"
+	"database/sql"
 	"testing"
 	"time"
 
@@ -67,3 +68,100 @@ func TestListEntries(t *testing.T) {
 		require.Equal(t, arg.AccountID, entry.AccountID)
 	}
 }
+
+func TestListEntriesAfter(t *testing.T) {
+	account := createRandomAccount(t)
+
+	var entries []Entry
+	for i := 0; i < 10; i++ {
+		entries = append(entries, createRandomEntry(t, account))
+	}
+
+	arg := ListEntriesAfterParams{
+		AccountID:  account.ID,
+		AfterID:    entries[4].ID,
+		MaxEntries: 3,
+	}
+
+	gotEntries, err := testQueries.ListEntriesAfter(context.Background(), arg)
+	require.NoError(t, err)
+	require.Len(t, gotEntries, 3)
+
+	for i, entry := range gotEntries {
+		require.Equal(t, entries[5+i].ID, entry.ID)
+		require.Equal(t, arg.AccountID, entry.AccountID)
+	}
+}
+
+func TestGetAccountLastEntryID(t *testing.T) {
+	account := createRandomAccount(t)
+
+	lastEntryID, err := testQueries.GetAccountLastEntryID(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Zero(t, lastEntryID)
+
+	createRandomEntry(t, account)
+	entry := createRandomEntry(t, account)
+	createRandomEntry(t, createRandomAccount(t))
+
+	lastEntryID, err = testQueries.GetAccountLastEntryID(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, entry.ID, lastEntryID)
+}
+
+func TestEntryHashChain(t *testing.T) {
+	account := createRandomAccount(t)
+	entry1 := createRandomEntry(t, account)
+	entry2 := createRandomEntry(t, account)
+
+	require.Empty(t, entry1.PrevHash)
+	require.Equal(t, ComputeEntryHash(entry1.PrevHash, entry1), entry1.Hash)
+
+	require.Equal(t, entry1.Hash, entry2.PrevHash)
+	require.Equal(t, ComputeEntryHash(entry2.PrevHash, entry2), entry2.Hash)
+}
+
+func TestComputeEntryHashJournal(t *testing.T) {
+	entry := createRandomEntry(t, createRandomAccount(t))
+	require.Equal(t, int16(2), entry.HashVersion)
+
+	// the new entries cover their journal
+	moved := entry
+	moved.JournalID = sql.NullInt64{Int64: util.RandomInt(1, 1000), Valid: true}
+	require.NotEqual(t, entry.Hash, ComputeEntryHash(entry.PrevHash, moved))
+
+	// the entries hashed before do not
+	entry.HashVersion = 1
+	moved.HashVersion = 1
+	require.Equal(t, ComputeEntryHash(entry.PrevHash, entry), ComputeEntryHash(entry.PrevHash, moved))
+}
+
+func TestEntriesAreImmutable(t *testing.T) {
+	account := createRandomAccount(t)
+	entry := createRandomEntry(t, account)
+
+	_, err := testDB.ExecContext(context.Background(), "UPDATE entries SET amount = amount + 1 WHERE id = $1", entry.ID)
+	require.Error(t, err)
+
+	_, err = testDB.ExecContext(context.Background(), "DELETE FROM entries WHERE id = $1", entry.ID)
+	require.Error(t, err)
+
+	entry2, err := testQueries.GetEntry(context.Background(), entry.ID)
+	require.NoError(t, err)
+	require.Equal(t, entry.Amount, entry2.Amount)
+	require.Equal(t, entry.Hash, entry2.Hash)
+}
+
+func TestStore_VerifyEntryChainTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	for i := 0; i < 3; i++ {
+		createRandomEntry(t, account)
+	}
+
+	result, err := store.VerifyEntryChainTx(context.Background())
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, result.EntriesChecked, int64(3))
+	require.Empty(t, result.Violations)
+}
@@ -0,0 +1,179 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	EventUserCreated         = "user.created"
+	EventAccountCreated      = "account.created"
+	EventTransferCompleted   = "transfer.completed"
+	EventTransferReversed    = "transfer.reversed"
+	EventDepositCompleted    = "deposit.completed"
+	EventWithdrawalCompleted = "withdrawal.completed"
+	EventWithdrawalFailed    = "withdrawal.failed"
+	EventInterestPosted      = "interest.posted"
+)
+
+// EventTypes are the domain events webhooks can subscribe to
+var EventTypes = []string{
+	EventUserCreated,
+	EventAccountCreated,
+	EventTransferCompleted,
+	EventTransferReversed,
+	EventDepositCompleted,
+	EventWithdrawalCompleted,
+	EventWithdrawalFailed,
+	EventInterestPosted,
+}
+
+const (
+	WebhookDeliveryStatusPending   = "pending"
+	WebhookDeliveryStatusSucceeded = "succeeded"
+	WebhookDeliveryStatusFailed    = "failed"
+	// WebhookDeliveryStatusReplayed is a failed delivery whose event was delivered again with a new delivery
+	WebhookDeliveryStatusReplayed = "replayed"
+)
+
+// TaskDeliverWebhook is the type of the outbox task delivering an event to a webhook subscription
+const TaskDeliverWebhook = "task:deliver_webhook"
+
+// webhookMaxRetry with the exponential backoff of the deliveries keeps retrying for about half a day
+const webhookMaxRetry = 12
+
+type PayloadDeliverWebhook struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+type UserEventData struct {
+	Username string `json:"username"`
+	FullName string `json:"full_name"`
+	Email    string `json:"email"`
+}
+
+type AccountEventData struct {
+	AccountID int64  `json:"account_id"`
+	Number    string `json:"number"`
+	Owner     string `json:"owner"`
+	Currency  string `json:"currency"`
+	Type      string `json:"type"`
+}
+
+type TransferEventData struct {
+	TransferID    int64  `json:"transfer_id"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	Fee           int64  `json:"fee"`
+	Currency      string `json:"currency"`
+	// ReversalOfID is the transfer refunded by a reversal
+	ReversalOfID int64 `json:"reversal_of_id,omitempty"`
+}
+
+type PaymentEventData struct {
+	PaymentID int64  `json:"payment_id"`
+	AccountID int64  `json:"account_id"`
+	Amount    int64  `json:"amount"`
+	Currency  string `json:"currency"`
+	Rail      string `json:"rail"`
+	// TransferID credits a deposit or debits a withdrawal
+	TransferID int64 `json:"transfer_id"`
+	// RefundTransferID gives the money of a failed withdrawal back
+	RefundTransferID int64 `json:"refund_transfer_id,omitempty"`
+}
+
+type InterestEventData struct {
+	TransferID int64  `json:"transfer_id"`
+	AccountID  int64  `json:"account_id"`
+	Amount     int64  `json:"amount"`
+	Currency   string `json:"currency"`
+	// Accruals is the number of daily accruals paid by the transfer
+	Accruals int `json:"accruals"`
+}
+
+// emitEvent records a domain event for a user and enqueues its delivery to the webhooks
+// the user subscribed with, both are only kept if the transaction commits
+func emitEvent(ctx context.Context, q *Queries, eventType string, username string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+
+	event, err := q.CreateDomainEvent(ctx, CreateDomainEventParams{
+		EventType: eventType,
+		Username:  username,
+		Data:      payload,
+	})
+	if err != nil {
+		return err
+	}
+
+	subscriptions, err := q.ListWebhookSubscriptionsForEvent(ctx, ListWebhookSubscriptionsForEventParams{
+		Owner:     username,
+		EventType: eventType,
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, subscription := range subscriptions {
+		_, err = enqueueWebhookDelivery(ctx, q, subscription.ID, event.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func enqueueWebhookDelivery(ctx context.Context, q *Queries, subscriptionID int64, eventID int64) (WebhookDelivery, error) {
+	delivery, err := q.CreateWebhookDelivery(ctx, CreateWebhookDeliveryParams{
+		SubscriptionID: subscriptionID,
+		EventID:        eventID,
+	})
+	if err != nil {
+		return delivery, err
+	}
+
+	err = enqueueOutboxTasks(ctx, q, OutboxTask{
+		Type:     TaskDeliverWebhook,
+		Payload:  PayloadDeliverWebhook{DeliveryID: delivery.ID},
+		MaxRetry: webhookMaxRetry,
+	})
+	return delivery, err
+}
+
+// emitTransferEvent notifies the owners of both accounts of a transfer
+func emitTransferEvent(ctx context.Context, q *Queries, eventType string, result TransferTxResult) error {
+	data := TransferEventData{
+		TransferID:    result.Transfer.ID,
+		FromAccountID: result.Transfer.FromAccountID,
+		ToAccountID:   result.Transfer.ToAccountID,
+		Amount:        result.Transfer.Amount,
+		Fee:           result.Transfer.Fee,
+		Currency:      result.FromAccount.Currency,
+		ReversalOfID:  result.Transfer.ReversalOfID.Int64,
+	}
+
+	err := emitEvent(ctx, q, eventType, result.FromAccount.Owner, data)
+	if err != nil || result.ToAccount.Owner == result.FromAccount.Owner {
+		return err
+	}
+
+	return emitEvent(ctx, q, eventType, result.ToAccount.Owner, data)
+}
+
+// emitPaymentEvent notifies the owner of the account of a deposit or a withdrawal
+func emitPaymentEvent(ctx context.Context, q *Queries, eventType string, payment Payment, account Account) error {
+	return emitEvent(ctx, q, eventType, account.Owner, PaymentEventData{
+		PaymentID:        payment.ID,
+		AccountID:        payment.AccountID,
+		Amount:           payment.Amount,
+		Currency:         account.Currency,
+		Rail:             payment.Rail,
+		TransferID:       payment.TransferID.Int64,
+		RefundTransferID: payment.RefundTransferID.Int64,
+	})
+}
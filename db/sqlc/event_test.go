@@ -0,0 +1,164 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomWebhookSubscription(t *testing.T, owner string, eventTypes string) WebhookSubscription {
+	subscription, err := testQueries.CreateWebhookSubscription(context.Background(), CreateWebhookSubscriptionParams{
+		Owner:      owner,
+		Url:        "https://example.com/" + util.RandomString(6),
+		Secret:     util.RandomString(32),
+		EventTypes: eventTypes,
+	})
+	require.NoError(t, err)
+	require.True(t, subscription.IsActive)
+
+	return subscription
+}
+
+func listSubscriptionDeliveries(t *testing.T, owner string, subscriptionID int64) []WebhookDelivery {
+	deliveries, err := testQueries.ListWebhookDeliveries(context.Background(), ListWebhookDeliveriesParams{
+		Owner:         owner,
+		MaxDeliveries: 10,
+	})
+	require.NoError(t, err)
+
+	var subscriptionDeliveries []WebhookDelivery
+	for _, delivery := range deliveries {
+		if delivery.SubscriptionID == subscriptionID {
+			subscriptionDeliveries = append(subscriptionDeliveries, delivery)
+		}
+	}
+
+	return subscriptionDeliveries
+}
+
+func TestStore_CreateAccountTxEmitsEvent(t *testing.T) {
+	user := createRandomUser(t)
+	subscribed := createRandomWebhookSubscription(t, user.Username, EventAccountCreated+","+EventTransferCompleted)
+	other := createRandomWebhookSubscription(t, user.Username, EventTransferReversed)
+
+	result, err := NewStore(testDB).CreateAccountTx(context.Background(), CreateAccountTxParams{
+		CreateAccountParams: CreateAccountParams{
+			Owner:    user.Username,
+			Currency: util.RandomCurrency(),
+			Type:     AccountTypeChecking,
+		},
+		Audit: AuditInfo{Actor: user.Username},
+	})
+	require.NoError(t, err)
+
+	require.Empty(t, listSubscriptionDeliveries(t, user.Username, other.ID))
+
+	deliveries := listSubscriptionDeliveries(t, user.Username, subscribed.ID)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, WebhookDeliveryStatusPending, deliveries[0].Status)
+
+	event, err := testQueries.GetDomainEvent(context.Background(), deliveries[0].EventID)
+	require.NoError(t, err)
+	require.Equal(t, EventAccountCreated, event.EventType)
+	require.Equal(t, user.Username, event.Username)
+
+	var data AccountEventData
+	require.NoError(t, json.Unmarshal(event.Data, &data))
+	require.Equal(t, result.Account.ID, data.AccountID)
+	require.Equal(t, result.Account.Number, data.Number)
+}
+
+func TestStore_ReplayWebhookDeliveryTx(t *testing.T) {
+	user := createRandomUser(t)
+	subscription := createRandomWebhookSubscription(t, user.Username, "")
+
+	event, err := testQueries.CreateDomainEvent(context.Background(), CreateDomainEventParams{
+		EventType: EventUserCreated,
+		Username:  user.Username,
+		Data:      json.RawMessage(`{}`),
+	})
+	require.NoError(t, err)
+
+	delivery, err := testQueries.CreateWebhookDelivery(context.Background(), CreateWebhookDeliveryParams{
+		SubscriptionID: subscription.ID,
+		EventID:        event.ID,
+	})
+	require.NoError(t, err)
+
+	store := NewStore(testDB)
+
+	// only failed deliveries can be replayed
+	_, err = store.ReplayWebhookDeliveryTx(context.Background(), ReplayWebhookDeliveryTxParams{DeliveryID: delivery.ID})
+	require.ErrorIs(t, err, ErrWebhookDeliveryNotFailed)
+
+	delivery, err = testQueries.UpdateWebhookDelivery(context.Background(), UpdateWebhookDeliveryParams{
+		ID:             delivery.ID,
+		Status:         WebhookDeliveryStatusFailed,
+		ResponseStatus: 500,
+		LastError:      "unexpected response status 500",
+	})
+	require.NoError(t, err)
+	require.Equal(t, int32(1), delivery.Attempts)
+
+	result, err := store.ReplayWebhookDeliveryTx(context.Background(), ReplayWebhookDeliveryTxParams{DeliveryID: delivery.ID})
+	require.NoError(t, err)
+	require.NotEqual(t, delivery.ID, result.Delivery.ID)
+	require.Equal(t, subscription.ID, result.Delivery.SubscriptionID)
+	require.Equal(t, event.ID, result.Delivery.EventID)
+	require.Equal(t, WebhookDeliveryStatusPending, result.Delivery.Status)
+	require.Zero(t, result.Delivery.Attempts)
+
+	// the failed delivery is kept in the log, marked replayed so it is only replayed once
+	require.Equal(t, delivery.ID, result.Original.ID)
+	require.Equal(t, WebhookDeliveryStatusReplayed, result.Original.Status)
+	require.Equal(t, delivery.Attempts, result.Original.Attempts)
+
+	_, err = store.ReplayWebhookDeliveryTx(context.Background(), ReplayWebhookDeliveryTxParams{DeliveryID: delivery.ID})
+	require.ErrorIs(t, err, ErrWebhookDeliveryNotFailed)
+
+	deliveries := listSubscriptionDeliveries(t, user.Username, subscription.ID)
+	require.Len(t, deliveries, 2)
+}
+
+func TestStore_PaymentTxEmitEvents(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	subscription := createRandomWebhookSubscription(t, account.Owner, EventDepositCompleted+","+EventWithdrawalCompleted)
+
+	deposit, err := store.DepositTx(context.Background(), DepositTxParams{
+		PaymentID:   createPendingDeposit(t, account, 100).ID,
+		ExternalRef: util.RandomString(12),
+		Audit:       AuditInfo{Actor: account.Owner},
+	})
+	require.NoError(t, err)
+
+	withdrawal, err := store.CompleteWithdrawalTx(context.Background(), CompleteWithdrawalTxParams{
+		PaymentID:   withdraw(t, account, 40).Payment.ID,
+		ExternalRef: util.RandomString(12),
+	})
+	require.NoError(t, err)
+
+	// the deliveries are listed from the newest
+	deliveries := listSubscriptionDeliveries(t, account.Owner, subscription.ID)
+	require.Len(t, deliveries, 2)
+
+	eventTypes := []string{EventWithdrawalCompleted, EventDepositCompleted}
+	for i, payment := range []Payment{withdrawal.Payment, deposit.Payment} {
+		event, err := testQueries.GetDomainEvent(context.Background(), deliveries[i].EventID)
+		require.NoError(t, err)
+		require.Equal(t, eventTypes[i], event.EventType)
+		require.Equal(t, account.Owner, event.Username)
+
+		var data PaymentEventData
+		require.NoError(t, json.Unmarshal(event.Data, &data))
+		require.Equal(t, payment.ID, data.PaymentID)
+		require.Equal(t, account.ID, data.AccountID)
+		require.Equal(t, payment.Amount, data.Amount)
+		require.Equal(t, account.Currency, data.Currency)
+		require.Equal(t, payment.TransferID.Int64, data.TransferID)
+	}
+}
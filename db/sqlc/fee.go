@@ -0,0 +1,98 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// RevenueAccountOwner owns the revenue accounts, one per currency, which collect the transfer fees
+const RevenueAccountOwner = "simple_bank_revenue"
+
+// ErrNoRevenueAccount is returned when a fee is due in a currency without a revenue account
+var ErrNoRevenueAccount = errors.New("no revenue account for the currency")
+
+// ComputeFee returns the fee of a tier for the given amount: the flat fee plus the
+// percentage of the amount rounded half up, bounded by the minimum and maximum fees
+func ComputeFee(tier FeeTier, amount int64) int64 {
+	fee := tier.FlatFee + (amount*tier.PercentageBps+5000)/10000
+
+	if fee < tier.MinFee {
+		fee = tier.MinFee
+	}
+	if tier.MaxFee.Valid && fee > tier.MaxFee.Int64 {
+		fee = tier.MaxFee.Int64
+	}
+
+	return fee
+}
+
+// QuoteFee returns the fee charged on a transfer of the given amount and currency
+func (store *SQLStore) QuoteFee(ctx context.Context, currency string, amount int64) (int64, error) {
+	return quoteFee(ctx, store.Queries, currency, amount)
+}
+
+// quoteFee applies the tier with the highest minimum amount not above the amount,
+// transfers below every tier of their currency are free
+func quoteFee(ctx context.Context, q *Queries, currency string, amount int64) (int64, error) {
+	tier, err := q.GetFeeTier(ctx, GetFeeTierParams{
+		Currency: currency,
+		Amount:   amount,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return ComputeFee(tier, amount), nil
+}
+
+// chargeTransferFee moves the fee of a transfer from its source account to the revenue account
+func chargeTransferFee(ctx context.Context, q *Queries, result TransferTxResult) (TransferTxResult, error) {
+	transfer := result.Transfer
+
+	revenueAccount, err := q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+		Owner:    RevenueAccountOwner,
+		Currency: result.FromAccount.Currency,
+		Type:     AccountTypeChecking,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return result, ErrNoRevenueAccount
+		}
+		return result, err
+	}
+
+	// the revenue account is locked after the accounts of the transfer,
+	// which is the same order for every transfer so it cannot deadlock
+	feeJournal, err := postJournal(ctx, q, PostJournalTxParams{
+		Description: fmt.Sprintf("fee of transfer %d", transfer.ID),
+		Legs: []JournalLeg{
+			{AccountID: transfer.FromAccountID, Amount: -transfer.Fee},
+			{AccountID: revenueAccount.ID, Amount: transfer.Fee},
+		},
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Transfer, err = q.UpdateTransferFeeJournal(ctx, UpdateTransferFeeJournalParams{
+		ID:           transfer.ID,
+		FeeJournalID: sql.NullInt64{Int64: feeJournal.Journal.ID, Valid: true},
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.FeeEntry = feeJournal.Entries[0]
+	for _, account := range feeJournal.Accounts {
+		if account.ID == transfer.FromAccountID {
+			result.FromAccount = account
+		}
+	}
+
+	return result, nil
+}
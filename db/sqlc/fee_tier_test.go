@@ -0,0 +1,190 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func addFeeTier(t *testing.T, arg CreateFeeTierParams) FeeTier {
+	tier, err := testQueries.CreateFeeTier(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, tier.ID)
+
+	// other tests expect free transfers
+	t.Cleanup(func() {
+		err := testQueries.DeleteFeeTier(context.Background(), tier.ID)
+		require.NoError(t, err)
+	})
+
+	return tier
+}
+
+func TestComputeFee(t *testing.T) {
+	testCases := []struct {
+		name   string
+		tier   FeeTier
+		amount int64
+		fee    int64
+	}{
+		{
+			name:   "Flat",
+			tier:   FeeTier{FlatFee: 25},
+			amount: 1000,
+			fee:    25,
+		},
+		{
+			name:   "Percentage",
+			tier:   FeeTier{PercentageBps: 150},
+			amount: 1000,
+			fee:    15,
+		},
+		{
+			name:   "PercentageRoundsHalfUp",
+			tier:   FeeTier{PercentageBps: 50},
+			amount: 100,
+			fee:    1,
+		},
+		{
+			name:   "FlatAndPercentage",
+			tier:   FeeTier{FlatFee: 10, PercentageBps: 100},
+			amount: 1000,
+			fee:    20,
+		},
+		{
+			name:   "MinFee",
+			tier:   FeeTier{PercentageBps: 100, MinFee: 50},
+			amount: 1000,
+			fee:    50,
+		},
+		{
+			name:   "MaxFee",
+			tier:   FeeTier{PercentageBps: 100, MaxFee: sql.NullInt64{Int64: 5, Valid: true}},
+			amount: 1000,
+			fee:    5,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.fee, ComputeFee(tc.tier, tc.amount))
+		})
+	}
+}
+
+func TestStore_QuoteFee(t *testing.T) {
+	store := NewStore(testDB)
+	currency := util.RandomCurrency()
+
+	addFeeTier(t, CreateFeeTierParams{Currency: currency, MinAmount: 100, FlatFee: 5})
+	addFeeTier(t, CreateFeeTierParams{Currency: currency, MinAmount: 1000, PercentageBps: 100})
+
+	// below every tier
+	fee, err := store.QuoteFee(context.Background(), currency, 99)
+	require.NoError(t, err)
+	require.Zero(t, fee)
+
+	fee, err = store.QuoteFee(context.Background(), currency, 500)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), fee)
+
+	fee, err = store.QuoteFee(context.Background(), currency, 3000)
+	require.NoError(t, err)
+	require.Equal(t, int64(30), fee)
+}
+
+func TestStore_TransferTxWithFee(t *testing.T) {
+	store := NewStore(testDB)
+
+	currency := util.RandomCurrency()
+	fromAccount := addRandomAccountBalance(t, createRandomAccountWithCurrency(t, currency), 1000)
+	toAccount := createRandomAccountWithCurrency(t, currency)
+
+	addFeeTier(t, CreateFeeTierParams{Currency: currency, FlatFee: 7})
+
+	revenueAccount, err := testQueries.GetAccountByOwnerAndCurrency(context.Background(), GetAccountByOwnerAndCurrencyParams{
+		Owner:    RevenueAccountOwner,
+		Currency: currency,
+		Type:     AccountTypeChecking,
+	})
+	require.NoError(t, err)
+
+	amount := int64(100)
+	result, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, int64(7), result.Transfer.Fee)
+	require.True(t, result.Transfer.FeeJournalID.Valid)
+	require.Equal(t, fromAccount.Balance-amount-7, result.FromAccount.Balance)
+	require.Equal(t, toAccount.Balance+amount, result.ToAccount.Balance)
+
+	require.Equal(t, fromAccount.ID, result.FeeEntry.AccountID)
+	require.Equal(t, int64(-7), result.FeeEntry.Amount)
+	require.Equal(t, result.Transfer.FeeJournalID.Int64, result.FeeEntry.JournalID.Int64)
+
+	feeEntries, err := testQueries.ListJournalEntries(context.Background(), result.Transfer.FeeJournalID.Int64)
+	require.NoError(t, err)
+	require.Len(t, feeEntries, 2)
+	require.Equal(t, revenueAccount.ID, feeEntries[1].AccountID)
+	require.Equal(t, int64(7), feeEntries[1].Amount)
+
+	updatedRevenueAccount, err := testQueries.GetAccount(context.Background(), revenueAccount.ID)
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, updatedRevenueAccount.Balance, revenueAccount.Balance+7)
+}
+
+func TestStore_ReverseTransferTxRefundsFee(t *testing.T) {
+	store := NewStore(testDB)
+
+	currency := util.RandomCurrency()
+	fromAccount := addRandomAccountBalance(t, createRandomAccountWithCurrency(t, currency), 1000)
+	toAccount := createRandomAccountWithCurrency(t, currency)
+
+	addFeeTier(t, CreateFeeTierParams{Currency: currency, FlatFee: 7})
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(7), original.Transfer.Fee)
+
+	// 30% of the amount refunds 30% of the fee, rounded down
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     30,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), result.FeeRefund)
+	require.Zero(t, result.Reversal.Transfer.Fee)
+	require.True(t, result.Reversal.Transfer.FeeJournalID.Valid)
+	require.Equal(t, original.FromAccount.Balance+30+2, result.Reversal.ToAccount.Balance)
+
+	refundEntries, err := testQueries.ListJournalEntries(context.Background(), result.Reversal.Transfer.FeeJournalID.Int64)
+	require.NoError(t, err)
+	require.Len(t, refundEntries, 2)
+	require.Equal(t, int64(-2), refundEntries[0].Amount)
+	require.Equal(t, fromAccount.ID, refundEntries[1].AccountID)
+	require.Equal(t, int64(2), refundEntries[1].Amount)
+
+	// the rest of the amount refunds the rest of the fee
+	result, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(5), result.FeeRefund)
+
+	updatedFromAccount, err := testQueries.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance, updatedFromAccount.Balance)
+}
@@ -0,0 +1,267 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func authorizeRandomHold(t *testing.T, store Store, fromAccount, toAccount Account, amount int64, expiresAt time.Time) Hold {
+	result, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+		ExpiresAt:     expiresAt,
+		Audit:         AuditInfo{Actor: fromAccount.Owner},
+	})
+	require.NoError(t, err)
+
+	hold := result.Hold
+	require.NotZero(t, hold.ID)
+	require.Equal(t, fromAccount.ID, hold.FromAccountID)
+	require.Equal(t, toAccount.ID, hold.ToAccountID)
+	require.Equal(t, amount, hold.Amount)
+	require.Equal(t, HoldStatusAuthorized, hold.Status)
+	require.False(t, hold.TransferID.Valid)
+	require.WithinDuration(t, expiresAt, hold.ExpiresAt, time.Second)
+
+	require.Equal(t, fromAccount.Balance, result.FromAccount.Balance)
+	require.Equal(t, fromAccount.HeldBalance+amount+hold.Fee, result.FromAccount.HeldBalance)
+
+	return hold
+}
+
+func TestStore_AuthorizeTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	authorizeRandomHold(t, store, account1, account2, account1.Balance, time.Now().Add(time.Hour))
+
+	// the whole balance is held, so nothing is available anymore
+	_, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+}
+
+func TestStore_CaptureTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	hold := authorizeRandomHold(t, store, account1, account2, account1.Balance/2, time.Now().Add(time.Hour))
+
+	result, err := store.CaptureTransferTx(context.Background(), CaptureTransferTxParams{
+		HoldID: hold.ID,
+		Audit:  AuditInfo{Actor: account1.Owner},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, HoldStatusCaptured, result.Hold.Status)
+	require.True(t, result.Hold.TransferID.Valid)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Hold.TransferID.Int64)
+	require.Equal(t, hold.Amount, result.Transfer.Transfer.Amount)
+
+	require.Equal(t, account1.Balance-hold.Amount-result.Transfer.Transfer.Fee, result.Transfer.FromAccount.Balance)
+	require.Zero(t, result.Transfer.FromAccount.HeldBalance)
+	require.Equal(t, account2.Balance+hold.Amount, result.Transfer.ToAccount.Balance)
+
+	events := listTargetAuditEvents(t, AuditTargetHold, auditID(hold.ID))
+	require.Len(t, events, 2)
+	// newest first
+	require.Equal(t, AuditActionHoldCaptured, events[0].Action)
+	require.Equal(t, AuditActionHoldAuthorized, events[1].Action)
+
+	_, err = store.CaptureTransferTx(context.Background(), CaptureTransferTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotAuthorized)
+
+	_, err = store.VoidTransferTx(context.Background(), VoidTransferTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldNotAuthorized)
+}
+
+func TestStore_CaptureTransferTxHeldFee(t *testing.T) {
+	store := NewStore(testDB)
+
+	currency := util.RandomCurrency()
+	account1 := addRandomAccountBalance(t, createRandomAccountWithCurrency(t, currency), 1000)
+	account2 := createRandomAccountWithCurrency(t, currency)
+
+	addFeeTier(t, CreateFeeTierParams{Currency: currency, FlatFee: 5})
+
+	hold := authorizeRandomHold(t, store, account1, account2, 100, time.Now().Add(time.Hour))
+	require.Equal(t, int64(5), hold.Fee)
+
+	// spend everything but the hold and its fee
+	drain, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance - hold.Amount - hold.Fee - 5,
+	})
+	require.NoError(t, err)
+	require.Equal(t, hold.Amount+hold.Fee, drain.FromAccount.Balance)
+	require.Equal(t, hold.Amount+hold.Fee, drain.FromAccount.HeldBalance)
+
+	result, err := store.CaptureTransferTx(context.Background(), CaptureTransferTxParams{HoldID: hold.ID})
+	require.NoError(t, err)
+
+	require.Equal(t, hold.Fee, result.Transfer.Transfer.Fee)
+	require.Zero(t, result.Transfer.FromAccount.Balance)
+	require.Zero(t, result.Transfer.FromAccount.HeldBalance)
+}
+
+func TestStore_AuthorizeTransferTxLimits(t *testing.T) {
+	store := NewStore(testDB)
+
+	currency := util.RandomCurrency()
+	fromAccount := addRandomAccountBalance(t, createRandomAccountWithCurrency(t, currency), 1000)
+	toAccount := createRandomAccountWithCurrency(t, currency)
+
+	_, err := testQueries.UpsertAccountTransferLimit(context.Background(), UpsertAccountTransferLimitParams{
+		AccountID:   nullInt64(fromAccount.ID),
+		DailyAmount: nullInt64(100),
+	})
+	require.NoError(t, err)
+
+	hold := authorizeRandomHold(t, store, fromAccount, toAccount, 60, time.Now().Add(time.Hour))
+
+	// the authorized hold counts toward the limits of the holds and the transfers
+	_, err = store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        50,
+		ExpiresAt:     time.Now().Add(time.Hour),
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        50,
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	// a voided hold does not
+	_, err = store.VoidTransferTx(context.Background(), VoidTransferTxParams{HoldID: hold.ID})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        50,
+	})
+	require.NoError(t, err)
+}
+
+func TestStore_AuthorizeTransferTxRisk(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	for _, decision := range []RiskDecision{RiskDecisionDeny, RiskDecisionReview} {
+		_, err := store.AuthorizeTransferTx(context.Background(), AuthorizeTransferTxParams{
+			FromAccountID: account1.ID,
+			ToAccountID:   account2.ID,
+			Amount:        1,
+			ExpiresAt:     time.Now().Add(time.Hour),
+			RiskEvaluator: stubRiskEvaluator{decision: decision},
+		})
+		require.ErrorIs(t, err, ErrTransferDenied)
+	}
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.HeldBalance, updatedAccount1.HeldBalance)
+}
+
+func TestStore_CaptureTransferTxExpired(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	hold := authorizeRandomHold(t, store, account1, account2, account1.Balance, time.Now().Add(-time.Second))
+
+	_, err := store.CaptureTransferTx(context.Background(), CaptureTransferTxParams{HoldID: hold.ID})
+	require.ErrorIs(t, err, ErrHoldExpired)
+}
+
+func TestStore_VoidTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	hold := authorizeRandomHold(t, store, account1, account2, account1.Balance, time.Now().Add(time.Hour))
+
+	result, err := store.VoidTransferTx(context.Background(), VoidTransferTxParams{
+		HoldID: hold.ID,
+		Audit:  AuditInfo{Actor: account1.Owner},
+	})
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusVoided, result.Hold.Status)
+	require.Equal(t, account1.Balance, result.FromAccount.Balance)
+	require.Zero(t, result.FromAccount.HeldBalance)
+
+	events := listTargetAuditEvents(t, AuditTargetHold, auditID(hold.ID))
+	require.Len(t, events, 2)
+	require.Equal(t, AuditActionHoldVoided, events[0].Action)
+	require.Equal(t, account1.Owner, events[0].Actor)
+
+	updatedAccount2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+}
+
+func TestStore_ExpireHoldTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	expiresAt := time.Now().Add(time.Minute)
+	hold := authorizeRandomHold(t, store, account1, account2, account1.Balance, expiresAt)
+
+	_, err := store.ExpireHoldTx(context.Background(), ExpireHoldTxParams{
+		HoldID: hold.ID,
+		Now:    time.Now(),
+	})
+	require.ErrorIs(t, err, ErrHoldNotExpired)
+
+	now := expiresAt.Add(time.Second)
+	holds, err := testQueries.ListExpiredHolds(context.Background(), ListExpiredHoldsParams{
+		Now:      now,
+		MaxHolds: 1000,
+	})
+	require.NoError(t, err)
+
+	var holdIDs []int64
+	for _, expiredHold := range holds {
+		holdIDs = append(holdIDs, expiredHold.ID)
+	}
+	require.Contains(t, holdIDs, hold.ID)
+
+	result, err := store.ExpireHoldTx(context.Background(), ExpireHoldTxParams{
+		HoldID: hold.ID,
+		Now:    now,
+	})
+	require.NoError(t, err)
+	require.Equal(t, HoldStatusExpired, result.Hold.Status)
+	require.Zero(t, result.FromAccount.HeldBalance)
+
+	events := listTargetAuditEvents(t, AuditTargetHold, auditID(hold.ID))
+	require.Len(t, events, 2)
+	require.Equal(t, AuditActionHoldExpired, events[0].Action)
+	require.Equal(t, AuditActorSystem, events[0].Actor)
+}
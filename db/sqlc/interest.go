@@ -0,0 +1,182 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+)
+
+const (
+	AccountTypeChecking = "checking"
+	AccountTypeSavings  = "savings"
+)
+
+// InterestExpenseAccountOwner owns the interest expense accounts, one per currency,
+// which pay the interest of the savings accounts
+const InterestExpenseAccountOwner = "simple_bank_interest"
+
+// daysPerYear is the day count of the APR, interest accrues daily on an actual/365 basis
+const daysPerYear = 365
+
+var (
+	// ErrNotSavingsAccount is returned when accruing interest on an account which is not a savings account
+	ErrNotSavingsAccount = errors.New("account is not a savings account")
+	// ErrNoInterestExpenseAccount is returned when there is no interest expense account for the currency of an account
+	ErrNoInterestExpenseAccount = errors.New("no interest expense account for the currency")
+)
+
+// ComputeDailyInterest returns the interest of one day on a balance at the given APR in basis points,
+// rounded half to even to minor units so that rounding errors don't add up in favor of either side
+func ComputeDailyInterest(balance int64, aprBps int64) int64 {
+	if balance <= 0 || aprBps <= 0 {
+		return 0
+	}
+
+	numerator := balance * aprBps
+	denominator := int64(10000 * daysPerYear)
+
+	interest := numerator / denominator
+	remainder := numerator % denominator
+	if 2*remainder > denominator || (2*remainder == denominator && interest%2 == 1) {
+		interest++
+	}
+
+	return interest
+}
+
+type AccrueInterestTxParams struct {
+	AccountID   int64     `json:"account_id"`
+	AccrualDate time.Time `json:"accrual_date"`
+	AprBps      int64     `json:"apr_bps"`
+}
+
+// AccrueInterestTx records the interest of a day on the current balance of a savings account,
+// the accrued interest is paid later by PostInterestTx
+func (store *SQLStore) AccrueInterestTx(ctx context.Context, arg AccrueInterestTxParams) (InterestAccrual, error) {
+	var result InterestAccrual
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if account.Type != AccountTypeSavings {
+			return ErrNotSavingsAccount
+		}
+
+		result, err = q.CreateInterestAccrual(ctx, CreateInterestAccrualParams{
+			AccountID:   account.ID,
+			AccrualDate: arg.AccrualDate,
+			Balance:     account.Balance,
+			AprBps:      arg.AprBps,
+			Amount:      ComputeDailyInterest(account.Balance, arg.AprBps),
+		})
+		return err
+	})
+
+	return result, err
+}
+
+type PostInterestTxParams struct {
+	AccountID int64 `json:"account_id"`
+	// Before is the end of the accrual period, it is excluded
+	Before time.Time `json:"before"`
+}
+
+type PostInterestTxResult struct {
+	Accruals []InterestAccrual `json:"accruals"`
+	// Transfer is empty when the accruals sum to zero
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// PostInterestTx pays the interest accrued on an account before the given date
+// with a transfer from the interest expense account of the same currency, and emits the interest.posted event
+func (store *SQLStore) PostInterestTx(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error) {
+	var result PostInterestTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		// locking the accruals serializes concurrent postings for the account
+		result.Accruals, err = q.ListUnpostedInterestAccrualsForUpdate(ctx, ListUnpostedInterestAccrualsForUpdateParams{
+			AccountID: arg.AccountID,
+			Before:    arg.Before,
+		})
+		if err != nil {
+			return err
+		}
+
+		var amount int64
+		for _, accrual := range result.Accruals {
+			amount += accrual.Amount
+		}
+		if amount == 0 {
+			return nil
+		}
+
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		expenseAccount, err := q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+			Owner:    InterestExpenseAccountOwner,
+			Currency: account.Currency,
+			Type:     AccountTypeChecking,
+		})
+		if err != nil {
+			if err == sql.ErrNoRows {
+				return ErrNoInterestExpenseAccount
+			}
+			return err
+		}
+
+		// the interest expense account is allowed to go negative
+		result.Transfer, err = postTransfer(ctx, q, CreateTransferParams{
+			FromAccountID: expenseAccount.ID,
+			ToAccountID:   account.ID,
+			Amount:        amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		transferID := sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true}
+		err = q.MarkInterestAccrualsPosted(ctx, MarkInterestAccrualsPostedParams{
+			AccountID:  account.ID,
+			Before:     arg.Before,
+			TransferID: transferID,
+		})
+		if err != nil {
+			return err
+		}
+
+		for i := range result.Accruals {
+			result.Accruals[i].TransferID = transferID
+		}
+
+		audit := AuditInfo{Actor: AuditActorSystem}
+		transfer := result.Transfer.Transfer
+		err = recordAudit(ctx, q, audit, AuditActionInterestPosted, AuditTargetTransfer, auditID(transfer.ID), nil, transfer)
+		if err != nil {
+			return err
+		}
+
+		err = emitEvent(ctx, q, EventInterestPosted, account.Owner, InterestEventData{
+			TransferID: transfer.ID,
+			AccountID:  account.ID,
+			Amount:     transfer.Amount,
+			Currency:   account.Currency,
+			Accruals:   len(result.Accruals),
+		})
+		if err != nil {
+			return err
+		}
+
+		return publishAccountUpdates(ctx, q, account.ID)
+	})
+
+	return result, err
+}
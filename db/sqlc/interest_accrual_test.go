@@ -0,0 +1,174 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomSavingsAccount(t *testing.T) Account {
+	user := createRandomUser(t)
+
+	account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  0,
+		Currency: util.RandomCurrency(),
+		Type:     AccountTypeSavings,
+	})
+	require.NoError(t, err)
+	require.Equal(t, AccountTypeSavings, account.Type)
+
+	return account
+}
+
+func TestComputeDailyInterest(t *testing.T) {
+	testCases := []struct {
+		name     string
+		balance  int64
+		aprBps   int64
+		interest int64
+	}{
+		{
+			name:     "RoundsDown",
+			balance:  1_000_000,
+			aprBps:   200,
+			interest: 55, // 54.79
+		},
+		{
+			name:     "HalfToEvenDown",
+			balance:  3_285,
+			aprBps:   5000,
+			interest: 4, // 4.5
+		},
+		{
+			name:     "HalfToEvenUp",
+			balance:  5_475,
+			aprBps:   5000,
+			interest: 8, // 7.5
+		},
+		{
+			name:     "ZeroBalance",
+			balance:  0,
+			aprBps:   200,
+			interest: 0,
+		},
+		{
+			name:     "NegativeBalance",
+			balance:  -1_000_000,
+			aprBps:   200,
+			interest: 0,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.interest, ComputeDailyInterest(tc.balance, tc.aprBps))
+		})
+	}
+}
+
+func TestStore_AccrueInterestTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	checkingAccount := createRandomAccount(t)
+	_, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{
+		AccountID:   checkingAccount.ID,
+		AccrualDate: time.Now().UTC(),
+		AprBps:      200,
+	})
+	require.ErrorIs(t, err, ErrNotSavingsAccount)
+
+	account := addRandomAccountBalance(t, createRandomSavingsAccount(t), 1_000_000)
+	accrualDate := time.Date(2022, time.March, 31, 0, 0, 0, 0, time.UTC)
+
+	accrual, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{
+		AccountID:   account.ID,
+		AccrualDate: accrualDate,
+		AprBps:      200,
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, accrual.AccountID)
+	require.Equal(t, account.Balance, accrual.Balance)
+	require.Equal(t, int64(200), accrual.AprBps)
+	require.Equal(t, ComputeDailyInterest(account.Balance, 200), accrual.Amount)
+	require.False(t, accrual.TransferID.Valid)
+
+	// interest accrues once a day
+	_, err = store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{
+		AccountID:   account.ID,
+		AccrualDate: accrualDate,
+		AprBps:      200,
+	})
+	require.Error(t, err)
+}
+
+func TestStore_PostInterestTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := addRandomAccountBalance(t, createRandomSavingsAccount(t), 1_000_000)
+
+	var total int64
+	for day := 1; day <= 3; day++ {
+		accrual, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{
+			AccountID:   account.ID,
+			AccrualDate: time.Date(2022, time.March, day, 0, 0, 0, 0, time.UTC),
+			AprBps:      200,
+		})
+		require.NoError(t, err)
+		total += accrual.Amount
+	}
+
+	// accruals of the current month are not posted yet
+	_, err := store.AccrueInterestTx(context.Background(), AccrueInterestTxParams{
+		AccountID:   account.ID,
+		AccrualDate: time.Date(2022, time.April, 1, 0, 0, 0, 0, time.UTC),
+		AprBps:      200,
+	})
+	require.NoError(t, err)
+
+	before := time.Date(2022, time.April, 1, 0, 0, 0, 0, time.UTC)
+	result, err := store.PostInterestTx(context.Background(), PostInterestTxParams{
+		AccountID: account.ID,
+		Before:    before,
+	})
+	require.NoError(t, err)
+	require.Len(t, result.Accruals, 3)
+
+	transfer := result.Transfer
+	require.Equal(t, total, transfer.Transfer.Amount)
+	require.Equal(t, account.ID, transfer.ToAccount.ID)
+	require.Equal(t, account.Balance+total, transfer.ToAccount.Balance)
+	require.Equal(t, InterestExpenseAccountOwner, transfer.FromAccount.Owner)
+	require.Equal(t, account.Currency, transfer.FromAccount.Currency)
+
+	accruals, err := testQueries.ListInterestAccruals(context.Background(), ListInterestAccrualsParams{
+		AccountID: account.ID,
+		Limit:     5,
+		Offset:    0,
+	})
+	require.NoError(t, err)
+	require.Len(t, accruals, 4)
+	require.False(t, accruals[0].TransferID.Valid)
+	for _, accrual := range accruals[1:] {
+		require.Equal(t, transfer.Transfer.ID, accrual.TransferID.Int64)
+	}
+
+	events := listTargetAuditEvents(t, AuditTargetTransfer, auditID(transfer.Transfer.ID))
+	require.Len(t, events, 1)
+	require.Equal(t, AuditActionInterestPosted, events[0].Action)
+	require.Equal(t, AuditActorSystem, events[0].Actor)
+
+	// posting again has nothing left to pay
+	result, err = store.PostInterestTx(context.Background(), PostInterestTxParams{
+		AccountID: account.ID,
+		Before:    before,
+	})
+	require.NoError(t, err)
+	require.Empty(t, result.Accruals)
+	require.Zero(t, result.Transfer.Transfer.ID)
+}
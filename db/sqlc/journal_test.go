@@ -0,0 +1,149 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_PostJournalTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	currency := util.RandomCurrency()
+	payer := addRandomAccountBalance(t, createRandomAccountWithCurrency(t, currency), 1000)
+	payee1 := createRandomAccountWithCurrency(t, currency)
+	payee2 := createRandomAccountWithCurrency(t, currency)
+
+	banker := createRandomUser(t)
+
+	arg := PostJournalTxParams{
+		Description: util.RandomString(12),
+		Legs: []JournalLeg{
+			{AccountID: payer.ID, Amount: -30},
+			{AccountID: payee1.ID, Amount: 20},
+			{AccountID: payee2.ID, Amount: 10},
+		},
+		Audit: AuditInfo{Actor: banker.Username},
+	}
+
+	result, err := store.PostJournalTx(context.Background(), arg)
+	require.NoError(t, err)
+
+	require.NotZero(t, result.Journal.ID)
+	require.Equal(t, arg.Description, result.Journal.Description)
+	require.Len(t, result.Accounts, 3)
+	require.Len(t, result.Entries, 3)
+
+	for i, entry := range result.Entries {
+		require.Equal(t, arg.Legs[i].AccountID, entry.AccountID)
+		require.Equal(t, arg.Legs[i].Amount, entry.Amount)
+		require.Equal(t, result.Journal.ID, entry.JournalID.Int64)
+	}
+
+	entries, err := testQueries.ListJournalEntries(context.Background(), result.Journal.ID)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+
+	balances := map[int64]int64{
+		payer.ID:  payer.Balance - 30,
+		payee1.ID: payee1.Balance + 20,
+		payee2.ID: payee2.Balance + 10,
+	}
+	for _, account := range result.Accounts {
+		require.Equal(t, balances[account.ID], account.Balance)
+	}
+
+	events := listTargetAuditEvents(t, AuditTargetJournal, auditID(result.Journal.ID))
+	require.Len(t, events, 1)
+	require.Equal(t, AuditActionJournalPosted, events[0].Action)
+	require.Equal(t, banker.Username, events[0].Actor)
+}
+
+func TestStore_PostJournalTxInvalid(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := addRandomAccountBalance(t, createRandomAccountWithCurrency(t, util.USD), 1000)
+	account2 := createRandomAccountWithCurrency(t, util.USD)
+	account3 := createRandomAccountWithCurrency(t, util.EUR)
+
+	testCases := []struct {
+		name string
+		legs []JournalLeg
+		err  error
+	}{
+		{
+			name: "TooFewLegs",
+			legs: []JournalLeg{{AccountID: account1.ID, Amount: 0}},
+			err:  ErrJournalTooFewLegs,
+		},
+		{
+			name: "ZeroLeg",
+			legs: []JournalLeg{{AccountID: account1.ID, Amount: 0}, {AccountID: account2.ID, Amount: 0}},
+			err:  ErrJournalZeroLeg,
+		},
+		{
+			name: "Unbalanced",
+			legs: []JournalLeg{{AccountID: account1.ID, Amount: -10}, {AccountID: account2.ID, Amount: 5}},
+			err:  ErrJournalUnbalanced,
+		},
+		{
+			name: "CurrencyMismatch",
+			legs: []JournalLeg{{AccountID: account1.ID, Amount: -10}, {AccountID: account3.ID, Amount: 10}},
+			err:  ErrJournalCurrencyMismatch,
+		},
+		{
+			name: "InsufficientFunds",
+			legs: []JournalLeg{{AccountID: account2.ID, Amount: -account2.Balance - 1}, {AccountID: account1.ID, Amount: account2.Balance + 1}},
+			err:  ErrInsufficientFunds,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := store.PostJournalTx(context.Background(), PostJournalTxParams{Legs: tc.legs})
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestStore_PostJournalTxLock(t *testing.T) {
+	store := NewStore(testDB)
+
+	currency := util.RandomCurrency()
+	accounts := make([]Account, 3)
+	for i := range accounts {
+		accounts[i] = addRandomAccountBalance(t, createRandomAccountWithCurrency(t, currency), 1000)
+	}
+
+	// run n concurrent journals rotating the legs, so each of them locks the accounts in a different order
+	n := 9
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		legs := []JournalLeg{
+			{AccountID: accounts[i%3].ID, Amount: -20},
+			{AccountID: accounts[(i+1)%3].ID, Amount: 10},
+			{AccountID: accounts[(i+2)%3].ID, Amount: 10},
+		}
+
+		go func() {
+			_, err := store.PostJournalTx(context.Background(), PostJournalTxParams{Legs: legs})
+			errs <- err
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		err := <-errs
+		require.NoError(t, err)
+	}
+
+	for _, account := range accounts {
+		updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+		require.NoError(t, err)
+		require.Equal(t, account.Balance, updatedAccount.Balance)
+	}
+}
@@ -5,6 +5,8 @@
 package db
 
 import (
+	"database/sql"
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -16,6 +18,52 @@ type Account struct {
 	Balance   int64     `json:"balance"`
 	Currency  string    `json:"currency"`
 	CreatedAt time.Time `json:"created_at"`
+	// sum of the authorized holds with their fees and of the transfers pending review, the available balance is balance - held_balance
+	HeldBalance int64 `json:"held_balance"`
+	// checking or savings
+	Type string `json:"type"`
+	// active, frozen or closed
+	Status string `json:"status"`
+	// IBAN-style number with check digits to share instead of the id
+	Number string `json:"number"`
+}
+
+type AccountStatusChange struct {
+	ID         int64  `json:"id"`
+	AccountID  int64  `json:"account_id"`
+	FromStatus string `json:"from_status"`
+	ToStatus   string `json:"to_status"`
+	Reason     string `json:"reason"`
+	// banker or owner who changed the status
+	ChangedBy string    `json:"changed_by"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AuditEvent struct {
+	ID int64 `json:"id"`
+	// username of the user who did the action, kept without a foreign key so the log is append only
+	Actor string `json:"actor"`
+	// like user.updated or account.closed
+	Action     string `json:"action"`
+	TargetType string `json:"target_type"`
+	TargetID   string `json:"target_id"`
+	// changed fields before the action, empty for creations
+	Before json.RawMessage `json:"before"`
+	// changed fields after the action
+	After     json.RawMessage `json:"after"`
+	ClientIp  string          `json:"client_ip"`
+	UserAgent string          `json:"user_agent"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+type DomainEvent struct {
+	ID int64 `json:"id"`
+	// like transfer.completed
+	EventType string `json:"event_type"`
+	// user notified of the event
+	Username  string          `json:"username"`
+	Data      json.RawMessage `json:"data"`
+	CreatedAt time.Time       `json:"created_at"`
 }
 
 type Entry struct {
@@ -24,6 +72,158 @@ type Entry struct {
 	// can be negative or positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// transfer which posted the entry
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	// hash of the previous entry of the account, empty for the first one
+	PrevHash []byte `json:"prev_hash"`
+	// sha256 of prev_hash and the entry contents
+	Hash []byte `json:"hash"`
+	// journal which posted the entry
+	JournalID sql.NullInt64 `json:"journal_id"`
+	// 1 for the hashes of the contents without the journal, 2 for the hashes covering it
+	HashVersion int16 `json:"hash_version"`
+}
+
+type FeeTier struct {
+	ID       int64  `json:"id"`
+	Currency string `json:"currency"`
+	// smallest transfer amount the tier applies to
+	MinAmount int64 `json:"min_amount"`
+	FlatFee   int64 `json:"flat_fee"`
+	// percentage of the amount in basis points
+	PercentageBps int64 `json:"percentage_bps"`
+	MinFee        int64 `json:"min_fee"`
+	// no maximum when null
+	MaxFee    sql.NullInt64 `json:"max_fee"`
+	CreatedAt time.Time     `json:"created_at"`
+}
+
+type Hold struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	// must be positive
+	Amount int64 `json:"amount"`
+	// authorized, captured, voided or expired
+	Status     string        `json:"status"`
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	ExpiresAt  time.Time     `json:"expires_at"`
+	CreatedAt  time.Time     `json:"created_at"`
+	// quoted when authorized, held with the amount and charged on capture
+	Fee int64 `json:"fee"`
+}
+
+type InterestAccrual struct {
+	ID          int64     `json:"id"`
+	AccountID   int64     `json:"account_id"`
+	AccrualDate time.Time `json:"accrual_date"`
+	// balance the interest accrued on
+	Balance int64 `json:"balance"`
+	// annual percentage rate in basis points
+	AprBps int64 `json:"apr_bps"`
+	// interest of the day rounded half to even
+	Amount int64 `json:"amount"`
+	// monthly transfer which paid the interest, null until then
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type Journal struct {
+	ID          int64     `json:"id"`
+	Description string    `json:"description"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type Outbox struct {
+	ID       int64           `json:"id"`
+	TaskType string          `json:"task_type"`
+	Payload  json.RawMessage `json:"payload"`
+	Queue    string          `json:"queue"`
+	MaxRetry int32           `json:"max_retry"`
+	// when the task must be processed, it is published to the queue right away
+	ProcessAt time.Time `json:"process_at"`
+	// failed attempts to publish the task
+	Attempts  int32  `json:"attempts"`
+	LastError string `json:"last_error"`
+	// when the task was published to the queue
+	SentAt    sql.NullTime `json:"sent_at"`
+	CreatedAt time.Time    `json:"created_at"`
+	// W3C trace context of the transaction which wrote the message, continued by its task
+	TraceContext json.RawMessage `json:"trace_context"`
+	// ID of the request which wrote the message, empty for the background tasks
+	RequestID string `json:"request_id"`
+}
+
+type Payee struct {
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	// name the owner gave to the payee
+	Nickname  string    `json:"nickname"`
+	AccountID int64     `json:"account_id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type Payment struct {
+	ID        int64 `json:"id"`
+	AccountID int64 `json:"account_id"`
+	// deposit or withdrawal
+	Kind string `json:"kind"`
+	// must be positive
+	Amount int64  `json:"amount"`
+	Rail   string `json:"rail"`
+	// reference of the payment on the rail, empty until the rail accepts it
+	ExternalRef string `json:"external_ref"`
+	// transfer crediting a deposit once collected, or debiting a withdrawal before it is paid out
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	CreatedAt  time.Time     `json:"created_at"`
+	// pending, completed or failed
+	Status string `json:"status"`
+	// funding source of a deposit or destination of a withdrawal
+	ExternalAccount string `json:"external_account"`
+	LastError       string `json:"last_error"`
+	// transfer giving the money of a failed withdrawal back
+	RefundTransferID sql.NullInt64 `json:"refund_transfer_id"`
+	UpdatedAt        time.Time     `json:"updated_at"`
+}
+
+type ReconciliationRun struct {
+	ID                    int64 `json:"id"`
+	AccountsChecked       int64 `json:"accounts_checked"`
+	TransfersChecked      int64 `json:"transfers_checked"`
+	AccountDiscrepancies  int64 `json:"account_discrepancies"`
+	TransferDiscrepancies int64 `json:"transfer_discrepancies"`
+	// mismatching accounts and transfers
+	Details    json.RawMessage `json:"details"`
+	StartedAt  time.Time       `json:"started_at"`
+	FinishedAt time.Time       `json:"finished_at"`
+}
+
+type ScheduledTransfer struct {
+	ID            int64  `json:"id"`
+	Owner         string `json:"owner"`
+	FromAccountID int64  `json:"from_account_id"`
+	ToAccountID   int64  `json:"to_account_id"`
+	Amount        int64  `json:"amount"`
+	// cron expression or @every interval
+	Recurrence string       `json:"recurrence"`
+	StartAt    time.Time    `json:"start_at"`
+	EndAt      sql.NullTime `json:"end_at"`
+	NextRunAt  time.Time    `json:"next_run_at"`
+	// active, paused, completed or cancelled
+	Status string `json:"status"`
+	// consecutive insufficient funds failures
+	FailureCount int32     `json:"failure_count"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type ScheduledTransferExecution struct {
+	ID                  int64         `json:"id"`
+	ScheduledTransferID int64         `json:"scheduled_transfer_id"`
+	TransferID          sql.NullInt64 `json:"transfer_id"`
+	// succeeded, failed or held for review
+	Status     string    `json:"status"`
+	Error      string    `json:"error"`
+	ExecutedAt time.Time `json:"executed_at"`
 }
 
 type Session struct {
@@ -44,6 +244,47 @@ type Transfer struct {
 	// must be positive
 	Amount    int64     `json:"amount"`
 	CreatedAt time.Time `json:"created_at"`
+	// the transfer refunded by this one
+	ReversalOfID sql.NullInt64 `json:"reversal_of_id"`
+	// charged to the sender on top of the amount
+	Fee int64 `json:"fee"`
+	// journal which moved the fee to the revenue account
+	FeeJournalID sql.NullInt64 `json:"fee_journal_id"`
+}
+
+type TransferLimit struct {
+	ID int64 `json:"id"`
+	// limits all the accounts of the user together, null for account limits
+	Username sql.NullString `json:"username"`
+	// limits a single account, null for user limits
+	AccountID sql.NullInt64 `json:"account_id"`
+	// the limits are unlimited when null
+	MaxSingleAmount sql.NullInt64 `json:"max_single_amount"`
+	// total amount per calendar day in UTC
+	DailyAmount sql.NullInt64 `json:"daily_amount"`
+	// total amount per calendar month in UTC
+	MonthlyAmount sql.NullInt64 `json:"monthly_amount"`
+	// number of transfers in the last hour
+	HourlyCount sql.NullInt64 `json:"hourly_count"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+type TransferReview struct {
+	ID            int64 `json:"id"`
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+	// held on the source account while pending
+	Amount int64 `json:"amount"`
+	// risk rules which flagged the transfer
+	Reasons string `json:"reasons"`
+	// pending, approved or rejected
+	Status     string         `json:"status"`
+	ReviewedBy sql.NullString `json:"reviewed_by"`
+	ReviewedAt sql.NullTime   `json:"reviewed_at"`
+	// transfer executed on approval
+	TransferID sql.NullInt64 `json:"transfer_id"`
+	CreatedAt  time.Time     `json:"created_at"`
 }
 
 type User struct {
@@ -53,4 +294,32 @@ type User struct {
 	Email             string    `json:"email"`
 	PasswordChangedAt time.Time `json:"password_changed_at"`
 	CreatedAt         time.Time `json:"created_at"`
+	// depositor or banker
+	Role string `json:"role"`
+}
+
+type WebhookDelivery struct {
+	ID             int64 `json:"id"`
+	SubscriptionID int64 `json:"subscription_id"`
+	EventID        int64 `json:"event_id"`
+	// pending, succeeded, failed or replayed by a new delivery
+	Status   string `json:"status"`
+	Attempts int32  `json:"attempts"`
+	// HTTP status of the last attempt, 0 when no response was received
+	ResponseStatus int32        `json:"response_status"`
+	LastError      string       `json:"last_error"`
+	DeliveredAt    sql.NullTime `json:"delivered_at"`
+	CreatedAt      time.Time    `json:"created_at"`
+}
+
+type WebhookSubscription struct {
+	ID    int64  `json:"id"`
+	Owner string `json:"owner"`
+	Url   string `json:"url"`
+	// key signing the payloads with HMAC-SHA256
+	Secret string `json:"secret"`
+	// comma separated event types to deliver, all of them when empty
+	EventTypes string    `json:"event_types"`
+	IsActive   bool      `json:"is_active"`
+	CreatedAt  time.Time `json:"created_at"`
 }
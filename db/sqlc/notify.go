@@ -0,0 +1,26 @@
+package db
+
+import (
+	"context"
+	"strconv"
+)
+
+// AccountUpdatedChannel is the Postgres NOTIFY channel on which the IDs
+// of accounts whose balance or entries changed are published
+const AccountUpdatedChannel = "account_updated"
+
+// publishAccountUpdates publishes the IDs of the changed accounts on AccountUpdatedChannel.
+// Notifications are only delivered to listeners once the surrounding transaction commits.
+func publishAccountUpdates(ctx context.Context, q *Queries, accountIDs ...int64) error {
+	for _, accountID := range accountIDs {
+		err := q.NotifyAccountUpdated(ctx, NotifyAccountUpdatedParams{
+			Channel: AccountUpdatedChannel,
+			Payload: strconv.FormatInt(accountID, 10),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,110 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/Dejan91/simple_bank/util"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"time"
+)
+
+// OutboxTask is a background task written to the outbox in the transaction creating it,
+// the relay publishes it to the task queue once the transaction commits
+type OutboxTask struct {
+	Type string
+	// Payload is marshalled to JSON
+	Payload interface{}
+	// Queue is the default queue when empty
+	Queue     string
+	MaxRetry  int32
+	ProcessIn time.Duration
+}
+
+// enqueueOutboxTasks writes the tasks to the outbox, so they are only published if the transaction commits
+func enqueueOutboxTasks(ctx context.Context, q *Queries, tasks ...OutboxTask) error {
+	// the relay publishes the tasks long after the request is done,
+	// so it continues its trace and restores its request ID from the message
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	traceContext, err := json.Marshal(carrier)
+	if err != nil {
+		return fmt.Errorf("failed to marshal trace context: %w", err)
+	}
+
+	for _, task := range tasks {
+		payload, err := json.Marshal(task.Payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s payload: %w", task.Type, err)
+		}
+
+		_, err = q.CreateOutboxMessage(ctx, CreateOutboxMessageParams{
+			TaskType:     task.Type,
+			Payload:      payload,
+			Queue:        task.Queue,
+			MaxRetry:     task.MaxRetry,
+			ProcessAt:    time.Now().Add(task.ProcessIn),
+			TraceContext: traceContext,
+			RequestID:    util.RequestIDFromContext(ctx),
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type RelayOutboxTxParams struct {
+	// Limit is the maximum number of messages relayed by the transaction
+	Limit int32
+	// Publish publishes a message to the task queue, it must be idempotent
+	// as the message is published again if the transaction fails to commit
+	Publish func(message Outbox) error
+}
+
+type RelayOutboxTxResult struct {
+	Sent   int
+	Failed int
+}
+
+// RelayOutboxTx publishes the unsent outbox messages in order and marks them sent.
+// Messages which fail to publish record the error and are retried by the next relay,
+// the messages locked by a concurrent relay are skipped
+func (store *SQLStore) RelayOutboxTx(ctx context.Context, arg RelayOutboxTxParams) (RelayOutboxTxResult, error) {
+	var result RelayOutboxTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		messages, err := q.ListUnsentOutboxMessagesForUpdate(ctx, arg.Limit)
+		if err != nil {
+			return err
+		}
+
+		for _, message := range messages {
+			if publishErr := arg.Publish(message); publishErr != nil {
+				_, err = q.RecordOutboxMessageFailure(ctx, RecordOutboxMessageFailureParams{
+					ID:        message.ID,
+					LastError: publishErr.Error(),
+				})
+				if err != nil {
+					return err
+				}
+
+				result.Failed++
+				continue
+			}
+
+			_, err = q.MarkOutboxMessageSent(ctx, message.ID)
+			if err != nil {
+				return err
+			}
+
+			result.Sent++
+		}
+
+		return nil
+	})
+
+	return result, err
+}
@@ -0,0 +1,145 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func createUserWithOutboxTask(t *testing.T) User {
+	hashedPassword, err := util.HashPassword(util.RandomString(6))
+	require.NoError(t, err)
+
+	result, err := NewStore(testDB).CreateUserTx(context.Background(), CreateUserTxParams{
+		CreateUserParams: CreateUserParams{
+			Username:       util.RandomOwner(),
+			HashedPassword: hashedPassword,
+			FullName:       util.RandomOwner(),
+			Email:          util.RandomEmail(),
+		},
+		AfterCreate: func(user User) []OutboxTask {
+			return []OutboxTask{{
+				Type:     "task:test",
+				Payload:  map[string]string{"username": user.Username},
+				Queue:    "default",
+				MaxRetry: 3,
+			}}
+		},
+	})
+	require.NoError(t, err)
+
+	return result.User
+}
+
+// relayOutbox relays the whole outbox and returns the published messages by the username in their payload
+func relayOutbox(t *testing.T, publish func(message Outbox) error) map[string]Outbox {
+	published := map[string]Outbox{}
+
+	result, err := NewStore(testDB).RelayOutboxTx(context.Background(), RelayOutboxTxParams{
+		Limit: 1000,
+		Publish: func(message Outbox) error {
+			var payload struct {
+				Username string `json:"username"`
+			}
+			require.NoError(t, json.Unmarshal(message.Payload, &payload))
+
+			published[payload.Username] = message
+			return publish(message)
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, len(published), result.Sent+result.Failed)
+
+	return published
+}
+
+func TestStore_RelayOutboxTx(t *testing.T) {
+	user := createUserWithOutboxTask(t)
+
+	published := relayOutbox(t, func(message Outbox) error {
+		return errors.New("queue unavailable")
+	})
+	require.Contains(t, published, user.Username)
+
+	published = relayOutbox(t, func(message Outbox) error {
+		return nil
+	})
+	require.Contains(t, published, user.Username)
+
+	message := published[user.Username]
+	require.Equal(t, "task:test", message.TaskType)
+	require.Equal(t, "default", message.Queue)
+	require.Equal(t, int32(3), message.MaxRetry)
+	require.Equal(t, int32(1), message.Attempts)
+	require.Equal(t, "queue unavailable", message.LastError)
+
+	// sent messages are not published again
+	published = relayOutbox(t, func(message Outbox) error {
+		return nil
+	})
+	require.NotContains(t, published, user.Username)
+}
+
+func TestStore_CreateUserTxFailureEnqueuesNoTask(t *testing.T) {
+	user := createRandomUser(t)
+
+	_, err := NewStore(testDB).CreateUserTx(context.Background(), CreateUserTxParams{
+		CreateUserParams: CreateUserParams{
+			Username:       user.Username,
+			HashedPassword: user.HashedPassword,
+			FullName:       user.FullName,
+			Email:          util.RandomEmail(),
+		},
+		AfterCreate: func(user User) []OutboxTask {
+			t.Fatal("tasks of a user which failed to be created must not be enqueued")
+			return nil
+		},
+	})
+	require.Error(t, err)
+}
+
+func TestStore_EnqueueOutboxTasksMetadata(t *testing.T) {
+	propagator := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		otel.SetTextMapPropagator(propagator)
+	})
+
+	spanContext := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 2, 3},
+		SpanID:     trace.SpanID{4, 5, 6},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), spanContext)
+	requestID := util.NewRequestID()
+	ctx = util.ContextWithRequestID(ctx, requestID)
+
+	username := util.RandomOwner()
+	err := enqueueOutboxTasks(ctx, testQueries, OutboxTask{
+		Type:    "task:test",
+		Payload: map[string]string{"username": username},
+	})
+	require.NoError(t, err)
+
+	published := relayOutbox(t, func(message Outbox) error {
+		return nil
+	})
+	require.Contains(t, published, username)
+
+	// the relay continues the trace and restores the request of the transaction which wrote the message
+	require.Equal(t, requestID, published[username].RequestID)
+
+	var carrier propagation.MapCarrier
+	require.NoError(t, json.Unmarshal(published[username].TraceContext, &carrier))
+
+	relayed := trace.SpanContextFromContext(propagation.TraceContext{}.Extract(context.Background(), carrier))
+	require.Equal(t, spanContext.TraceID(), relayed.TraceID())
+	require.Equal(t, spanContext.SpanID(), relayed.SpanID())
+}
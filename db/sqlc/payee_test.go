@@ -0,0 +1,99 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomPayee(t *testing.T, owner string) (Payee, Account) {
+	account := createRandomAccount(t)
+
+	arg := CreatePayeeParams{
+		Owner:     owner,
+		Nickname:  util.RandomOwner(),
+		AccountID: account.ID,
+	}
+
+	payee, err := testQueries.CreatePayee(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotZero(t, payee.ID)
+	require.Equal(t, arg.Owner, payee.Owner)
+	require.Equal(t, arg.Nickname, payee.Nickname)
+	require.Equal(t, arg.AccountID, payee.AccountID)
+	require.NotZero(t, payee.CreatedAt)
+
+	return payee, account
+}
+
+func TestQueries_CreatePayee(t *testing.T) {
+	user := createRandomUser(t)
+	payee, _ := createRandomPayee(t, user.Username)
+
+	// the nickname is unique per owner
+	_, err := testQueries.CreatePayee(context.Background(), CreatePayeeParams{
+		Owner:     user.Username,
+		Nickname:  payee.Nickname,
+		AccountID: createRandomAccount(t).ID,
+	})
+	require.Error(t, err)
+
+	// and so is the account
+	_, err = testQueries.CreatePayee(context.Background(), CreatePayeeParams{
+		Owner:     user.Username,
+		Nickname:  util.RandomOwner(),
+		AccountID: payee.AccountID,
+	})
+	require.Error(t, err)
+}
+
+func TestQueries_ListPayees(t *testing.T) {
+	user := createRandomUser(t)
+
+	accounts := make(map[int64]Account)
+	for i := 0; i < 3; i++ {
+		payee, account := createRandomPayee(t, user.Username)
+		accounts[payee.ID] = account
+	}
+
+	payees, err := testQueries.ListPayees(context.Background(), ListPayeesParams{
+		Owner:  user.Username,
+		Limit:  5,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.Len(t, payees, 3)
+
+	for _, payee := range payees {
+		require.Equal(t, user.Username, payee.Owner)
+		require.Equal(t, accounts[payee.ID].Number, payee.AccountNumber)
+		require.Equal(t, accounts[payee.ID].Currency, payee.Currency)
+	}
+}
+
+func TestQueries_UpdatePayee(t *testing.T) {
+	user := createRandomUser(t)
+	payee1, _ := createRandomPayee(t, user.Username)
+
+	payee2, err := testQueries.UpdatePayee(context.Background(), UpdatePayeeParams{
+		ID:       payee1.ID,
+		Nickname: util.RandomOwner(),
+	})
+	require.NoError(t, err)
+	require.NotEqual(t, payee1.Nickname, payee2.Nickname)
+	require.Equal(t, payee1.AccountID, payee2.AccountID)
+}
+
+func TestQueries_DeletePayee(t *testing.T) {
+	user := createRandomUser(t)
+	payee, _ := createRandomPayee(t, user.Username)
+
+	err := testQueries.DeletePayee(context.Background(), payee.ID)
+	require.NoError(t, err)
+
+	_, err = testQueries.GetPayee(context.Background(), payee.ID)
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
@@ -0,0 +1,243 @@
+package db
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func createPendingDeposit(t *testing.T, account Account, amount int64) Payment {
+	payment, err := testQueries.CreatePayment(context.Background(), CreatePaymentParams{
+		AccountID:       account.ID,
+		Kind:            PaymentKindDeposit,
+		Amount:          amount,
+		Rail:            "test",
+		ExternalAccount: util.RandomString(10),
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentStatusPending, payment.Status)
+	require.Empty(t, payment.ExternalRef)
+	require.False(t, payment.TransferID.Valid)
+
+	return payment
+}
+
+func withdraw(t *testing.T, account Account, amount int64) WithdrawTxResult {
+	result, err := NewStore(testDB).WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID:   account.ID,
+		Amount:      amount,
+		Rail:        "test",
+		Destination: util.RandomString(10),
+		Audit:       AuditInfo{Actor: account.Owner},
+	})
+	require.NoError(t, err)
+
+	return result
+}
+
+func TestStore_DepositTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	amount := util.RandomMoney()
+	externalRef := util.RandomString(12)
+	pendingPayment := createPendingDeposit(t, account, amount)
+
+	result, err := store.DepositTx(context.Background(), DepositTxParams{
+		PaymentID:   pendingPayment.ID,
+		ExternalRef: externalRef,
+		Audit:       AuditInfo{Actor: account.Owner},
+	})
+	require.NoError(t, err)
+
+	payment := result.Payment
+	require.Equal(t, pendingPayment.ID, payment.ID)
+	require.Equal(t, PaymentStatusCompleted, payment.Status)
+	require.Equal(t, externalRef, payment.ExternalRef)
+	require.Equal(t, result.Transfer.Transfer.ID, payment.TransferID.Int64)
+
+	clearingAccount := result.Transfer.FromAccount
+	require.Equal(t, ClearingAccountOwner, clearingAccount.Owner)
+	require.Equal(t, account.Currency, clearingAccount.Currency)
+
+	require.Equal(t, account.Balance+amount, result.Transfer.ToAccount.Balance)
+	require.Equal(t, amount, result.Transfer.ToEntry.Amount)
+	require.Equal(t, -amount, result.Transfer.FromEntry.Amount)
+
+	events := listTargetAuditEvents(t, AuditTargetPayment, auditID(payment.ID))
+	require.Len(t, events, 1)
+	require.Equal(t, AuditActionDepositCompleted, events[0].Action)
+
+	// a deposit is credited once
+	_, err = store.DepositTx(context.Background(), DepositTxParams{
+		PaymentID:   pendingPayment.ID,
+		ExternalRef: externalRef,
+	})
+	require.ErrorIs(t, err, ErrPaymentNotPending)
+
+	// the same rail payment cannot be deposited twice
+	_, err = store.DepositTx(context.Background(), DepositTxParams{
+		PaymentID:   createPendingDeposit(t, account, amount).ID,
+		ExternalRef: externalRef,
+	})
+	require.Error(t, err)
+}
+
+func TestStore_FailDepositTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	pendingPayment := createPendingDeposit(t, account, util.RandomMoney())
+
+	result, err := store.FailDepositTx(context.Background(), FailDepositTxParams{
+		PaymentID: pendingPayment.ID,
+		LastError: "declined",
+		Audit:     AuditInfo{Actor: account.Owner},
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentStatusFailed, result.Payment.Status)
+	require.Equal(t, "declined", result.Payment.LastError)
+	require.Empty(t, result.Payment.ExternalRef)
+
+	_, err = store.DepositTx(context.Background(), DepositTxParams{
+		PaymentID:   pendingPayment.ID,
+		ExternalRef: util.RandomString(12),
+	})
+	require.ErrorIs(t, err, ErrPaymentNotPending)
+
+	updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, updatedAccount.Balance)
+}
+
+func TestQueries_ListStalePendingPayments(t *testing.T) {
+	pendingPayment := createPendingDeposit(t, createRandomAccount(t), util.RandomMoney())
+
+	createdBefore := pendingPayment.CreatedAt.Add(-time.Second)
+	payments, err := testQueries.ListStalePendingPayments(context.Background(), ListStalePendingPaymentsParams{
+		CreatedBefore: createdBefore,
+		MaxPayments:   100,
+	})
+	require.NoError(t, err)
+
+	for _, payment := range payments {
+		require.NotEqual(t, pendingPayment.ID, payment.ID)
+		require.Equal(t, PaymentStatusPending, payment.Status)
+		require.False(t, payment.CreatedAt.After(createdBefore))
+	}
+}
+
+func TestStore_WithdrawTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	amount := account.Balance
+
+	result := withdraw(t, account, amount)
+	require.Equal(t, PaymentKindWithdrawal, result.Payment.Kind)
+	require.Equal(t, PaymentStatusPending, result.Payment.Status)
+	require.Equal(t, amount, result.Payment.Amount)
+	require.Empty(t, result.Payment.ExternalRef)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Payment.TransferID.Int64)
+	require.Equal(t, ClearingAccountOwner, result.Transfer.ToAccount.Owner)
+	require.Zero(t, result.Transfer.FromAccount.Balance)
+
+	_, err := store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID:   account.ID,
+		Amount:      1,
+		Rail:        "test",
+		Destination: util.RandomString(10),
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	completed, err := store.CompleteWithdrawalTx(context.Background(), CompleteWithdrawalTxParams{
+		PaymentID:   result.Payment.ID,
+		ExternalRef: util.RandomString(12),
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentStatusCompleted, completed.Payment.Status)
+
+	_, err = store.FailWithdrawalTx(context.Background(), FailWithdrawalTxParams{
+		PaymentID: result.Payment.ID,
+		LastError: "declined",
+	})
+	require.ErrorIs(t, err, ErrPaymentNotPending)
+
+	events := listTargetAuditEvents(t, AuditTargetPayment, auditID(result.Payment.ID))
+	require.Len(t, events, 2)
+	require.Equal(t, AuditActionWithdrawalCompleted, events[0].Action)
+	require.Equal(t, AuditActorSystem, events[0].Actor)
+	require.Equal(t, AuditActionWithdrawalRequested, events[1].Action)
+}
+
+func TestStore_WithdrawTxLimits(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := addRandomAccountBalance(t, createRandomAccount(t), 1000)
+
+	_, err := testQueries.UpsertAccountTransferLimit(context.Background(), UpsertAccountTransferLimitParams{
+		AccountID:   nullInt64(account.ID),
+		DailyAmount: nullInt64(100),
+	})
+	require.NoError(t, err)
+
+	// withdrawals count toward the limits like transfers
+	withdraw(t, account, 60)
+
+	_, err = store.WithdrawTx(context.Background(), WithdrawTxParams{
+		AccountID:   account.ID,
+		Amount:      50,
+		Rail:        "test",
+		Destination: util.RandomString(10),
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+}
+
+func TestStore_WithdrawTxRisk(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+
+	for _, decision := range []RiskDecision{RiskDecisionDeny, RiskDecisionReview} {
+		_, err := store.WithdrawTx(context.Background(), WithdrawTxParams{
+			AccountID:     account.ID,
+			Amount:        1,
+			Rail:          "test",
+			Destination:   util.RandomString(10),
+			RiskEvaluator: stubRiskEvaluator{decision: decision},
+		})
+		require.ErrorIs(t, err, ErrTransferDenied)
+	}
+
+	updatedAccount, err := testQueries.GetAccount(context.Background(), account.ID)
+	require.NoError(t, err)
+	require.Equal(t, account.Balance, updatedAccount.Balance)
+}
+
+func TestStore_FailWithdrawalTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account := createRandomAccount(t)
+	result := withdraw(t, account, account.Balance)
+
+	failed, err := store.FailWithdrawalTx(context.Background(), FailWithdrawalTxParams{
+		PaymentID: result.Payment.ID,
+		LastError: "declined",
+	})
+	require.NoError(t, err)
+	require.Equal(t, PaymentStatusFailed, failed.Payment.Status)
+	require.Equal(t, "declined", failed.Payment.LastError)
+	require.Equal(t, failed.Refund.Transfer.ID, failed.Payment.RefundTransferID.Int64)
+
+	// the debited money is given back
+	require.Equal(t, account.Balance, failed.Refund.ToAccount.Balance)
+
+	_, err = store.CompleteWithdrawalTx(context.Background(), CompleteWithdrawalTxParams{
+		PaymentID:   result.Payment.ID,
+		ExternalRef: util.RandomString(12),
+	})
+	require.ErrorIs(t, err, ErrPaymentNotPending)
+}
@@ -0,0 +1,75 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_ReconcileLedgerTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	// an account funded through a deposit is backed by entries
+	user := createRandomUser(t)
+	consistentAccount, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+		Owner:    user.Username,
+		Balance:  0,
+		Currency: util.RandomCurrency(),
+		Type:     AccountTypeChecking,
+	})
+	require.NoError(t, err)
+
+	deposit, err := store.DepositTx(context.Background(), DepositTxParams{
+		PaymentID:   createPendingDeposit(t, consistentAccount, util.RandomMoney()).ID,
+		ExternalRef: util.RandomString(12),
+	})
+	require.NoError(t, err)
+
+	// a random account has a balance but no entries,
+	// and a random transfer has no entries either
+	inconsistentAccount := createRandomAccount(t)
+	inconsistentTransfer := createRandomTransfer(t, inconsistentAccount, consistentAccount)
+
+	result, err := store.ReconcileLedgerTx(context.Background())
+	require.NoError(t, err)
+	require.True(t, result.HasDiscrepancies())
+
+	run := result.Run
+	require.NotZero(t, run.ID)
+	require.NotZero(t, run.AccountsChecked)
+	require.NotZero(t, run.TransfersChecked)
+	require.GreaterOrEqual(t, run.AccountDiscrepancies, int64(len(result.Details.Accounts)))
+	require.GreaterOrEqual(t, run.TransferDiscrepancies, int64(len(result.Details.Transfers)))
+	require.False(t, run.FinishedAt.Before(run.StartedAt))
+
+	var details ReconciliationDetails
+	err = json.Unmarshal(run.Details, &details)
+	require.NoError(t, err)
+	require.Equal(t, result.Details, details)
+
+	accounts, err := testQueries.ListAccountBalanceMismatches(context.Background())
+	require.NoError(t, err)
+
+	accountIDs := make(map[int64]ListAccountBalanceMismatchesRow)
+	for _, account := range accounts {
+		accountIDs[account.AccountID] = account
+	}
+	require.NotContains(t, accountIDs, consistentAccount.ID)
+	require.Contains(t, accountIDs, inconsistentAccount.ID)
+	require.Equal(t, inconsistentAccount.Balance, accountIDs[inconsistentAccount.ID].Balance)
+	require.Zero(t, accountIDs[inconsistentAccount.ID].EntriesSum)
+
+	transfers, err := testQueries.ListTransferEntryMismatches(context.Background())
+	require.NoError(t, err)
+
+	transferIDs := make(map[int64]ListTransferEntryMismatchesRow)
+	for _, transfer := range transfers {
+		transferIDs[transfer.TransferID] = transfer
+	}
+	require.NotContains(t, transferIDs, deposit.Transfer.Transfer.ID)
+	require.Contains(t, transferIDs, inconsistentTransfer.ID)
+	require.Zero(t, transferIDs[inconsistentTransfer.ID].EntryCount)
+}
@@ -0,0 +1,40 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+type RiskDecision string
+
+const (
+	RiskDecisionAllow  RiskDecision = "allow"
+	RiskDecisionReview RiskDecision = "review"
+	RiskDecisionDeny   RiskDecision = "deny"
+)
+
+// ErrTransferDenied is returned when the risk screening denies a transfer
+var ErrTransferDenied = errors.New("transfer denied by risk screening")
+
+// RiskInput describes a transfer to screen and the client requesting it
+type RiskInput struct {
+	FromAccount Account   `json:"from_account"`
+	ToAccountID int64     `json:"to_account_id"`
+	Amount      int64     `json:"amount"`
+	ClientIP    string    `json:"client_ip"`
+	UserAgent   string    `json:"user_agent"`
+	Now         time.Time `json:"now"`
+}
+
+type RiskAssessment struct {
+	Decision RiskDecision `json:"decision"`
+	// Reasons explain why the transfer was flagged, empty when it is allowed
+	Reasons []string `json:"reasons"`
+}
+
+// RiskEvaluator screens a transfer inside its transaction, after the accounts are locked,
+// so the queries see the same state the transfer is committed against
+type RiskEvaluator interface {
+	Evaluate(ctx context.Context, q Querier, input RiskInput) (RiskAssessment, error)
+}
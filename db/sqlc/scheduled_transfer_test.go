@@ -0,0 +1,238 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func createRandomScheduledTransfer(t *testing.T, fromAccount, toAccount Account, nextRunAt time.Time) ScheduledTransfer {
+	arg := CreateScheduledTransferParams{
+		Owner:         fromAccount.Owner,
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+		Recurrence:    "@every 24h",
+		StartAt:       nextRunAt,
+		NextRunAt:     nextRunAt,
+	}
+
+	schedule, err := testQueries.CreateScheduledTransfer(context.Background(), arg)
+	require.NoError(t, err)
+	require.NotEmpty(t, schedule)
+
+	require.Equal(t, arg.Owner, schedule.Owner)
+	require.Equal(t, arg.FromAccountID, schedule.FromAccountID)
+	require.Equal(t, arg.ToAccountID, schedule.ToAccountID)
+	require.Equal(t, arg.Amount, schedule.Amount)
+	require.Equal(t, arg.Recurrence, schedule.Recurrence)
+	require.Equal(t, ScheduledTransferStatusActive, schedule.Status)
+	require.False(t, schedule.EndAt.Valid)
+	require.Zero(t, schedule.FailureCount)
+	require.WithinDuration(t, arg.NextRunAt, schedule.NextRunAt, time.Second)
+
+	require.NotZero(t, schedule.ID)
+	require.NotZero(t, schedule.CreatedAt)
+
+	return schedule
+}
+
+func TestQueries_CreateScheduledTransfer(t *testing.T) {
+	createRandomScheduledTransfer(t, createRandomAccount(t), createRandomAccount(t), time.Now())
+}
+
+func TestQueries_UpdateScheduledTransfer(t *testing.T) {
+	schedule1 := createRandomScheduledTransfer(t, createRandomAccount(t), createRandomAccount(t), time.Now())
+
+	schedule2, err := testQueries.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:     schedule1.ID,
+		Status: sql.NullString{String: ScheduledTransferStatusPaused, Valid: true},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, ScheduledTransferStatusPaused, schedule2.Status)
+	require.Equal(t, schedule1.Amount, schedule2.Amount)
+	require.Equal(t, schedule1.Recurrence, schedule2.Recurrence)
+	require.WithinDuration(t, schedule1.NextRunAt, schedule2.NextRunAt, time.Second)
+}
+
+func TestStore_ExecuteScheduledTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := addRandomAccountBalance(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+	now := time.Now()
+	schedule := createRandomScheduledTransfer(t, account1, account2, now.Add(-time.Minute))
+
+	result, err := store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{
+		ID:          schedule.ID,
+		Now:         now,
+		MaxFailures: 3,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, ExecutionStatusSucceeded, result.Execution.Status)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Execution.TransferID.Int64)
+	require.Equal(t, account1.Balance-schedule.Amount, result.Transfer.FromAccount.Balance)
+	require.Equal(t, ScheduledTransferStatusActive, result.ScheduledTransfer.Status)
+	require.True(t, result.ScheduledTransfer.NextRunAt.After(now))
+
+	events := listTargetAuditEvents(t, AuditTargetTransfer, auditID(result.Transfer.Transfer.ID))
+	require.Len(t, events, 1)
+	require.Equal(t, AuditActionScheduledTransferExecuted, events[0].Action)
+	require.Equal(t, AuditActorSystem, events[0].Actor)
+
+	// running again before the next run is a no-op
+	_, err = store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{
+		ID:          schedule.ID,
+		Now:         now,
+		MaxFailures: 3,
+	})
+	require.ErrorIs(t, err, ErrScheduleNotDue)
+}
+
+func TestStore_ExecuteScheduledTransferTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := addRandomAccountBalance(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+	now := time.Now()
+	schedule := createRandomScheduledTransfer(t, account1, account2, now.Add(-time.Minute))
+
+	// duplicate task runs execute the same due schedule at once
+	n := 5
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{
+				ID:          schedule.ID,
+				Now:         now,
+				MaxFailures: 3,
+			})
+
+			errs <- err
+		}()
+	}
+
+	executed := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			executed++
+			continue
+		}
+		require.ErrorIs(t, err, ErrScheduleNotDue)
+	}
+	require.Equal(t, 1, executed)
+
+	executions, err := testQueries.ListScheduledTransferExecutions(context.Background(), ListScheduledTransferExecutionsParams{
+		ScheduledTransferID: schedule.ID,
+		Limit:               10,
+		Offset:              0,
+	})
+	require.NoError(t, err)
+	require.Len(t, executions, 1)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance-schedule.Amount, updatedAccount1.Balance)
+}
+
+func TestStore_ExecuteScheduledTransferTxPausesAfterFailures(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+	schedule := createRandomScheduledTransfer(t, account1, account2, time.Now().Add(-time.Minute))
+
+	_, err := testQueries.UpdateScheduledTransfer(context.Background(), UpdateScheduledTransferParams{
+		ID:     schedule.ID,
+		Amount: sql.NullInt64{Int64: account1.Balance + 1, Valid: true},
+	})
+	require.NoError(t, err)
+
+	maxFailures := int32(2)
+	for i := int32(1); i <= maxFailures; i++ {
+		// pretend the next run is due
+		now := time.Now().Add(time.Duration(i) * 48 * time.Hour)
+
+		result, err := store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{
+			ID:          schedule.ID,
+			Now:         now,
+			MaxFailures: maxFailures,
+		})
+		require.NoError(t, err)
+
+		require.Equal(t, ExecutionStatusFailed, result.Execution.Status)
+		require.False(t, result.Execution.TransferID.Valid)
+		require.Equal(t, ErrInsufficientFunds.Error(), result.Execution.Error)
+		require.Equal(t, i, result.ScheduledTransfer.FailureCount)
+	}
+
+	schedule, err = testQueries.GetScheduledTransfer(context.Background(), schedule.ID)
+	require.NoError(t, err)
+	require.Equal(t, ScheduledTransferStatusPaused, schedule.Status)
+
+	executions, err := testQueries.ListScheduledTransferExecutions(context.Background(), ListScheduledTransferExecutionsParams{
+		ScheduledTransferID: schedule.ID,
+		Limit:               10,
+		Offset:              0,
+	})
+	require.NoError(t, err)
+	require.Len(t, executions, int(maxFailures))
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+}
+
+func TestStore_ExecuteScheduledTransferTxScreening(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := addRandomAccountBalance(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+	now := time.Now()
+	schedule := createRandomScheduledTransfer(t, account1, account2, now.Add(-time.Minute))
+
+	result, err := store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{
+		ID:            schedule.ID,
+		Now:           now,
+		MaxFailures:   3,
+		RiskEvaluator: stubRiskEvaluator{decision: RiskDecisionReview},
+	})
+	require.NoError(t, err)
+
+	// the held transfer waits for a reviewer with its amount held on the source account
+	require.Equal(t, ExecutionStatusHeld, result.Execution.Status)
+	require.False(t, result.Execution.TransferID.Valid)
+	require.Zero(t, result.Transfer.Transfer.ID)
+	require.NotZero(t, result.Transfer.Review.ID)
+	require.Equal(t, schedule.Amount, result.Transfer.Review.Amount)
+	require.Equal(t, account1.Balance, result.Transfer.FromAccount.Balance)
+	require.Equal(t, account1.HeldBalance+schedule.Amount, result.Transfer.FromAccount.HeldBalance)
+	require.True(t, result.ScheduledTransfer.NextRunAt.After(now))
+
+	events := listTargetAuditEvents(t, AuditTargetTransferReview, auditID(result.Transfer.Review.ID))
+	require.Len(t, events, 1)
+	require.Equal(t, AuditActionTransferHeld, events[0].Action)
+	require.Equal(t, AuditActorSystem, events[0].Actor)
+
+	// pretend the next run is due, a denied transfer fails the execution without pausing the schedule
+	now = now.Add(48 * time.Hour)
+	result, err = store.ExecuteScheduledTransferTx(context.Background(), ExecuteScheduledTransferTxParams{
+		ID:            schedule.ID,
+		Now:           now,
+		MaxFailures:   1,
+		RiskEvaluator: stubRiskEvaluator{decision: RiskDecisionDeny},
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, ExecutionStatusFailed, result.Execution.Status)
+	require.Contains(t, result.Execution.Error, ErrTransferDenied.Error())
+	require.Equal(t, ScheduledTransferStatusActive, result.ScheduledTransfer.Status)
+	require.Zero(t, result.ScheduledTransfer.FailureCount)
+}
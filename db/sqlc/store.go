@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"time"
 )
 
 // Store provides all functions to execute db queries and transaction
@@ -11,6 +12,33 @@ type Store interface {
 	Querier
 	TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error)
 	CreateUserTx(ctx context.Context, arg CreateUserTxParams) (CreateUserTxResult, error)
+	UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (UpdateUserTxResult, error)
+	CreateSessionTx(ctx context.Context, arg CreateSessionTxParams) (CreateSessionTxResult, error)
+	BlockSessionTx(ctx context.Context, arg BlockSessionTxParams) (BlockSessionTxResult, error)
+	CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (CreateAccountTxResult, error)
+	ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResult, error)
+	ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error)
+	AuthorizeTransferTx(ctx context.Context, arg AuthorizeTransferTxParams) (AuthorizeTransferTxResult, error)
+	CaptureTransferTx(ctx context.Context, arg CaptureTransferTxParams) (CaptureTransferTxResult, error)
+	VoidTransferTx(ctx context.Context, arg VoidTransferTxParams) (VoidTransferTxResult, error)
+	ExpireHoldTx(ctx context.Context, arg ExpireHoldTxParams) (VoidTransferTxResult, error)
+	DepositTx(ctx context.Context, arg DepositTxParams) (DepositTxResult, error)
+	FailDepositTx(ctx context.Context, arg FailDepositTxParams) (FailDepositTxResult, error)
+	WithdrawTx(ctx context.Context, arg WithdrawTxParams) (WithdrawTxResult, error)
+	CompleteWithdrawalTx(ctx context.Context, arg CompleteWithdrawalTxParams) (CompleteWithdrawalTxResult, error)
+	FailWithdrawalTx(ctx context.Context, arg FailWithdrawalTxParams) (FailWithdrawalTxResult, error)
+	ReconcileLedgerTx(ctx context.Context) (ReconcileLedgerTxResult, error)
+	VerifyEntryChainTx(ctx context.Context) (VerifyEntryChainTxResult, error)
+	PostJournalTx(ctx context.Context, arg PostJournalTxParams) (PostJournalTxResult, error)
+	QuoteFee(ctx context.Context, currency string, amount int64) (int64, error)
+	AccrueInterestTx(ctx context.Context, arg AccrueInterestTxParams) (InterestAccrual, error)
+	PostInterestTx(ctx context.Context, arg PostInterestTxParams) (PostInterestTxResult, error)
+	GetTransferLimits(ctx context.Context, account Account, now time.Time) (TransferLimitsResult, error)
+	ReviewTransferTx(ctx context.Context, arg ReviewTransferTxParams) (ReviewTransferTxResult, error)
+	SetTransferLimitTx(ctx context.Context, arg SetTransferLimitTxParams) (SetTransferLimitTxResult, error)
+	ChangeAccountStatusTx(ctx context.Context, arg ChangeAccountStatusTxParams) (ChangeAccountStatusTxResult, error)
+	RelayOutboxTx(ctx context.Context, arg RelayOutboxTxParams) (RelayOutboxTxResult, error)
+	ReplayWebhookDeliveryTx(ctx context.Context, arg ReplayWebhookDeliveryTxParams) (ReplayWebhookDeliveryTxResult, error)
 }
 
 // SQLStore provides all functions to execute SQL queries and transaction
@@ -22,7 +50,7 @@ type SQLStore struct {
 func NewStore(db *sql.DB) Store {
 	return &SQLStore{
 		db:      db,
-		Queries: New(db),
+		Queries: New(NewTracedDBTX(db)),
 	}
 }
 
@@ -32,7 +60,7 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 		return err
 	}
 
-	q := New(tx)
+	q := New(NewTracedDBTX(tx))
 	err = fn(q)
 	if err != nil {
 		if rbErr := tx.Rollback(); rbErr != nil {
@@ -42,3 +70,17 @@ func (store *SQLStore) execTx(ctx context.Context, fn func(*Queries) error) erro
 	}
 	return tx.Commit()
 }
+
+// execSnapshotTx executes a read-only function on a consistent snapshot of the database
+func (store *SQLStore) execSnapshotTx(ctx context.Context, fn func(*Queries) error) error {
+	tx, err := store.db.BeginTx(ctx, &sql.TxOptions{
+		Isolation: sql.LevelRepeatableRead,
+		ReadOnly:  true,
+	})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	return fn(New(NewTracedDBTX(tx)))
+}
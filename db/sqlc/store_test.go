@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"github.com/stretchr/testify/require"
 	"testing"
+	"time"
 )
 
 func TestStore_TransferTx(t *testing.T) {
@@ -12,6 +13,7 @@ func TestStore_TransferTx(t *testing.T) {
 
 	account1 := createRandomAccount(t)
 	account2 := createRandomAccount(t)
+	account1 = addRandomAccountBalance(t, account1, 1000)
 	fmt.Println("account1: ", account1)
 	fmt.Println("account2: ", account2)
 
@@ -64,6 +66,7 @@ func TestStore_TransferTx(t *testing.T) {
 		require.NotEmpty(t, fromEntry)
 		require.Equal(t, account1.ID, fromEntry.AccountID)
 		require.Equal(t, -amount, fromEntry.Amount)
+		require.Equal(t, transfer.ID, fromEntry.TransferID.Int64)
 		require.NotZero(t, fromEntry.ID)
 		require.NotZero(t, fromEntry.CreatedAt)
 
@@ -74,6 +77,7 @@ func TestStore_TransferTx(t *testing.T) {
 		require.NotEmpty(t, toEntry)
 		require.Equal(t, account2.ID, toEntry.AccountID)
 		require.Equal(t, amount, toEntry.Amount)
+		require.Equal(t, transfer.ID, toEntry.TransferID.Int64)
 		require.NotZero(t, toEntry.ID)
 		require.NotZero(t, toEntry.CreatedAt)
 
@@ -120,6 +124,8 @@ func TestStore_TransferTxLock(t *testing.T) {
 
 	account1 := createRandomAccount(t)
 	account2 := createRandomAccount(t)
+	account1 = addRandomAccountBalance(t, account1, 1000)
+	account2 = addRandomAccountBalance(t, account2, 1000)
 
 	fmt.Println(">> before:", account1.Balance, account2.Balance)
 
@@ -164,3 +170,160 @@ func TestStore_TransferTxLock(t *testing.T) {
 	require.Equal(t, account1.Balance, upatedAccount1.Balance)
 	require.Equal(t, account2.Balance, upatedAccount2.Balance)
 }
+
+func TestStore_TransferTxInsufficientFunds(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := createRandomAccount(t)
+	account2 := createRandomAccount(t)
+
+	_, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        account1.Balance + 1,
+	})
+	require.ErrorIs(t, err, ErrInsufficientFunds)
+
+	// the whole transaction is rolled back
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+
+	updatedAccount2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+}
+
+func TestStore_ReverseTransferTx(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := addRandomAccountBalance(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	// partial refund
+	result, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     30,
+	})
+	require.NoError(t, err)
+	require.Equal(t, original.Transfer.ID, result.OriginalTransfer.ID)
+	require.Equal(t, int64(70), result.RemainingAmount)
+
+	reversal := result.Reversal
+	require.Equal(t, account2.ID, reversal.Transfer.FromAccountID)
+	require.Equal(t, account1.ID, reversal.Transfer.ToAccountID)
+	require.Equal(t, int64(30), reversal.Transfer.Amount)
+	require.True(t, reversal.Transfer.ReversalOfID.Valid)
+	require.Equal(t, original.Transfer.ID, reversal.Transfer.ReversalOfID.Int64)
+	require.Equal(t, int64(-30), reversal.FromEntry.Amount)
+	require.Equal(t, int64(30), reversal.ToEntry.Amount)
+	require.Equal(t, original.ToAccount.Balance-30, reversal.FromAccount.Balance)
+	require.Equal(t, original.FromAccount.Balance+30, reversal.ToAccount.Balance)
+
+	// refunding more than what is left fails
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+		Amount:     71,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsAmount)
+
+	// a zero amount refunds the rest
+	result, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(70), result.Reversal.Transfer.Amount)
+	require.Zero(t, result.RemainingAmount)
+
+	// a fully reversed transfer cannot be reversed again
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: original.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrReversalExceedsAmount)
+
+	// and neither can a reversal
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: result.Reversal.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrTransferIsReversal)
+
+	updatedAccount1, err := testQueries.GetAccount(context.Background(), account1.ID)
+	require.NoError(t, err)
+	require.Equal(t, account1.Balance, updatedAccount1.Balance)
+
+	updatedAccount2, err := testQueries.GetAccount(context.Background(), account2.ID)
+	require.NoError(t, err)
+	require.Equal(t, account2.Balance, updatedAccount2.Balance)
+}
+
+func TestStore_ReverseTransferTxRejected(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := addRandomAccountBalance(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+
+	// withdrawals move the money to the clearing account
+	withdrawal := withdraw(t, account1, 10)
+	_, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: withdrawal.Transfer.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrSystemTransfer)
+
+	// captured holds are settled by their transfer
+	hold := authorizeRandomHold(t, store, account1, account2, 10, time.Now().Add(time.Hour))
+	capture, err := store.CaptureTransferTx(context.Background(), CaptureTransferTxParams{HoldID: hold.ID})
+	require.NoError(t, err)
+
+	_, err = store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+		TransferID: capture.Transfer.Transfer.ID,
+	})
+	require.ErrorIs(t, err, ErrSettlementTransfer)
+}
+
+func TestStore_ReverseTransferTxConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+
+	account1 := addRandomAccountBalance(t, createRandomAccount(t), 100)
+	account2 := createRandomAccount(t)
+
+	original, err := store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: account1.ID,
+		ToAccountID:   account2.ID,
+		Amount:        100,
+	})
+	require.NoError(t, err)
+
+	n := 5
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.ReverseTransferTx(context.Background(), ReverseTransferTxParams{
+				TransferID: original.Transfer.ID,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrReversalExceedsAmount)
+	}
+	require.Equal(t, 1, succeeded)
+
+	reversedAmount, err := testQueries.GetReversedAmount(context.Background(), original.Transfer.ID)
+	require.NoError(t, err)
+	require.Equal(t, original.Transfer.Amount, reversedAmount)
+}
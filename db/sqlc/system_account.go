@@ -0,0 +1,13 @@
+package db
+
+// systemAccountOwners are the users owning the accounts the bank itself moves money through
+var systemAccountOwners = map[string]bool{
+	ClearingAccountOwner:        true,
+	RevenueAccountOwner:         true,
+	InterestExpenseAccountOwner: true,
+}
+
+// IsSystemAccountOwner tells if the user owns the system accounts of the bank
+func IsSystemAccountOwner(username string) bool {
+	return systemAccountOwners[username]
+}
@@ -7,26 +7,72 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"time"
 )
 
+const countTransfersBetween = `-- name: CountTransfersBetween :one
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = $1 AND to_account_id = $2 AND reversal_of_id IS NULL
+`
+
+type CountTransfersBetweenParams struct {
+	FromAccountID int64 `json:"from_account_id"`
+	ToAccountID   int64 `json:"to_account_id"`
+}
+
+func (q *Queries) CountTransfersBetween(ctx context.Context, arg CountTransfersBetweenParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTransfersBetween, arg.FromAccountID, arg.ToAccountID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countTransfersSince = `-- name: CountTransfersSince :one
+SELECT COUNT(*) FROM transfers
+WHERE from_account_id = $1 AND created_at >= $2 AND reversal_of_id IS NULL
+`
+
+type CountTransfersSinceParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	Since         time.Time `json:"since"`
+}
+
+func (q *Queries) CountTransfersSince(ctx context.Context, arg CountTransfersSinceParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countTransfersSince, arg.FromAccountID, arg.Since)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTransfer = `-- name: CreateTransfer :one
 INSERT INTO transfers (
     from_account_id,
     to_account_id,
-    amount
+    amount,
+    reversal_of_id,
+    fee
 ) VALUES (
-    $1, $2, $3
-) RETURNING id, from_account_id, to_account_id, amount, created_at
+    $1, $2, $3, $4, $5
+) RETURNING id, from_account_id, to_account_id, amount, created_at, reversal_of_id, fee, fee_journal_id
 `
 
 type CreateTransferParams struct {
-	FromAccountID int64 `json:"from_account_id"`
-	ToAccountID   int64 `json:"to_account_id"`
-	Amount        int64 `json:"amount"`
+	FromAccountID int64         `json:"from_account_id"`
+	ToAccountID   int64         `json:"to_account_id"`
+	Amount        int64         `json:"amount"`
+	ReversalOfID  sql.NullInt64 `json:"reversal_of_id"`
+	Fee           int64         `json:"fee"`
 }
 
 func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams) (Transfer, error) {
-	row := q.db.QueryRowContext(ctx, createTransfer, arg.FromAccountID, arg.ToAccountID, arg.Amount)
+	row := q.db.QueryRowContext(ctx, createTransfer,
+		arg.FromAccountID,
+		arg.ToAccountID,
+		arg.Amount,
+		arg.ReversalOfID,
+		arg.Fee,
+	)
 	var i Transfer
 	err := row.Scan(
 		&i.ID,
@@ -34,12 +80,28 @@ func (q *Queries) CreateTransfer(ctx context.Context, arg CreateTransferParams)
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ReversalOfID,
+		&i.Fee,
+		&i.FeeJournalID,
 	)
 	return i, err
 }
 
+const getReversedAmount = `-- name: GetReversedAmount :one
+SELECT COALESCE(SUM(amount), 0)::bigint AS reversed_amount
+FROM transfers
+WHERE reversal_of_id = $1::bigint
+`
+
+func (q *Queries) GetReversedAmount(ctx context.Context, transferID int64) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getReversedAmount, transferID)
+	var reversed_amount int64
+	err := row.Scan(&reversed_amount)
+	return reversed_amount, err
+}
+
 const getTransfer = `-- name: GetTransfer :one
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of_id, fee, fee_journal_id FROM transfers
 WHERE id = $1 LIMIT 1
 `
 
@@ -52,12 +114,54 @@ func (q *Queries) GetTransfer(ctx context.Context, id int64) (Transfer, error) {
 		&i.ToAccountID,
 		&i.Amount,
 		&i.CreatedAt,
+		&i.ReversalOfID,
+		&i.Fee,
+		&i.FeeJournalID,
+	)
+	return i, err
+}
+
+const getTransferForUpdate = `-- name: GetTransferForUpdate :one
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of_id, fee, fee_journal_id FROM transfers
+WHERE id = $1 LIMIT 1
+FOR NO KEY UPDATE
+`
+
+func (q *Queries) GetTransferForUpdate(ctx context.Context, id int64) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, getTransferForUpdate, id)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ReversalOfID,
+		&i.Fee,
+		&i.FeeJournalID,
 	)
 	return i, err
 }
 
+const isSettlementTransfer = `-- name: IsSettlementTransfer :one
+SELECT (COUNT(*) > 0)::boolean AS settlement FROM (
+  SELECT holds.id FROM holds WHERE holds.transfer_id = $1::bigint
+  UNION ALL
+  SELECT payments.id FROM payments WHERE payments.transfer_id = $1::bigint OR payments.refund_transfer_id = $1::bigint
+  UNION ALL
+  SELECT transfer_reviews.id FROM transfer_reviews WHERE transfer_reviews.transfer_id = $1::bigint
+) AS settlements
+`
+
+func (q *Queries) IsSettlementTransfer(ctx context.Context, transferID int64) (bool, error) {
+	row := q.db.QueryRowContext(ctx, isSettlementTransfer, transferID)
+	var settlement bool
+	err := row.Scan(&settlement)
+	return settlement, err
+}
+
 const listTransfers = `-- name: ListTransfers :many
-SELECT id, from_account_id, to_account_id, amount, created_at FROM transfers
+SELECT id, from_account_id, to_account_id, amount, created_at, reversal_of_id, fee, fee_journal_id FROM transfers
 WHERE
     from_account_id = $1 OR
     to_account_id = $2
@@ -93,6 +197,9 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 			&i.ToAccountID,
 			&i.Amount,
 			&i.CreatedAt,
+			&i.ReversalOfID,
+			&i.Fee,
+			&i.FeeJournalID,
 		); err != nil {
 			return nil, err
 		}
@@ -106,3 +213,31 @@ func (q *Queries) ListTransfers(ctx context.Context, arg ListTransfersParams) ([
 	}
 	return items, nil
 }
+
+const updateTransferFeeJournal = `-- name: UpdateTransferFeeJournal :one
+UPDATE transfers
+SET fee_journal_id = $1
+WHERE id = $2
+RETURNING id, from_account_id, to_account_id, amount, created_at, reversal_of_id, fee, fee_journal_id
+`
+
+type UpdateTransferFeeJournalParams struct {
+	FeeJournalID sql.NullInt64 `json:"fee_journal_id"`
+	ID           int64         `json:"id"`
+}
+
+func (q *Queries) UpdateTransferFeeJournal(ctx context.Context, arg UpdateTransferFeeJournalParams) (Transfer, error) {
+	row := q.db.QueryRowContext(ctx, updateTransferFeeJournal, arg.FeeJournalID, arg.ID)
+	var i Transfer
+	err := row.Scan(
+		&i.ID,
+		&i.FromAccountID,
+		&i.ToAccountID,
+		&i.Amount,
+		&i.CreatedAt,
+		&i.ReversalOfID,
+		&i.Fee,
+		&i.FeeJournalID,
+	)
+	return i, err
+}
@@ -0,0 +1,194 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+const (
+	TransferLimitScopeUser    = "user"
+	TransferLimitScopeAccount = "account"
+)
+
+const (
+	TransferLimitMaxSingleAmount = "max_single_amount"
+	TransferLimitDailyAmount     = "daily_amount"
+	TransferLimitMonthlyAmount   = "monthly_amount"
+	TransferLimitHourlyCount     = "hourly_count"
+)
+
+// ErrTransferLimitExceeded is wrapped by the TransferLimitError returned when a transfer exceeds a limit
+var ErrTransferLimitExceeded = errors.New("transfer limit exceeded")
+
+// TransferLimitError tells which limit blocked a transfer
+type TransferLimitError struct {
+	Scope string
+	// Subject is the username for user limits and the account ID for account limits
+	Subject string
+	Usage   TransferLimitUsage
+}
+
+func (e *TransferLimitError) Error() string {
+	return fmt.Sprintf("%s: %s limit of %s %s is %d, %d remaining",
+		ErrTransferLimitExceeded, e.Usage.Name, e.Scope, e.Subject, e.Usage.Max, e.Usage.Remaining)
+}
+
+func (e *TransferLimitError) Unwrap() error {
+	return ErrTransferLimitExceeded
+}
+
+// TransferLimitUsage is how much of a limit is used in its current window
+type TransferLimitUsage struct {
+	Name      string `json:"name"`
+	Max       int64  `json:"max"`
+	Used      int64  `json:"used"`
+	Remaining int64  `json:"remaining"`
+}
+
+type TransferLimitsResult struct {
+	// User holds the limits shared by the accounts of the owner in the currency of the account, empty when there are none
+	User []TransferLimitUsage `json:"user"`
+	// Account holds the limits of the account, empty when there are none
+	Account []TransferLimitUsage `json:"account"`
+}
+
+// GetTransferLimits returns the usage of the limits applying to transfers from the account
+func (store *SQLStore) GetTransferLimits(ctx context.Context, account Account, now time.Time) (TransferLimitsResult, error) {
+	return getTransferLimits(ctx, store.Queries, account, now)
+}
+
+func getTransferLimits(ctx context.Context, q *Queries, account Account, now time.Time) (TransferLimitsResult, error) {
+	var result TransferLimitsResult
+
+	now = now.UTC()
+	hourStart := now.Add(-time.Hour)
+	dayStart := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	userLimit, err := q.GetUserTransferLimit(ctx, account.Owner)
+	if err != nil && err != sql.ErrNoRows {
+		return result, err
+	}
+	if err == nil {
+		// amounts in different currencies cannot be added up, so the user amount limits
+		// apply to each currency separately while the hourly count covers all the accounts
+		usage, err := q.GetUserTransferUsage(ctx, GetUserTransferUsageParams{
+			Owner:      account.Owner,
+			Currency:   account.Currency,
+			HourStart:  hourStart,
+			DayStart:   dayStart,
+			MonthStart: monthStart,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		result.User = transferLimitUsages(userLimit, GetAccountTransferUsageRow(usage))
+	}
+
+	accountLimit, err := q.GetAccountTransferLimit(ctx, account.ID)
+	if err != nil && err != sql.ErrNoRows {
+		return result, err
+	}
+	if err == nil {
+		usage, err := q.GetAccountTransferUsage(ctx, GetAccountTransferUsageParams{
+			AccountID:  account.ID,
+			HourStart:  hourStart,
+			DayStart:   dayStart,
+			MonthStart: monthStart,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		result.Account = transferLimitUsages(accountLimit, usage)
+	}
+
+	return result, nil
+}
+
+// transferLimitUsages lists the configured limits with their usage, unset limits are unlimited
+func transferLimitUsages(limit TransferLimit, usage GetAccountTransferUsageRow) []TransferLimitUsage {
+	var usages []TransferLimitUsage
+
+	add := func(name string, max sql.NullInt64, used int64) {
+		if !max.Valid {
+			return
+		}
+
+		remaining := max.Int64 - used
+		if remaining < 0 {
+			remaining = 0
+		}
+
+		usages = append(usages, TransferLimitUsage{
+			Name:      name,
+			Max:       max.Int64,
+			Used:      used,
+			Remaining: remaining,
+		})
+	}
+
+	add(TransferLimitMaxSingleAmount, limit.MaxSingleAmount, 0)
+	add(TransferLimitDailyAmount, limit.DailyAmount, usage.DailyAmount)
+	add(TransferLimitMonthlyAmount, limit.MonthlyAmount, usage.MonthlyAmount)
+	add(TransferLimitHourlyCount, limit.HourlyCount, usage.HourlyCount)
+
+	return usages
+}
+
+// enforceTransferLimits returns a TransferLimitError when a transfer of the amount from the account
+// would exceed one of its limits. It locks the owner and the accounts of the transfer first, so the
+// usage cannot change until the transfer commits, and returns the source account read under the lock.
+func enforceTransferLimits(ctx context.Context, q *Queries, fromAccount Account, toAccountID int64, amount int64, now time.Time) (Account, error) {
+	// the owner is always locked before the accounts, which keeps the lock order of the accounts
+	_, err := q.GetUserForUpdate(ctx, fromAccount.Owner)
+	if err != nil {
+		return fromAccount, err
+	}
+
+	accounts, err := lockAccounts(ctx, q, fromAccount.ID, toAccountID)
+	if err != nil {
+		return fromAccount, err
+	}
+	fromAccount = accounts[fromAccount.ID]
+
+	limits, err := getTransferLimits(ctx, q, fromAccount, now)
+	if err != nil {
+		return fromAccount, err
+	}
+
+	err = checkTransferLimits(TransferLimitScopeUser, fromAccount.Owner, limits.User, amount)
+	if err != nil {
+		return fromAccount, err
+	}
+
+	err = checkTransferLimits(TransferLimitScopeAccount, strconv.FormatInt(fromAccount.ID, 10), limits.Account, amount)
+	return fromAccount, err
+}
+
+func checkTransferLimits(scope string, subject string, usages []TransferLimitUsage, amount int64) error {
+	for _, usage := range usages {
+		exceeded := false
+		switch usage.Name {
+		case TransferLimitMaxSingleAmount, TransferLimitDailyAmount, TransferLimitMonthlyAmount:
+			exceeded = amount > usage.Remaining
+		case TransferLimitHourlyCount:
+			exceeded = usage.Remaining < 1
+		}
+
+		if exceeded {
+			return &TransferLimitError{
+				Scope:   scope,
+				Subject: subject,
+				Usage:   usage,
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,311 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func nullInt64(value int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: value, Valid: true}
+}
+
+func TestStore_TransferTxAccountLimits(t *testing.T) {
+	store := NewStore(testDB)
+
+	currency := util.RandomCurrency()
+	fromAccount := addRandomAccountBalance(t, createRandomAccountWithCurrency(t, currency), 1000)
+	toAccount := createRandomAccountWithCurrency(t, currency)
+
+	_, err := testQueries.UpsertAccountTransferLimit(context.Background(), UpsertAccountTransferLimitParams{
+		AccountID:       nullInt64(fromAccount.ID),
+		MaxSingleAmount: nullInt64(100),
+		DailyAmount:     nullInt64(150),
+		HourlyCount:     nullInt64(5),
+	})
+	require.NoError(t, err)
+
+	transfer := func(amount int64) error {
+		_, err := store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: fromAccount.ID,
+			ToAccountID:   toAccount.ID,
+			Amount:        amount,
+		})
+		return err
+	}
+
+	err = transfer(101)
+	var limitErr *TransferLimitError
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, TransferLimitScopeAccount, limitErr.Scope)
+	require.Equal(t, TransferLimitMaxSingleAmount, limitErr.Usage.Name)
+
+	require.NoError(t, transfer(100))
+
+	err = transfer(60)
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, TransferLimitDailyAmount, limitErr.Usage.Name)
+	require.Equal(t, int64(50), limitErr.Usage.Remaining)
+
+	require.NoError(t, transfer(50))
+
+	limits, err := store.GetTransferLimits(context.Background(), fromAccount, time.Now())
+	require.NoError(t, err)
+	require.Empty(t, limits.User)
+	require.Len(t, limits.Account, 3)
+
+	usages := make(map[string]TransferLimitUsage)
+	for _, usage := range limits.Account {
+		usages[usage.Name] = usage
+	}
+	require.Equal(t, int64(150), usages[TransferLimitDailyAmount].Used)
+	require.Zero(t, usages[TransferLimitDailyAmount].Remaining)
+	require.Equal(t, int64(2), usages[TransferLimitHourlyCount].Used)
+	require.Equal(t, int64(3), usages[TransferLimitHourlyCount].Remaining)
+}
+
+func TestStore_TransferTxUserLimits(t *testing.T) {
+	store := NewStore(testDB)
+
+	// the user count limit covers all the accounts of the user together
+	user := createRandomUser(t)
+	accounts := make([]Account, 2)
+	for i, currency := range []string{util.USD, util.EUR} {
+		account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:    user.Username,
+			Balance:  1000,
+			Currency: currency,
+			Type:     AccountTypeChecking,
+		})
+		require.NoError(t, err)
+		accounts[i] = account
+	}
+
+	_, err := testQueries.UpsertUserTransferLimit(context.Background(), UpsertUserTransferLimitParams{
+		Username:    sql.NullString{String: user.Username, Valid: true},
+		HourlyCount: nullInt64(1),
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: accounts[0].ID,
+		ToAccountID:   createRandomAccountWithCurrency(t, util.USD).ID,
+		Amount:        10,
+	})
+	require.NoError(t, err)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: accounts[1].ID,
+		ToAccountID:   createRandomAccountWithCurrency(t, util.EUR).ID,
+		Amount:        10,
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	var limitErr *TransferLimitError
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, TransferLimitScopeUser, limitErr.Scope)
+	require.Equal(t, user.Username, limitErr.Subject)
+	require.Equal(t, TransferLimitHourlyCount, limitErr.Usage.Name)
+}
+
+func TestStore_TransferTxUserLimitsPerCurrency(t *testing.T) {
+	store := NewStore(testDB)
+
+	user := createRandomUser(t)
+	currencies := []string{util.USD, util.EUR}
+	accounts := make([]Account, len(currencies))
+	for i, currency := range currencies {
+		account, err := testQueries.CreateAccount(context.Background(), CreateAccountParams{
+			Owner:    user.Username,
+			Balance:  1000,
+			Currency: currency,
+			Type:     AccountTypeChecking,
+		})
+		require.NoError(t, err)
+		accounts[i] = account
+	}
+
+	_, err := testQueries.UpsertUserTransferLimit(context.Background(), UpsertUserTransferLimitParams{
+		Username:    sql.NullString{String: user.Username, Valid: true},
+		DailyAmount: nullInt64(100),
+	})
+	require.NoError(t, err)
+
+	// the amounts of each currency count toward their own limit
+	for i, currency := range currencies {
+		_, err = store.TransferTx(context.Background(), TransferTxParams{
+			FromAccountID: accounts[i].ID,
+			ToAccountID:   createRandomAccountWithCurrency(t, currency).ID,
+			Amount:        80,
+		})
+		require.NoError(t, err)
+	}
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: accounts[0].ID,
+		ToAccountID:   createRandomAccountWithCurrency(t, util.USD).ID,
+		Amount:        30,
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	limits, err := store.GetTransferLimits(context.Background(), accounts[1], time.Now())
+	require.NoError(t, err)
+	require.Len(t, limits.User, 1)
+	require.Equal(t, TransferLimitDailyAmount, limits.User[0].Name)
+	require.Equal(t, int64(80), limits.User[0].Used)
+}
+
+func TestStore_TransferTxLimitsConcurrent(t *testing.T) {
+	store := NewStore(testDB)
+
+	currency := util.RandomCurrency()
+	fromAccount := addRandomAccountBalance(t, createRandomAccountWithCurrency(t, currency), 1000)
+	toAccount := createRandomAccountWithCurrency(t, currency)
+
+	_, err := testQueries.UpsertAccountTransferLimit(context.Background(), UpsertAccountTransferLimitParams{
+		AccountID:   nullInt64(fromAccount.ID),
+		DailyAmount: nullInt64(50),
+	})
+	require.NoError(t, err)
+
+	// only 5 of the concurrent transfers fit in the daily limit
+	n := 10
+	errs := make(chan error)
+
+	for i := 0; i < n; i++ {
+		go func() {
+			_, err := store.TransferTx(context.Background(), TransferTxParams{
+				FromAccountID: fromAccount.ID,
+				ToAccountID:   toAccount.ID,
+				Amount:        10,
+			})
+			errs <- err
+		}()
+	}
+
+	succeeded := 0
+	for i := 0; i < n; i++ {
+		err := <-errs
+		if err == nil {
+			succeeded++
+			continue
+		}
+		require.ErrorIs(t, err, ErrTransferLimitExceeded)
+	}
+	require.Equal(t, 5, succeeded)
+}
+
+func TestStore_SetTransferLimitTx(t *testing.T) {
+	store := NewStore(testDB)
+	account := createRandomAccount(t)
+	banker := createRandomUser(t)
+
+	result, err := store.SetTransferLimitTx(context.Background(), SetTransferLimitTxParams{
+		AccountID:   account.ID,
+		DailyAmount: nullInt64(100),
+		Audit:       AuditInfo{Actor: banker.Username},
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.ID, result.Limit.AccountID.Int64)
+	require.False(t, result.Limit.Username.Valid)
+	require.Equal(t, nullInt64(100), result.Limit.DailyAmount)
+	require.False(t, result.Limit.MaxSingleAmount.Valid)
+
+	// the limits are replaced, the ones left unset become unlimited
+	updated, err := store.SetTransferLimitTx(context.Background(), SetTransferLimitTxParams{
+		AccountID:   account.ID,
+		HourlyCount: nullInt64(3),
+		Audit:       AuditInfo{Actor: banker.Username},
+	})
+	require.NoError(t, err)
+	require.Equal(t, result.Limit.ID, updated.Limit.ID)
+	require.False(t, updated.Limit.DailyAmount.Valid)
+	require.Equal(t, nullInt64(3), updated.Limit.HourlyCount)
+
+	events := listTargetAuditEvents(t, AuditTargetTransferLimit, auditID(result.Limit.ID))
+	require.Len(t, events, 2)
+	for _, event := range events {
+		require.Equal(t, banker.Username, event.Actor)
+		require.Equal(t, AuditActionTransferLimitSet, event.Action)
+	}
+
+	result, err = store.SetTransferLimitTx(context.Background(), SetTransferLimitTxParams{
+		Username:        account.Owner,
+		MaxSingleAmount: nullInt64(50),
+		Audit:           AuditInfo{Actor: banker.Username},
+	})
+	require.NoError(t, err)
+	require.Equal(t, account.Owner, result.Limit.Username.String)
+
+	limits, err := store.GetTransferLimits(context.Background(), account, time.Now())
+	require.NoError(t, err)
+	require.Len(t, limits.User, 1)
+	require.Len(t, limits.Account, 1)
+
+	_, err = store.SetTransferLimitTx(context.Background(), SetTransferLimitTxParams{
+		Username: util.RandomOwner(),
+		Audit:    AuditInfo{Actor: banker.Username},
+	})
+	require.ErrorIs(t, err, sql.ErrNoRows)
+}
+
+func TestStore_ReviewTransferTxLimits(t *testing.T) {
+	store := NewStore(testDB)
+	review, fromAccount, toAccount := submitRandomTransferReview(t)
+	banker := createRandomUser(t)
+
+	setLimits := func(maxSingleAmount sql.NullInt64) {
+		_, err := store.SetTransferLimitTx(context.Background(), SetTransferLimitTxParams{
+			AccountID:       fromAccount.ID,
+			MaxSingleAmount: maxSingleAmount,
+			DailyAmount:     nullInt64(review.Amount + 5),
+			Audit:           AuditInfo{Actor: banker.Username},
+		})
+		require.NoError(t, err)
+	}
+	setLimits(nullInt64(review.Amount - 1))
+
+	// the pending review counts toward the limits
+	limits, err := store.GetTransferLimits(context.Background(), fromAccount, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, TransferLimitDailyAmount, limits.Account[1].Name)
+	require.Equal(t, review.Amount, limits.Account[1].Used)
+
+	_, err = store.TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        review.Amount - 1,
+	})
+	require.ErrorIs(t, err, ErrTransferLimitExceeded)
+
+	// the approved transfer is checked against the limits again
+	_, err = store.ReviewTransferTx(context.Background(), ReviewTransferTxParams{
+		ReviewID: review.ID,
+		Audit:    AuditInfo{Actor: banker.Username},
+		Approve:  true,
+	})
+	var limitErr *TransferLimitError
+	require.True(t, errors.As(err, &limitErr))
+	require.Equal(t, TransferLimitMaxSingleAmount, limitErr.Usage.Name)
+
+	pendingReview, err := testQueries.GetTransferReview(context.Background(), review.ID)
+	require.NoError(t, err)
+	require.Equal(t, TransferReviewStatusPending, pendingReview.Status)
+
+	// the review no longer counts once it is decided, so it fits in the daily limit
+	setLimits(sql.NullInt64{})
+
+	result, err := store.ReviewTransferTx(context.Background(), ReviewTransferTxParams{
+		ReviewID: review.ID,
+		Audit:    AuditInfo{Actor: banker.Username},
+		Approve:  true,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferReviewStatusApproved, result.Review.Status)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Review.TransferID.Int64)
+}
@@ -0,0 +1,130 @@
+package db
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+// stubRiskEvaluator returns the same decision for every transfer
+type stubRiskEvaluator struct {
+	decision RiskDecision
+}
+
+func (evaluator stubRiskEvaluator) Evaluate(ctx context.Context, q Querier, input RiskInput) (RiskAssessment, error) {
+	return RiskAssessment{
+		Decision: evaluator.decision,
+		Reasons:  []string{"stub " + string(evaluator.decision)},
+	}, nil
+}
+
+func submitRandomTransferReview(t *testing.T) (TransferReview, Account, Account) {
+	currency := util.RandomCurrency()
+	fromAccount := addRandomAccountBalance(t, createRandomAccountWithCurrency(t, currency), 100)
+	toAccount := createRandomAccountWithCurrency(t, currency)
+	amount := int64(10)
+
+	result, err := NewStore(testDB).TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        amount,
+		RiskEvaluator: stubRiskEvaluator{decision: RiskDecisionReview},
+	})
+	require.NoError(t, err)
+	require.Zero(t, result.Transfer.ID)
+
+	review := result.Review
+	require.NotZero(t, review.ID)
+	require.Equal(t, fromAccount.ID, review.FromAccountID)
+	require.Equal(t, toAccount.ID, review.ToAccountID)
+	require.Equal(t, amount, review.Amount)
+	require.Equal(t, "stub review", review.Reasons)
+	require.Equal(t, TransferReviewStatusPending, review.Status)
+
+	// the amount is held until the review
+	require.Equal(t, fromAccount.Balance, result.FromAccount.Balance)
+	require.Equal(t, fromAccount.HeldBalance+amount, result.FromAccount.HeldBalance)
+
+	return review, fromAccount, toAccount
+}
+
+func TestStore_TransferTxDenied(t *testing.T) {
+	currency := util.RandomCurrency()
+	fromAccount := addRandomAccountBalance(t, createRandomAccountWithCurrency(t, currency), 100)
+	toAccount := createRandomAccountWithCurrency(t, currency)
+
+	_, err := NewStore(testDB).TransferTx(context.Background(), TransferTxParams{
+		FromAccountID: fromAccount.ID,
+		ToAccountID:   toAccount.ID,
+		Amount:        10,
+		RiskEvaluator: stubRiskEvaluator{decision: RiskDecisionDeny},
+	})
+	require.ErrorIs(t, err, ErrTransferDenied)
+
+	updatedAccount, err := testQueries.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance, updatedAccount.Balance)
+	require.Equal(t, fromAccount.HeldBalance, updatedAccount.HeldBalance)
+}
+
+func TestStore_ReviewTransferTxApprove(t *testing.T) {
+	store := NewStore(testDB)
+	review, fromAccount, toAccount := submitRandomTransferReview(t)
+	banker := createRandomUser(t)
+
+	result, err := store.ReviewTransferTx(context.Background(), ReviewTransferTxParams{
+		ReviewID: review.ID,
+		Audit:    AuditInfo{Actor: banker.Username},
+		Approve:  true,
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, TransferReviewStatusApproved, result.Review.Status)
+	require.Equal(t, banker.Username, result.Review.ReviewedBy.String)
+	require.True(t, result.Review.ReviewedAt.Valid)
+	require.Equal(t, result.Transfer.Transfer.ID, result.Review.TransferID.Int64)
+
+	transfer := result.Transfer.Transfer
+	require.Equal(t, review.Amount, transfer.Amount)
+	require.Equal(t, fromAccount.Balance-review.Amount-transfer.Fee, result.Transfer.FromAccount.Balance)
+	require.Equal(t, fromAccount.HeldBalance, result.Transfer.FromAccount.HeldBalance)
+	require.Equal(t, toAccount.Balance+review.Amount, result.Transfer.ToAccount.Balance)
+
+	_, err = store.ReviewTransferTx(context.Background(), ReviewTransferTxParams{
+		ReviewID: review.ID,
+		Audit:    AuditInfo{Actor: banker.Username},
+		Approve:  false,
+	})
+	require.ErrorIs(t, err, ErrTransferReviewNotPending)
+}
+
+func TestStore_ReviewTransferTxReject(t *testing.T) {
+	store := NewStore(testDB)
+	review, fromAccount, _ := submitRandomTransferReview(t)
+	banker := createRandomUser(t)
+
+	result, err := store.ReviewTransferTx(context.Background(), ReviewTransferTxParams{
+		ReviewID: review.ID,
+		Audit:    AuditInfo{Actor: banker.Username},
+		Approve:  false,
+	})
+	require.NoError(t, err)
+	require.Equal(t, TransferReviewStatusRejected, result.Review.Status)
+	require.False(t, result.Review.TransferID.Valid)
+	require.Zero(t, result.Transfer.Transfer.ID)
+
+	updatedAccount, err := testQueries.GetAccount(context.Background(), fromAccount.ID)
+	require.NoError(t, err)
+	require.Equal(t, fromAccount.Balance, updatedAccount.Balance)
+	require.Equal(t, fromAccount.HeldBalance, updatedAccount.HeldBalance)
+
+	reviews, err := testQueries.ListTransferReviews(context.Background(), ListTransferReviewsParams{
+		Status: TransferReviewStatusRejected,
+		Limit:  100,
+		Offset: 0,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, reviews)
+}
@@ -0,0 +1,105 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+const (
+	AccountStatusActive = "active"
+	AccountStatusFrozen = "frozen"
+	AccountStatusClosed = "closed"
+)
+
+var (
+	// ErrAccountNotActive is returned when moving money into or out of a frozen or closed account
+	ErrAccountNotActive = errors.New("account is not active")
+	// ErrAccountNotEmpty is returned when closing an account which still has a balance or holds
+	ErrAccountNotEmpty = errors.New("account balance must be zero to close it")
+	// ErrInvalidAccountStatusChange is returned when changing the status of a closed account,
+	// or to the status the account already has
+	ErrInvalidAccountStatusChange = errors.New("invalid account status change")
+	// ErrAccountFrozenByBanker is returned when someone other than a banker changes the status of a frozen account,
+	// which would let the owner move it out of the freeze, e.g. by closing it
+	ErrAccountFrozenByBanker = errors.New("only bankers can change the status of a frozen account")
+)
+
+// checkAccountActive returns ErrAccountNotActive unless the account is active,
+// the account row must be locked for the check to hold until the transaction commits
+func checkAccountActive(account Account) error {
+	if account.Status != AccountStatusActive {
+		return fmt.Errorf("%w: account %d is %s", ErrAccountNotActive, account.ID, account.Status)
+	}
+	return nil
+}
+
+type ChangeAccountStatusTxParams struct {
+	AccountID int64  `json:"account_id"`
+	Status    string `json:"status"`
+	Reason    string `json:"reason"`
+	// Banker tells whether Audit.Actor is a banker, only bankers can move an account out of frozen
+	Banker bool `json:"banker"`
+	// Audit.Actor is the username of the banker or owner changing the status
+	Audit AuditInfo `json:"audit"`
+}
+
+type ChangeAccountStatusTxResult struct {
+	Account Account             `json:"account"`
+	Change  AccountStatusChange `json:"change"`
+}
+
+// ChangeAccountStatusTx freezes, unfreezes or closes an account and records the reason of the change.
+// Closed accounts are final and can only be closed with a zero balance and no holds,
+// frozen accounts can only be unfrozen or closed by a banker
+func (store *SQLStore) ChangeAccountStatusTx(ctx context.Context, arg ChangeAccountStatusTxParams) (ChangeAccountStatusTxResult, error) {
+	var result ChangeAccountStatusTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		account, err := q.GetAccountForUpdate(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		if account.Status == AccountStatusClosed || account.Status == arg.Status {
+			return fmt.Errorf("%w: account %d is already %s", ErrInvalidAccountStatusChange, account.ID, account.Status)
+		}
+
+		if account.Status == AccountStatusFrozen && !arg.Banker {
+			return fmt.Errorf("%w: account %d", ErrAccountFrozenByBanker, account.ID)
+		}
+
+		if arg.Status == AccountStatusClosed && (account.Balance != 0 || account.HeldBalance != 0) {
+			return ErrAccountNotEmpty
+		}
+
+		result.Account, err = q.UpdateAccountStatus(ctx, UpdateAccountStatusParams{
+			ID:     arg.AccountID,
+			Status: arg.Status,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Change, err = q.CreateAccountStatusChange(ctx, CreateAccountStatusChangeParams{
+			AccountID:  arg.AccountID,
+			FromStatus: account.Status,
+			ToStatus:   arg.Status,
+			Reason:     arg.Reason,
+			ChangedBy:  arg.Audit.Actor,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = recordAudit(ctx, q, arg.Audit, auditAccountStatusActions[arg.Status],
+			AuditTargetAccount, auditID(account.ID), account, result.Account)
+		if err != nil {
+			return err
+		}
+
+		return publishAccountUpdates(ctx, q, arg.AccountID)
+	})
+
+	return result, err
+}
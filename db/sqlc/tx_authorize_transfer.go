@@ -0,0 +1,106 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	HoldStatusAuthorized = "authorized"
+	HoldStatusCaptured   = "captured"
+	HoldStatusVoided     = "voided"
+	HoldStatusExpired    = "expired"
+)
+
+var (
+	// ErrHoldNotAuthorized is returned when a hold has already been captured, voided or expired
+	ErrHoldNotAuthorized = errors.New("hold is not authorized")
+	// ErrHoldExpired is returned when capturing a hold after its expiry time
+	ErrHoldExpired = errors.New("hold has expired")
+)
+
+type AuthorizeTransferTxParams struct {
+	FromAccountID int64     `json:"from_account_id"`
+	ToAccountID   int64     `json:"to_account_id"`
+	Amount        int64     `json:"amount"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	// RiskEvaluator screens the hold before it is placed, nil skips the screening
+	RiskEvaluator RiskEvaluator `json:"-"`
+	// Audit records who authorized the transfer, the client is also screened by the risk evaluator
+	Audit AuditInfo `json:"audit"`
+}
+
+type AuthorizeTransferTxResult struct {
+	Hold        Hold    `json:"hold"`
+	FromAccount Account `json:"from_account"`
+}
+
+// AuthorizeTransferTx places a hold for the amount and the fee of the transfer on the source account,
+// which reduces its available balance without moving any money until the hold is captured.
+// The hold is checked against the transfer limits and screened for risk here,
+// as capturing it settles the transfer without checking them again
+func (store *SQLStore) AuthorizeTransferTx(ctx context.Context, arg AuthorizeTransferTxParams) (AuthorizeTransferTxResult, error) {
+	var result AuthorizeTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		assessment, err := screenTransfer(ctx, q, fromAccount, arg.ToAccountID, arg.Amount, arg.RiskEvaluator, arg.Audit, time.Now())
+		if err != nil {
+			return err
+		}
+
+		// a capture cannot wait for a banker, so holds flagged for review are denied
+		if assessment.Decision == RiskDecisionReview {
+			return fmt.Errorf("%w: flagged for review: %s", ErrTransferDenied, strings.Join(assessment.Reasons, ", "))
+		}
+
+		// the fee is held with the amount, so the capture cannot run out of funds to pay it
+		fee, err := quoteFee(ctx, q, fromAccount.Currency, arg.Amount)
+		if err != nil {
+			return err
+		}
+
+		result.FromAccount, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			ID:     arg.FromAccountID,
+			Amount: arg.Amount + fee,
+		})
+		if err != nil {
+			return err
+		}
+
+		if result.FromAccount.Balance < result.FromAccount.HeldBalance {
+			return ErrInsufficientFunds
+		}
+
+		if err = checkAccountActive(result.FromAccount); err != nil {
+			return err
+		}
+
+		result.Hold, err = q.CreateHold(ctx, CreateHoldParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
+			Fee:           fee,
+			ExpiresAt:     arg.ExpiresAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = recordAudit(ctx, q, arg.Audit, AuditActionHoldAuthorized, AuditTargetHold, auditID(result.Hold.ID), nil, result.Hold)
+		if err != nil {
+			return err
+		}
+
+		return publishAccountUpdates(ctx, q, arg.FromAccountID)
+	})
+
+	return result, err
+}
@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+)
+
+// ErrSessionBlocked is returned when blocking a session which is already blocked
+var ErrSessionBlocked = errors.New("session is already blocked")
+
+type BlockSessionTxParams struct {
+	ID uuid.UUID `json:"id"`
+	// Audit.Actor is the username of the owner or banker blocking the session
+	Audit AuditInfo `json:"audit"`
+}
+
+type BlockSessionTxResult struct {
+	Session Session `json:"session"`
+}
+
+// BlockSessionTx blocks a session, so its refresh token cannot renew access tokens anymore,
+// and records the block in the audit log
+func (store *SQLStore) BlockSessionTx(ctx context.Context, arg BlockSessionTxParams) (BlockSessionTxResult, error) {
+	var result BlockSessionTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		session, err := q.GetSessionForUpdate(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		if session.IsBlocked {
+			return ErrSessionBlocked
+		}
+
+		result.Session, err = q.BlockSession(ctx, arg.ID)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, arg.Audit, AuditActionSessionBlocked, AuditTargetSession, session.ID.String(), session, result.Session)
+	})
+
+	return result, err
+}
@@ -0,0 +1,81 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"time"
+)
+
+type CaptureTransferTxParams struct {
+	HoldID int64 `json:"hold_id"`
+	// Audit records who captured the hold
+	Audit AuditInfo `json:"audit"`
+}
+
+type CaptureTransferTxResult struct {
+	Hold     Hold             `json:"hold"`
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// CaptureTransferTx releases a hold and settles its amount with a regular transfer charging the fee held with it,
+// the limits and the risk screening were applied when the hold was authorized
+func (store *SQLStore) CaptureTransferTx(ctx context.Context, arg CaptureTransferTxParams) (CaptureTransferTxResult, error) {
+	var result CaptureTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, arg.HoldID)
+		if err != nil {
+			return err
+		}
+
+		if hold.Status != HoldStatusAuthorized {
+			return ErrHoldNotAuthorized
+		}
+
+		if !time.Now().Before(hold.ExpiresAt) {
+			return ErrHoldExpired
+		}
+
+		// releasing the hold locks the source account before the transfer locks both of them,
+		// so lock them upfront in the same order as the transfer does to avoid deadlocks
+		_, err = lockAccounts(ctx, q, hold.FromAccountID, hold.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			ID:     hold.FromAccountID,
+			Amount: -(hold.Amount + hold.Fee),
+		})
+		if err != nil {
+			return err
+		}
+
+		// the fee quoted when authorizing was held, a new quote could exceed it
+		result.Transfer, err = transferChargingFee(ctx, q, CreateTransferParams{
+			FromAccountID: hold.FromAccountID,
+			ToAccountID:   hold.ToAccountID,
+			Amount:        hold.Amount,
+			Fee:           hold.Fee,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Hold, err = q.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+			ID:         hold.ID,
+			Status:     HoldStatusCaptured,
+			TransferID: sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, arg.Audit, AuditActionHoldCaptured, AuditTargetHold, auditID(hold.ID), hold, result.Hold)
+	})
+	if err == nil {
+		observeTransfer(result.Transfer)
+	}
+
+	return result, err
+}
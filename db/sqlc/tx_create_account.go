@@ -0,0 +1,41 @@
+package db
+
+import "context"
+
+type CreateAccountTxParams struct {
+	CreateAccountParams
+	Audit AuditInfo
+}
+
+type CreateAccountTxResult struct {
+	Account Account
+}
+
+// CreateAccountTx opens an account, emits its account.created event and records it in the audit log
+func (store *SQLStore) CreateAccountTx(ctx context.Context, arg CreateAccountTxParams) (CreateAccountTxResult, error) {
+	var result CreateAccountTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Account, err = q.CreateAccount(ctx, arg.CreateAccountParams)
+		if err != nil {
+			return err
+		}
+
+		err = emitEvent(ctx, q, EventAccountCreated, result.Account.Owner, AccountEventData{
+			AccountID: result.Account.ID,
+			Number:    result.Account.Number,
+			Owner:     result.Account.Owner,
+			Currency:  result.Account.Currency,
+			Type:      result.Account.Type,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, arg.Audit, AuditActionAccountCreated, AuditTargetAccount, auditID(result.Account.ID), nil, result.Account)
+	})
+
+	return result, err
+}
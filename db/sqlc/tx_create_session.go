@@ -0,0 +1,30 @@
+package db
+
+import "context"
+
+type CreateSessionTxParams struct {
+	CreateSessionParams
+	Audit AuditInfo
+}
+
+type CreateSessionTxResult struct {
+	Session Session
+}
+
+// CreateSessionTx creates the session of a user logging in and records the login in the audit log
+func (store *SQLStore) CreateSessionTx(ctx context.Context, arg CreateSessionTxParams) (CreateSessionTxResult, error) {
+	var result CreateSessionTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		result.Session, err = q.CreateSession(ctx, arg.CreateSessionParams)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, arg.Audit, AuditActionUserLoggedIn, AuditTargetSession, result.Session.ID.String(), nil, result.Session)
+	})
+
+	return result, err
+}
@@ -4,7 +4,8 @@ import "context"
 
 type CreateUserTxParams struct {
 	CreateUserParams
-	AfterCreate func(user User) error
+	// AfterCreate returns the tasks to enqueue through the outbox for the new user
+	AfterCreate func(user User) []OutboxTask
 }
 
 type CreateUserTxResult struct {
@@ -22,7 +23,16 @@ func (store *SQLStore) CreateUserTx(ctx context.Context, arg CreateUserTxParams)
 			return err
 		}
 
-		return arg.AfterCreate(result.User)
+		err = emitEvent(ctx, q, EventUserCreated, result.User.Username, UserEventData{
+			Username: result.User.Username,
+			FullName: result.User.FullName,
+			Email:    result.User.Email,
+		})
+		if err != nil {
+			return err
+		}
+
+		return enqueueOutboxTasks(ctx, q, arg.AfterCreate(result.User)...)
 	})
 
 	return result, err
@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// ClearingAccountOwner owns the clearing accounts, one per currency, which are the
+// counterparty of the money entering and leaving the bank through payment rails
+const ClearingAccountOwner = "simple_bank"
+
+var (
+	// ErrNoClearingAccount is returned when there is no clearing account for the currency of an account
+	ErrNoClearingAccount = errors.New("no clearing account for the currency")
+	// ErrPaymentNotPending is returned when settling a payment which has already completed or failed
+	ErrPaymentNotPending = errors.New("payment is not pending")
+)
+
+const (
+	PaymentKindDeposit    = "deposit"
+	PaymentKindWithdrawal = "withdrawal"
+)
+
+const (
+	PaymentStatusPending   = "pending"
+	PaymentStatusCompleted = "completed"
+	PaymentStatusFailed    = "failed"
+)
+
+type DepositTxParams struct {
+	// PaymentID is the pending deposit recorded before collecting the money
+	PaymentID int64 `json:"payment_id"`
+	// ExternalRef is the reference of the collection on the rail
+	ExternalRef string    `json:"external_ref"`
+	Audit       AuditInfo `json:"audit"`
+}
+
+type DepositTxResult struct {
+	Payment  Payment          `json:"payment"`
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// DepositTx credits an account with the money of a pending deposit once the payment rail collected it,
+// posting it as a transfer from the clearing account of the same currency, and emits the deposit.completed event
+func (store *SQLStore) DepositTx(ctx context.Context, arg DepositTxParams) (DepositTxResult, error) {
+	var result DepositTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		payment, err := getPendingPayment(ctx, q, arg.PaymentID, PaymentKindDeposit)
+		if err != nil {
+			return err
+		}
+
+		clearingAccount, err := getClearingAccount(ctx, q, payment.AccountID)
+		if err != nil {
+			return err
+		}
+
+		// the clearing account is allowed to go negative
+		result.Transfer, err = postTransfer(ctx, q, CreateTransferParams{
+			FromAccountID: clearingAccount.ID,
+			ToAccountID:   payment.AccountID,
+			Amount:        payment.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Payment, err = q.UpdatePayment(ctx, UpdatePaymentParams{
+			ID:          payment.ID,
+			Status:      PaymentStatusCompleted,
+			ExternalRef: sql.NullString{String: arg.ExternalRef, Valid: true},
+			TransferID:  sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		err = recordAudit(ctx, q, arg.Audit, AuditActionDepositCompleted, AuditTargetPayment, auditID(payment.ID), payment, result.Payment)
+		if err != nil {
+			return err
+		}
+
+		err = emitPaymentEvent(ctx, q, EventDepositCompleted, result.Payment, result.Transfer.ToAccount)
+		if err != nil {
+			return err
+		}
+
+		return publishAccountUpdates(ctx, q, payment.AccountID)
+	})
+
+	return result, err
+}
+
+type FailDepositTxParams struct {
+	PaymentID int64 `json:"payment_id"`
+	// ExternalRef is the reference of a collection which was refunded, empty when nothing was collected
+	ExternalRef string    `json:"external_ref"`
+	LastError   string    `json:"last_error"`
+	Audit       AuditInfo `json:"audit"`
+}
+
+type FailDepositTxResult struct {
+	Payment Payment `json:"payment"`
+}
+
+// FailDepositTx records that the money of a pending deposit was not collected, or was refunded
+func (store *SQLStore) FailDepositTx(ctx context.Context, arg FailDepositTxParams) (FailDepositTxResult, error) {
+	var result FailDepositTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		payment, err := getPendingPayment(ctx, q, arg.PaymentID, PaymentKindDeposit)
+		if err != nil {
+			return err
+		}
+
+		result.Payment, err = q.UpdatePayment(ctx, UpdatePaymentParams{
+			ID:          payment.ID,
+			Status:      PaymentStatusFailed,
+			ExternalRef: sql.NullString{String: arg.ExternalRef, Valid: arg.ExternalRef != ""},
+			LastError:   arg.LastError,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, arg.Audit, AuditActionDepositFailed, AuditTargetPayment, auditID(payment.ID), payment, result.Payment)
+	})
+
+	return result, err
+}
+
+// getPendingPayment locks a pending payment of the given kind
+func getPendingPayment(ctx context.Context, q *Queries, id int64, kind string) (Payment, error) {
+	payment, err := q.GetPaymentForUpdate(ctx, id)
+	if err != nil {
+		return payment, err
+	}
+
+	if payment.Kind != kind {
+		return payment, fmt.Errorf("payment %d is a %s", payment.ID, payment.Kind)
+	}
+
+	if payment.Status != PaymentStatusPending {
+		return payment, ErrPaymentNotPending
+	}
+
+	return payment, nil
+}
+
+// getClearingAccount returns the clearing account with the currency of the given account
+func getClearingAccount(ctx context.Context, q *Queries, accountID int64) (Account, error) {
+	account, err := q.GetAccount(ctx, accountID)
+	if err != nil {
+		return account, err
+	}
+
+	clearingAccount, err := q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+		Owner:    ClearingAccountOwner,
+		Currency: account.Currency,
+		Type:     AccountTypeChecking,
+	})
+	if err == sql.ErrNoRows {
+		return clearingAccount, ErrNoClearingAccount
+	}
+	return clearingAccount, err
+}
@@ -0,0 +1,213 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Dejan91/simple_bank/util"
+	"time"
+)
+
+const (
+	ScheduledTransferStatusActive    = "active"
+	ScheduledTransferStatusPaused    = "paused"
+	ScheduledTransferStatusCompleted = "completed"
+	ScheduledTransferStatusCancelled = "cancelled"
+)
+
+const (
+	ExecutionStatusSucceeded = "succeeded"
+	ExecutionStatusFailed    = "failed"
+	// ExecutionStatusHeld is recorded when the risk screening holds the transfer for review
+	ExecutionStatusHeld = "held"
+)
+
+// ErrScheduleNotDue is returned when a scheduled transfer is not active or not due yet,
+// e.g. because another worker has already executed it or is executing it
+var ErrScheduleNotDue = errors.New("scheduled transfer is not due")
+
+type ExecuteScheduledTransferTxParams struct {
+	ID  int64
+	Now time.Time
+	// MaxFailures is the number of consecutive insufficient funds failures after which the schedule is paused
+	MaxFailures int32
+	// RiskEvaluator screens the transfer like TransferTx does, nil skips the screening
+	RiskEvaluator RiskEvaluator
+}
+
+type ExecuteScheduledTransferTxResult struct {
+	ScheduledTransfer ScheduledTransfer
+	Execution         ScheduledTransferExecution
+	// Transfer is only set when the execution succeeded, or only its Review when the execution is held
+	Transfer TransferTxResult
+}
+
+// ExecuteScheduledTransferTx runs a due scheduled transfer through the same logic as TransferTx
+// and records the execution. A transfer held by the risk screening is submitted for review like TransferTx does,
+// a failed or denied transfer is recorded as a failed execution in a separate transaction,
+// and the schedule is paused after too many consecutive insufficient funds failures.
+func (store *SQLStore) ExecuteScheduledTransferTx(ctx context.Context, arg ExecuteScheduledTransferTxParams) (ExecuteScheduledTransferTxResult, error) {
+	var result ExecuteScheduledTransferTxResult
+
+	var transferErr error
+	err := store.execTx(ctx, func(q *Queries) error {
+		schedule, err := getDueScheduledTransfer(ctx, q, arg.ID, arg.Now)
+		if err != nil {
+			return err
+		}
+
+		audit := AuditInfo{Actor: AuditActorSystem}
+		result.Transfer, transferErr = scheduledTransfer(ctx, q, schedule, arg.RiskEvaluator, audit, arg.Now)
+		if transferErr != nil {
+			return transferErr
+		}
+
+		execution := CreateScheduledTransferExecutionParams{
+			ScheduledTransferID: schedule.ID,
+			TransferID:          sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+			Status:              ExecutionStatusSucceeded,
+		}
+		if result.Transfer.Review.ID != 0 {
+			execution.TransferID = sql.NullInt64{}
+			execution.Status = ExecutionStatusHeld
+		}
+
+		result.Execution, err = q.CreateScheduledTransferExecution(ctx, execution)
+		if err != nil {
+			return err
+		}
+
+		result.ScheduledTransfer, err = advanceScheduledTransfer(ctx, q, schedule, arg.Now, 0)
+		if err != nil {
+			return err
+		}
+
+		if result.Transfer.Review.ID != 0 {
+			review := result.Transfer.Review
+			return recordAudit(ctx, q, audit, AuditActionTransferHeld, AuditTargetTransferReview, auditID(review.ID), nil, review)
+		}
+
+		transfer := result.Transfer.Transfer
+		return recordAudit(ctx, q, audit, AuditActionScheduledTransferExecuted, AuditTargetTransfer, auditID(transfer.ID), nil, transfer)
+	})
+	if transferErr == nil {
+		if err == nil {
+			observeTransfer(result.Transfer)
+		}
+		return result, err
+	}
+
+	result.Transfer = TransferTxResult{}
+	err = store.execTx(ctx, func(q *Queries) error {
+		schedule, err := getDueScheduledTransfer(ctx, q, arg.ID, arg.Now)
+		if err != nil {
+			return err
+		}
+
+		result.Execution, err = q.CreateScheduledTransferExecution(ctx, CreateScheduledTransferExecutionParams{
+			ScheduledTransferID: schedule.ID,
+			Status:              ExecutionStatusFailed,
+			Error:               transferErr.Error(),
+		})
+		if err != nil {
+			return err
+		}
+
+		failureCount := schedule.FailureCount
+		if errors.Is(transferErr, ErrInsufficientFunds) {
+			failureCount++
+		}
+
+		if arg.MaxFailures > 0 && failureCount >= arg.MaxFailures {
+			result.ScheduledTransfer, err = q.UpdateScheduledTransfer(ctx, UpdateScheduledTransferParams{
+				ID:           schedule.ID,
+				Status:       sql.NullString{String: ScheduledTransferStatusPaused, Valid: true},
+				FailureCount: sql.NullInt32{Int32: failureCount, Valid: true},
+			})
+			return err
+		}
+
+		result.ScheduledTransfer, err = advanceScheduledTransfer(ctx, q, schedule, arg.Now, failureCount)
+		return err
+	})
+
+	return result, err
+}
+
+// scheduledTransfer screens the transfer of a schedule and executes it with its fee,
+// or submits it for review when the risk screening holds it
+func scheduledTransfer(
+	ctx context.Context,
+	q *Queries,
+	schedule ScheduledTransfer,
+	evaluator RiskEvaluator,
+	audit AuditInfo,
+	now time.Time,
+) (TransferTxResult, error) {
+	fromAccount, err := q.GetAccount(ctx, schedule.FromAccountID)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	assessment, err := screenTransfer(ctx, q, fromAccount, schedule.ToAccountID, schedule.Amount, evaluator, audit, now)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	if assessment.Decision == RiskDecisionReview {
+		return submitTransferReview(ctx, q, TransferTxParams{
+			FromAccountID: schedule.FromAccountID,
+			ToAccountID:   schedule.ToAccountID,
+			Amount:        schedule.Amount,
+		}, assessment)
+	}
+
+	return transferWithFee(ctx, q, CreateTransferParams{
+		FromAccountID: schedule.FromAccountID,
+		ToAccountID:   schedule.ToAccountID,
+		Amount:        schedule.Amount,
+	})
+}
+
+// getDueScheduledTransfer locks the scheduled transfer when it is active and due. The lock is skipped
+// when another worker holds it, so two workers never run the same schedule and the loser gets ErrScheduleNotDue
+// instead of waiting; once the winner advances next_run_at the schedule is not due anymore.
+func getDueScheduledTransfer(ctx context.Context, q *Queries, id int64, now time.Time) (ScheduledTransfer, error) {
+	schedule, err := q.GetDueScheduledTransferForUpdate(ctx, GetDueScheduledTransferForUpdateParams{
+		ID:  id,
+		Now: now,
+	})
+	if err == sql.ErrNoRows {
+		return schedule, ErrScheduleNotDue
+	}
+
+	return schedule, err
+}
+
+// advanceScheduledTransfer moves the schedule to its next run after now,
+// skipping runs missed while no worker was running, and completes it once past its end date
+func advanceScheduledTransfer(
+	ctx context.Context,
+	q *Queries,
+	schedule ScheduledTransfer,
+	now time.Time,
+	failureCount int32,
+) (ScheduledTransfer, error) {
+	recurrence, err := util.ParseRecurrence(schedule.Recurrence)
+	if err != nil {
+		return schedule, fmt.Errorf("invalid recurrence %q: %w", schedule.Recurrence, err)
+	}
+
+	arg := UpdateScheduledTransferParams{
+		ID:           schedule.ID,
+		NextRunAt:    sql.NullTime{Time: recurrence.Next(now), Valid: true},
+		FailureCount: sql.NullInt32{Int32: failureCount, Valid: true},
+	}
+
+	if schedule.EndAt.Valid && arg.NextRunAt.Time.After(schedule.EndAt.Time) {
+		arg.Status = sql.NullString{String: ScheduledTransferStatusCompleted, Valid: true}
+	}
+
+	return q.UpdateScheduledTransfer(ctx, arg)
+}
@@ -0,0 +1,177 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+)
+
+var (
+	// ErrJournalTooFewLegs is returned when a journal has less than two legs
+	ErrJournalTooFewLegs = errors.New("journal must have at least two legs")
+	// ErrJournalZeroLeg is returned when a leg of a journal doesn't move any money
+	ErrJournalZeroLeg = errors.New("journal leg amount must not be zero")
+	// ErrJournalUnbalanced is returned when the legs of a journal don't sum to zero
+	ErrJournalUnbalanced = errors.New("journal legs must sum to zero")
+	// ErrJournalCurrencyMismatch is returned when the accounts of a journal have different currencies
+	ErrJournalCurrencyMismatch = errors.New("journal accounts must have the same currency")
+)
+
+// JournalLeg is a single debit or credit of a journal
+type JournalLeg struct {
+	AccountID int64 `json:"account_id"`
+	// Amount is negative for debits and positive for credits
+	Amount int64 `json:"amount"`
+}
+
+type PostJournalTxParams struct {
+	Description string       `json:"description"`
+	Legs        []JournalLeg `json:"legs"`
+	// Audit is only recorded by PostJournalTx, the journals composed into other transactions are audited by them
+	Audit AuditInfo `json:"audit"`
+}
+
+type PostJournalTxResult struct {
+	Journal Journal `json:"journal"`
+	// Accounts are the updated accounts ordered by ID
+	Accounts []Account `json:"accounts"`
+	// Entries are the posted entries in the order of the legs
+	Entries []Entry `json:"entries"`
+}
+
+// PostJournalTx atomically posts any number of balanced legs across accounts of the same currency
+func (store *SQLStore) PostJournalTx(ctx context.Context, arg PostJournalTxParams) (PostJournalTxResult, error) {
+	var result PostJournalTxResult
+
+	err := validateJournalLegs(arg.Legs)
+	if err != nil {
+		return result, err
+	}
+
+	err = store.execTx(ctx, func(q *Queries) error {
+		var err error
+		result, err = postJournal(ctx, q, arg)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, arg.Audit, AuditActionJournalPosted, AuditTargetJournal, auditID(result.Journal.ID), nil, result)
+	})
+
+	return result, err
+}
+
+func validateJournalLegs(legs []JournalLeg) error {
+	if len(legs) < 2 {
+		return ErrJournalTooFewLegs
+	}
+
+	var sum int64
+	for _, leg := range legs {
+		if leg.Amount == 0 {
+			return ErrJournalZeroLeg
+		}
+		sum += leg.Amount
+	}
+
+	if sum != 0 {
+		return ErrJournalUnbalanced
+	}
+
+	return nil
+}
+
+// postJournal records the journal with its entries and updates the balances using the given queries,
+// so it can be composed into bigger transactions
+func postJournal(ctx context.Context, q *Queries, arg PostJournalTxParams) (PostJournalTxResult, error) {
+	var result PostJournalTxResult
+
+	amounts := make(map[int64]int64)
+	accountIDs := make([]int64, 0, len(arg.Legs))
+	for _, leg := range arg.Legs {
+		if _, ok := amounts[leg.AccountID]; !ok {
+			accountIDs = append(accountIDs, leg.AccountID)
+		}
+		amounts[leg.AccountID] += leg.Amount
+	}
+
+	accounts, err := lockAccounts(ctx, q, accountIDs...)
+	if err != nil {
+		return result, err
+	}
+
+	currency := accounts[accountIDs[0]].Currency
+	for _, account := range accounts {
+		if account.Currency != currency {
+			return result, ErrJournalCurrencyMismatch
+		}
+
+		if err = checkAccountActive(account); err != nil {
+			return result, err
+		}
+	}
+
+	result.Journal, err = q.CreateJournal(ctx, arg.Description)
+	if err != nil {
+		return result, err
+	}
+
+	sort.Slice(accountIDs, func(i, j int) bool { return accountIDs[i] < accountIDs[j] })
+	for _, accountID := range accountIDs {
+		account, err := q.AddAccountBalance(ctx, AddAccountBalanceParams{
+			ID:     accountID,
+			Amount: amounts[accountID],
+		})
+		if err != nil {
+			return result, err
+		}
+
+		// held money is not available for debits
+		if amounts[accountID] < 0 && account.Balance < account.HeldBalance {
+			return result, ErrInsufficientFunds
+		}
+
+		result.Accounts = append(result.Accounts, account)
+	}
+
+	journalID := sql.NullInt64{Int64: result.Journal.ID, Valid: true}
+	for _, leg := range arg.Legs {
+		entry, err := q.CreateEntry(ctx, CreateEntryParams{
+			AccountID: leg.AccountID,
+			Amount:    leg.Amount,
+			JournalID: journalID,
+		})
+		if err != nil {
+			return result, err
+		}
+
+		result.Entries = append(result.Entries, entry)
+	}
+
+	err = publishAccountUpdates(ctx, q, accountIDs...)
+	return result, err
+}
+
+// lockAccounts locks the given accounts ordered by ID, so concurrent transactions
+// touching the same accounts cannot deadlock, and returns them by ID
+func lockAccounts(ctx context.Context, q *Queries, accountIDs ...int64) (map[int64]Account, error) {
+	ids := make([]int64, len(accountIDs))
+	copy(ids, accountIDs)
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	accounts := make(map[int64]Account, len(ids))
+	for _, id := range ids {
+		if _, ok := accounts[id]; ok {
+			continue
+		}
+
+		account, err := q.GetAccountForUpdate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		accounts[id] = account
+	}
+
+	return accounts, nil
+}
@@ -0,0 +1,88 @@
+package db
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/Dejan91/simple_bank/metrics"
+	"time"
+)
+
+// maxReconciliationDetails is the max number of mismatching accounts and transfers stored with a run
+const maxReconciliationDetails = 100
+
+// ReconciliationDetails lists the mismatches found by a reconciliation run
+type ReconciliationDetails struct {
+	Accounts  []ListAccountBalanceMismatchesRow `json:"accounts"`
+	Transfers []ListTransferEntryMismatchesRow  `json:"transfers"`
+}
+
+type ReconcileLedgerTxResult struct {
+	Run     ReconciliationRun     `json:"run"`
+	Details ReconciliationDetails `json:"details"`
+}
+
+// HasDiscrepancies reports whether the run found any mismatch
+func (result ReconcileLedgerTxResult) HasDiscrepancies() bool {
+	return result.Run.AccountDiscrepancies > 0 || result.Run.TransferDiscrepancies > 0
+}
+
+// ReconcileLedgerTx checks that every account balance equals the sum of its entries
+// and that every transfer has exactly one debit and one credit entry of its amount,
+// then records the outcome in reconciliation_runs and in the metrics
+func (store *SQLStore) ReconcileLedgerTx(ctx context.Context) (ReconcileLedgerTxResult, error) {
+	var result ReconcileLedgerTxResult
+	startedAt := time.Now()
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		accountsChecked, err := q.CountAccounts(ctx)
+		if err != nil {
+			return err
+		}
+
+		transfersChecked, err := q.CountTransfers(ctx)
+		if err != nil {
+			return err
+		}
+
+		accounts, err := q.ListAccountBalanceMismatches(ctx)
+		if err != nil {
+			return err
+		}
+
+		transfers, err := q.ListTransferEntryMismatches(ctx)
+		if err != nil {
+			return err
+		}
+
+		result.Details = ReconciliationDetails{
+			Accounts:  accounts,
+			Transfers: transfers,
+		}
+		if len(accounts) > maxReconciliationDetails {
+			result.Details.Accounts = accounts[:maxReconciliationDetails]
+		}
+		if len(transfers) > maxReconciliationDetails {
+			result.Details.Transfers = transfers[:maxReconciliationDetails]
+		}
+
+		details, err := json.Marshal(result.Details)
+		if err != nil {
+			return err
+		}
+
+		result.Run, err = q.CreateReconciliationRun(ctx, CreateReconciliationRunParams{
+			AccountsChecked:       accountsChecked,
+			TransfersChecked:      transfersChecked,
+			AccountDiscrepancies:  int64(len(accounts)),
+			TransferDiscrepancies: int64(len(transfers)),
+			Details:               details,
+			StartedAt:             startedAt,
+		})
+		return err
+	})
+	if err == nil {
+		metrics.ObserveReconciliation(result.Run.AccountDiscrepancies, result.Run.TransferDiscrepancies, time.Now())
+	}
+
+	return result, err
+}
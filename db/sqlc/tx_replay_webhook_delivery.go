@@ -0,0 +1,46 @@
+package db
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrWebhookDeliveryNotFailed is returned when replaying a delivery which is pending, succeeded or already replayed
+var ErrWebhookDeliveryNotFailed = errors.New("webhook delivery has not failed")
+
+type ReplayWebhookDeliveryTxParams struct {
+	DeliveryID int64 `json:"delivery_id"`
+}
+
+type ReplayWebhookDeliveryTxResult struct {
+	// Original is the replayed delivery, Delivery the new one
+	Original WebhookDelivery `json:"original"`
+	Delivery WebhookDelivery `json:"delivery"`
+}
+
+// ReplayWebhookDeliveryTx delivers the event of a failed delivery again with a new delivery.
+// The failed delivery is locked and marked replayed, so concurrent replays of it deliver the event only once
+func (store *SQLStore) ReplayWebhookDeliveryTx(ctx context.Context, arg ReplayWebhookDeliveryTxParams) (ReplayWebhookDeliveryTxResult, error) {
+	var result ReplayWebhookDeliveryTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		delivery, err := q.GetWebhookDeliveryForUpdate(ctx, arg.DeliveryID)
+		if err != nil {
+			return err
+		}
+
+		if delivery.Status != WebhookDeliveryStatusFailed {
+			return ErrWebhookDeliveryNotFailed
+		}
+
+		result.Original, err = q.MarkWebhookDeliveryReplayed(ctx, delivery.ID)
+		if err != nil {
+			return err
+		}
+
+		result.Delivery, err = enqueueWebhookDelivery(ctx, q, delivery.SubscriptionID, delivery.EventID)
+		return err
+	})
+
+	return result, err
+}
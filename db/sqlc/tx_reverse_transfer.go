@@ -0,0 +1,182 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrTransferIsReversal is returned when trying to reverse a transfer which is itself a reversal
+	ErrTransferIsReversal = errors.New("transfer is a reversal and cannot be reversed")
+	// ErrReversalExceedsAmount is returned when the reversal would refund more than what is left of the original amount
+	ErrReversalExceedsAmount = errors.New("reversal exceeds the remaining amount of the transfer")
+	// ErrSystemTransfer is returned when trying to reverse a transfer to or from a system account,
+	// e.g. a deposit, a withdrawal or an interest payment, which are undone through their own flows
+	ErrSystemTransfer = errors.New("transfer involves a system account and cannot be reversed")
+	// ErrSettlementTransfer is returned when trying to reverse a transfer settling a payment, a hold or a review
+	ErrSettlementTransfer = errors.New("transfer settles a payment, a hold or a review and cannot be reversed")
+)
+
+type ReverseTransferTxParams struct {
+	TransferID int64 `json:"transfer_id"`
+	// Amount to refund, 0 means the whole remaining amount
+	Amount int64     `json:"amount"`
+	Audit  AuditInfo `json:"audit"`
+}
+
+type ReverseTransferTxResult struct {
+	OriginalTransfer Transfer         `json:"original_transfer"`
+	Reversal         TransferTxResult `json:"reversal"`
+	// RemainingAmount is what can still be reversed after this reversal
+	RemainingAmount int64 `json:"remaining_amount"`
+	// FeeRefund is the part of the original fee given back to the sender
+	FeeRefund int64 `json:"fee_refund"`
+}
+
+// ReverseTransferTx refunds all or part of a transfer by moving the money back
+// from the receiving to the sending account with a transfer linked to the original.
+// The fee of the original transfer is refunded in proportion to the reversed amount.
+func (store *SQLStore) ReverseTransferTx(ctx context.Context, arg ReverseTransferTxParams) (ReverseTransferTxResult, error) {
+	var result ReverseTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var err error
+
+		// locking the original transfer serializes concurrent reversals of it
+		result.OriginalTransfer, err = q.GetTransferForUpdate(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		if result.OriginalTransfer.ReversalOfID.Valid {
+			return ErrTransferIsReversal
+		}
+
+		err = checkReversible(ctx, q, result.OriginalTransfer)
+		if err != nil {
+			return err
+		}
+
+		reversedAmount, err := q.GetReversedAmount(ctx, arg.TransferID)
+		if err != nil {
+			return err
+		}
+
+		remainingAmount := result.OriginalTransfer.Amount - reversedAmount
+		amount := arg.Amount
+		if amount == 0 {
+			amount = remainingAmount
+		}
+		if amount <= 0 || amount > remainingAmount {
+			return ErrReversalExceedsAmount
+		}
+
+		result.Reversal, err = transfer(ctx, q, CreateTransferParams{
+			FromAccountID: result.OriginalTransfer.ToAccountID,
+			ToAccountID:   result.OriginalTransfer.FromAccountID,
+			Amount:        amount,
+			ReversalOfID:  sql.NullInt64{Int64: arg.TransferID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		result.FeeRefund = feeRefund(result.OriginalTransfer, reversedAmount, amount)
+		if result.FeeRefund != 0 {
+			result.Reversal, err = refundTransferFee(ctx, q, result.Reversal, result.OriginalTransfer, result.FeeRefund)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = emitTransferEvent(ctx, q, EventTransferReversed, result.Reversal)
+		if err != nil {
+			return err
+		}
+
+		result.RemainingAmount = remainingAmount - amount
+
+		return recordAudit(ctx, q, arg.Audit, AuditActionTransferReversed,
+			AuditTargetTransfer, auditID(arg.TransferID), nil, result.Reversal.Transfer)
+	})
+
+	return result, err
+}
+
+// checkReversible refuses the transfers moving money in or out of the bank, and the ones settling
+// another operation, since reversing them would leave the payment, hold or review out of sync
+func checkReversible(ctx context.Context, q *Queries, transfer Transfer) error {
+	for _, accountID := range []int64{transfer.FromAccountID, transfer.ToAccountID} {
+		account, err := q.GetAccount(ctx, accountID)
+		if err != nil {
+			return err
+		}
+
+		if IsSystemAccountOwner(account.Owner) {
+			return ErrSystemTransfer
+		}
+	}
+
+	settlement, err := q.IsSettlementTransfer(ctx, transfer.ID)
+	if err != nil {
+		return err
+	}
+	if settlement {
+		return ErrSettlementTransfer
+	}
+
+	return nil
+}
+
+// feeRefund returns the part of the fee refunded when reversing amount after reversedAmount was already reversed.
+// It rounds the total refunded so far down, so the refunds of the partial reversals add up to the whole fee.
+func feeRefund(transfer Transfer, reversedAmount, amount int64) int64 {
+	refunded := transfer.Fee * reversedAmount / transfer.Amount
+	return transfer.Fee*(reversedAmount+amount)/transfer.Amount - refunded
+}
+
+// refundTransferFee moves part of the fee of a transfer from the revenue account back to its source account,
+// the journal is linked to the reversal like the fee journal to the transfer
+func refundTransferFee(ctx context.Context, q *Queries, reversal TransferTxResult, original Transfer, refund int64) (TransferTxResult, error) {
+	revenueAccount, err := q.GetAccountByOwnerAndCurrency(ctx, GetAccountByOwnerAndCurrencyParams{
+		Owner:    RevenueAccountOwner,
+		Currency: reversal.ToAccount.Currency,
+		Type:     AccountTypeChecking,
+	})
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return reversal, ErrNoRevenueAccount
+		}
+		return reversal, err
+	}
+
+	// the revenue account is locked after the accounts of the reversal, like when the fee was charged
+	refundJournal, err := postJournal(ctx, q, PostJournalTxParams{
+		Description: fmt.Sprintf("fee refund of transfer %d", original.ID),
+		Legs: []JournalLeg{
+			{AccountID: revenueAccount.ID, Amount: -refund},
+			{AccountID: original.FromAccountID, Amount: refund},
+		},
+	})
+	if err != nil {
+		return reversal, err
+	}
+
+	reversal.Transfer, err = q.UpdateTransferFeeJournal(ctx, UpdateTransferFeeJournalParams{
+		ID:           reversal.Transfer.ID,
+		FeeJournalID: sql.NullInt64{Int64: refundJournal.Journal.ID, Valid: true},
+	})
+	if err != nil {
+		return reversal, err
+	}
+
+	for _, account := range refundJournal.Accounts {
+		if account.ID == original.FromAccountID {
+			reversal.ToAccount = account
+		}
+	}
+
+	return reversal, nil
+}
@@ -0,0 +1,169 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+)
+
+const (
+	TransferReviewStatusPending  = "pending"
+	TransferReviewStatusApproved = "approved"
+	TransferReviewStatusRejected = "rejected"
+)
+
+// ErrTransferReviewNotPending is returned when reviewing a transfer which has already been approved or rejected
+var ErrTransferReviewNotPending = errors.New("transfer review is not pending")
+
+// submitTransferReview holds the amount of a flagged transfer on the source account
+// and queues the transfer for a banker to approve or reject it
+func submitTransferReview(ctx context.Context, q *Queries, arg TransferTxParams, assessment RiskAssessment) (TransferTxResult, error) {
+	var result TransferTxResult
+	var err error
+
+	result.FromAccount, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+		ID:     arg.FromAccountID,
+		Amount: arg.Amount,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if result.FromAccount.Balance < result.FromAccount.HeldBalance {
+		return result, ErrInsufficientFunds
+	}
+
+	if err = checkAccountActive(result.FromAccount); err != nil {
+		return result, err
+	}
+
+	// the destination is checked again when the transfer is approved
+	toAccount, err := q.GetAccount(ctx, arg.ToAccountID)
+	if err != nil {
+		return result, err
+	}
+
+	if err = checkAccountActive(toAccount); err != nil {
+		return result, err
+	}
+
+	result.Review, err = q.CreateTransferReview(ctx, CreateTransferReviewParams{
+		FromAccountID: arg.FromAccountID,
+		ToAccountID:   arg.ToAccountID,
+		Amount:        arg.Amount,
+		Reasons:       strings.Join(assessment.Reasons, ", "),
+	})
+	if err != nil {
+		return result, err
+	}
+
+	err = publishAccountUpdates(ctx, q, arg.FromAccountID)
+	return result, err
+}
+
+type ReviewTransferTxParams struct {
+	ReviewID int64 `json:"review_id"`
+	Approve  bool  `json:"approve"`
+	// Audit.Actor is recorded as the reviewer
+	Audit AuditInfo `json:"audit"`
+}
+
+type ReviewTransferTxResult struct {
+	Review TransferReview `json:"review"`
+	// Transfer is empty when the review is rejected
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// ReviewTransferTx releases the amount held by a transfer pending review,
+// and executes the transfer with its fee when the reviewer approves it and it still fits in the transfer limits
+func (store *SQLStore) ReviewTransferTx(ctx context.Context, arg ReviewTransferTxParams) (ReviewTransferTxResult, error) {
+	var result ReviewTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		review, err := q.GetTransferReviewForUpdate(ctx, arg.ReviewID)
+		if err != nil {
+			return err
+		}
+
+		if review.Status != TransferReviewStatusPending {
+			return ErrTransferReviewNotPending
+		}
+
+		fromAccount, err := q.GetAccount(ctx, review.FromAccountID)
+		if err != nil {
+			return err
+		}
+
+		// releasing the held amount locks the source account before the transfer locks the owner and both accounts,
+		// so lock them upfront in the same order as the transfer does to avoid deadlocks
+		_, err = q.GetUserForUpdate(ctx, fromAccount.Owner)
+		if err != nil {
+			return err
+		}
+
+		_, err = lockAccounts(ctx, q, review.FromAccountID, review.ToAccountID)
+		if err != nil {
+			return err
+		}
+
+		_, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+			ID:     review.FromAccountID,
+			Amount: -review.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		status := TransferReviewStatusRejected
+		action := AuditActionTransferRejected
+		if arg.Approve {
+			status = TransferReviewStatusApproved
+			action = AuditActionTransferApproved
+		}
+
+		// the pending review counts toward the transfer limits,
+		// so it is decided before the approved transfer checks them again
+		result.Review, err = q.UpdateTransferReview(ctx, UpdateTransferReviewParams{
+			ID:         review.ID,
+			Status:     status,
+			ReviewedBy: sql.NullString{String: arg.Audit.Actor, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		if arg.Approve {
+			result.Transfer, err = customerTransfer(ctx, q, CreateTransferParams{
+				FromAccountID: review.FromAccountID,
+				ToAccountID:   review.ToAccountID,
+				Amount:        review.Amount,
+			})
+			if err != nil {
+				return err
+			}
+
+			result.Review, err = q.UpdateTransferReview(ctx, UpdateTransferReviewParams{
+				ID:         review.ID,
+				Status:     status,
+				ReviewedBy: sql.NullString{String: arg.Audit.Actor, Valid: true},
+				TransferID: sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		err = recordAudit(ctx, q, arg.Audit, action, AuditTargetTransferReview, auditID(review.ID), review, result.Review)
+		if err != nil {
+			return err
+		}
+
+		return publishAccountUpdates(ctx, q, review.FromAccountID)
+	})
+	if err == nil {
+		observeTransfer(result.Transfer)
+	}
+
+	return result, err
+}
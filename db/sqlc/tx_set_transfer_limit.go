@@ -0,0 +1,85 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+)
+
+type SetTransferLimitTxParams struct {
+	// Username sets the limits shared by all the accounts of the user,
+	// AccountID sets the limits of a single account, only one of them is set
+	Username  string `json:"username"`
+	AccountID int64  `json:"account_id"`
+	// the limits are unlimited when null
+	MaxSingleAmount sql.NullInt64 `json:"max_single_amount"`
+	DailyAmount     sql.NullInt64 `json:"daily_amount"`
+	MonthlyAmount   sql.NullInt64 `json:"monthly_amount"`
+	HourlyCount     sql.NullInt64 `json:"hourly_count"`
+	// Audit.Actor is the username of the banker setting the limits
+	Audit AuditInfo `json:"audit"`
+}
+
+type SetTransferLimitTxResult struct {
+	Limit TransferLimit `json:"limit"`
+}
+
+// SetTransferLimitTx replaces the transfer limits of a user or an account and records the change in the audit log
+func (store *SQLStore) SetTransferLimitTx(ctx context.Context, arg SetTransferLimitTxParams) (SetTransferLimitTxResult, error) {
+	var result SetTransferLimitTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		var limit TransferLimit
+		var err error
+
+		// the transfers lock the owner and the accounts before reading their limits,
+		// so locking them here keeps the limits from changing under a transfer
+		if arg.Username != "" {
+			_, err = q.GetUserForUpdate(ctx, arg.Username)
+			if err != nil {
+				return err
+			}
+
+			limit, err = q.GetUserTransferLimit(ctx, arg.Username)
+		} else {
+			_, err = q.GetAccountForUpdate(ctx, arg.AccountID)
+			if err != nil {
+				return err
+			}
+
+			limit, err = q.GetAccountTransferLimit(ctx, arg.AccountID)
+		}
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+
+		var before interface{}
+		if err == nil {
+			before = limit
+		}
+
+		if arg.Username != "" {
+			result.Limit, err = q.UpsertUserTransferLimit(ctx, UpsertUserTransferLimitParams{
+				Username:        sql.NullString{String: arg.Username, Valid: true},
+				MaxSingleAmount: arg.MaxSingleAmount,
+				DailyAmount:     arg.DailyAmount,
+				MonthlyAmount:   arg.MonthlyAmount,
+				HourlyCount:     arg.HourlyCount,
+			})
+		} else {
+			result.Limit, err = q.UpsertAccountTransferLimit(ctx, UpsertAccountTransferLimitParams{
+				AccountID:       sql.NullInt64{Int64: arg.AccountID, Valid: true},
+				MaxSingleAmount: arg.MaxSingleAmount,
+				DailyAmount:     arg.DailyAmount,
+				MonthlyAmount:   arg.MonthlyAmount,
+				HourlyCount:     arg.HourlyCount,
+			})
+		}
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, arg.Audit, AuditActionTransferLimitSet, AuditTargetTransferLimit, auditID(result.Limit.ID), before, result.Limit)
+	})
+
+	return result, err
+}
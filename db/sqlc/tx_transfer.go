@@ -1,11 +1,27 @@
 package db
 
-import "context"
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/Dejan91/simple_bank/metrics"
+	"github.com/lib/pq"
+	"strings"
+	"time"
+)
+
+// ErrInsufficientFunds is returned when a transfer or hold exceeds the available balance of the source account
+var ErrInsufficientFunds = errors.New("insufficient funds")
 
 type TransferTxParams struct {
 	FromAccountID int64 `json:"from_account_id"`
 	ToAccountID   int64 `json:"to_account_id"`
 	Amount        int64 `json:"amount"`
+	// RiskEvaluator screens the transfer before it is executed, nil skips the screening
+	RiskEvaluator RiskEvaluator `json:"-"`
+	// Audit records who requested the transfer, the client is also screened by the risk evaluator
+	Audit AuditInfo `json:"audit"`
 }
 
 type TransferTxResult struct {
@@ -14,48 +30,290 @@ type TransferTxResult struct {
 	ToAccount   Account  `json:"to_account"`
 	FromEntry   Entry    `json:"from_entry"`
 	ToEntry     Entry    `json:"to_entry"`
+	// FeeEntry debits the fee from the source account, it is empty for free transfers
+	FeeEntry Entry `json:"fee_entry"`
+	// Review is set instead of the transfer and the entries when the risk screening holds it for review
+	Review TransferReview `json:"review"`
 }
 
+// maxTransferTxAttempts is how many times TransferTx runs when it conflicts with a concurrent transaction
+const maxTransferTxAttempts = 3
+
 func (store *SQLStore) TransferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
 	var result TransferTxResult
 
-	err := store.execTx(ctx, func(q *Queries) error {
+	err := retryTransferTx(func() error {
 		var err error
+		result, err = store.transferTx(ctx, arg)
+		return err
+	})
+	if err != nil {
+		metrics.ObserveTransferTxFailure(transferTxFailureReason(err))
+		return result, err
+	}
 
-		result.Transfer, err = q.CreateTransfer(ctx, CreateTransferParams{
-			FromAccountID: arg.FromAccountID,
-			ToAccountID:   arg.ToAccountID,
-			Amount:        arg.Amount,
-		})
+	observeTransfer(result)
+	return result, nil
+}
+
+func (store *SQLStore) transferTx(ctx context.Context, arg TransferTxParams) (TransferTxResult, error) {
+	var result TransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		now := time.Now()
+
+		fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
 		if err != nil {
 			return err
 		}
 
-		result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.FromAccountID,
-			Amount:    -arg.Amount,
-		})
+		assessment, err := screenTransfer(ctx, q, fromAccount, arg.ToAccountID, arg.Amount, arg.RiskEvaluator, arg.Audit, now)
 		if err != nil {
-			return nil
+			return err
+		}
+
+		if assessment.Decision == RiskDecisionReview {
+			result, err = submitTransferReview(ctx, q, arg, assessment)
+			if err != nil {
+				return err
+			}
+
+			return recordAudit(ctx, q, arg.Audit, AuditActionTransferHeld,
+				AuditTargetTransferReview, auditID(result.Review.ID), nil, result.Review)
 		}
 
-		result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
-			AccountID: arg.ToAccountID,
-			Amount:    arg.Amount,
+		result, err = transferWithFee(ctx, q, CreateTransferParams{
+			FromAccountID: arg.FromAccountID,
+			ToAccountID:   arg.ToAccountID,
+			Amount:        arg.Amount,
 		})
 		if err != nil {
 			return err
 		}
 
-		if arg.FromAccountID < arg.ToAccountID {
-			result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
-		} else {
-			result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
+		return recordAudit(ctx, q, arg.Audit, AuditActionTransferCreated,
+			AuditTargetTransfer, auditID(result.Transfer.ID), nil, result.Transfer)
+	})
+
+	return result, err
+}
+
+// screenTransfer enforces the transfer limits of the source account and screens the transfer
+// with the risk evaluator, a nil evaluator allows every transfer within the limits.
+// The evaluator sees the source account read after the limits locked it, not the one passed in.
+// A denied transfer returns ErrTransferDenied
+func screenTransfer(
+	ctx context.Context,
+	q *Queries,
+	fromAccount Account,
+	toAccountID int64,
+	amount int64,
+	evaluator RiskEvaluator,
+	audit AuditInfo,
+	now time.Time,
+) (RiskAssessment, error) {
+	fromAccount, err := enforceTransferLimits(ctx, q, fromAccount, toAccountID, amount, now)
+	if err != nil {
+		return RiskAssessment{}, err
+	}
+
+	if evaluator == nil {
+		return RiskAssessment{Decision: RiskDecisionAllow}, nil
+	}
+
+	assessment, err := evaluator.Evaluate(ctx, q, RiskInput{
+		FromAccount: fromAccount,
+		ToAccountID: toAccountID,
+		Amount:      amount,
+		ClientIP:    audit.ClientIP,
+		UserAgent:   audit.UserAgent,
+		Now:         now,
+	})
+	if err != nil {
+		return assessment, err
+	}
+
+	if assessment.Decision == RiskDecisionDeny {
+		return assessment, fmt.Errorf("%w: %s", ErrTransferDenied, strings.Join(assessment.Reasons, ", "))
+	}
+
+	return assessment, nil
+}
+
+// retryTransferTx runs the transaction again when it fails because of a concurrent transaction,
+// a deadlock or a serialization failure rolls back the whole transaction so it is safe to run it again
+func retryTransferTx(run func() error) error {
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		err = run()
+		if err == nil || !isConflictTxError(err) || attempt == maxTransferTxAttempts {
+			return err
 		}
 
-		return err
+		metrics.ObserveTransferTxRetry()
+	}
+}
+
+// isConflictTxError tells whether the transaction failed because of a concurrent transaction
+func isConflictTxError(err error) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	switch pqErr.Code.Name() {
+	case "serialization_failure", "deadlock_detected":
+		return true
+	}
+
+	return false
+}
+
+// transferTxFailureReason maps the error of a failed transfer to a short reason for the metrics
+func transferTxFailureReason(err error) string {
+	switch {
+	case errors.Is(err, ErrInsufficientFunds):
+		return "insufficient_funds"
+	case errors.Is(err, ErrTransferLimitExceeded):
+		return "limit_exceeded"
+	case errors.Is(err, ErrTransferDenied):
+		return "denied"
+	case errors.Is(err, ErrAccountNotActive):
+		return "account_not_active"
+	case errors.Is(err, sql.ErrNoRows):
+		return "not_found"
+	case isConflictTxError(err):
+		return "conflict"
+	}
+
+	return "error"
+}
+
+// observeTransfer records a committed transfer in the metrics, transfers held for review are skipped
+func observeTransfer(result TransferTxResult) {
+	if result.Transfer.ID == 0 {
+		return
+	}
+
+	metrics.ObserveTransfer(result.FromAccount.Currency, result.Transfer.Amount)
+}
+
+// customerTransfer moves money on behalf of the owner of the source account,
+// enforcing the transfer limits and charging the transfer fee. It skips the risk screening,
+// so it only executes transfers already screened, e.g. the ones approved by a reviewer
+func customerTransfer(ctx context.Context, q *Queries, arg CreateTransferParams) (TransferTxResult, error) {
+	fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	_, err = enforceTransferLimits(ctx, q, fromAccount, arg.ToAccountID, arg.Amount, time.Now())
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	return transferWithFee(ctx, q, arg)
+}
+
+// transferWithFee moves money between two accounts, charges the transfer fee to the source account
+// and emits the transfer.completed event
+func transferWithFee(ctx context.Context, q *Queries, arg CreateTransferParams) (TransferTxResult, error) {
+	fromAccount, err := q.GetAccount(ctx, arg.FromAccountID)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	arg.Fee, err = quoteFee(ctx, q, fromAccount.Currency, arg.Amount)
+	if err != nil {
+		return TransferTxResult{}, err
+	}
+
+	return transferChargingFee(ctx, q, arg)
+}
+
+// transferChargingFee moves money between two accounts and charges the fee set in the params,
+// which was quoted earlier, e.g. when the hold settled by the transfer was authorized
+func transferChargingFee(ctx context.Context, q *Queries, arg CreateTransferParams) (TransferTxResult, error) {
+	result, err := transfer(ctx, q, arg)
+	if err != nil {
+		return result, err
+	}
+
+	if arg.Fee != 0 {
+		result, err = chargeTransferFee(ctx, q, result)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	err = emitTransferEvent(ctx, q, EventTransferCompleted, result)
+	return result, err
+}
+
+// transfer moves money between two accounts using the given queries,
+// so it can be composed into bigger transactions
+func transfer(ctx context.Context, q *Queries, arg CreateTransferParams) (TransferTxResult, error) {
+	result, err := postTransfer(ctx, q, arg)
+	if err != nil {
+		return result, err
+	}
+
+	// held money is not available for transfers
+	if result.FromAccount.Balance < result.FromAccount.HeldBalance {
+		return result, ErrInsufficientFunds
+	}
+
+	err = publishAccountUpdates(ctx, q, arg.FromAccountID, arg.ToAccountID)
+	return result, err
+}
+
+// postTransfer records the transfer with its entries and updates the balances
+// without checking that the source account can afford it
+func postTransfer(ctx context.Context, q *Queries, arg CreateTransferParams) (TransferTxResult, error) {
+	var result TransferTxResult
+	var err error
+
+	result.Transfer, err = q.CreateTransfer(ctx, arg)
+	if err != nil {
+		return result, err
+	}
+
+	// the accounts are updated before the entries are created, so the account row locks
+	// are always taken in id order before the locks of the entry hash chains
+	if arg.FromAccountID < arg.ToAccountID {
+		result.FromAccount, result.ToAccount, err = addMoney(ctx, q, arg.FromAccountID, -arg.Amount, arg.ToAccountID, arg.Amount)
+	} else {
+		result.ToAccount, result.FromAccount, err = addMoney(ctx, q, arg.ToAccountID, arg.Amount, arg.FromAccountID, -arg.Amount)
+	}
+	if err != nil {
+		return result, err
+	}
+
+	// the updates lock both accounts, so their status cannot change before the transfer commits
+	if err = checkAccountActive(result.FromAccount); err != nil {
+		return result, err
+	}
+	if err = checkAccountActive(result.ToAccount); err != nil {
+		return result, err
+	}
+
+	transferID := sql.NullInt64{Int64: result.Transfer.ID, Valid: true}
+
+	result.FromEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.FromAccountID,
+		Amount:     -arg.Amount,
+		TransferID: transferID,
 	})
+	if err != nil {
+		return result, err
+	}
 
+	result.ToEntry, err = q.CreateEntry(ctx, CreateEntryParams{
+		AccountID:  arg.ToAccountID,
+		Amount:     arg.Amount,
+		TransferID: transferID,
+	})
 	return result, err
 }
 
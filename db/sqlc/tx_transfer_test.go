@@ -0,0 +1,66 @@
+package db
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsConflictTxError(t *testing.T) {
+	require.True(t, isConflictTxError(&pq.Error{Code: "40001"}))
+	require.True(t, isConflictTxError(fmt.Errorf("tx err: %w", &pq.Error{Code: "40P01"})))
+	require.False(t, isConflictTxError(&pq.Error{Code: "23505"}))
+	require.False(t, isConflictTxError(ErrInsufficientFunds))
+}
+
+func TestRetryTransferTx(t *testing.T) {
+	deadlock := &pq.Error{Code: "40P01"}
+
+	testCases := []struct {
+		name     string
+		errs     []error
+		attempts int
+		err      error
+	}{
+		{
+			name:     "OK",
+			errs:     []error{nil},
+			attempts: 1,
+		},
+		{
+			name:     "RetriedConflict",
+			errs:     []error{deadlock, deadlock, nil},
+			attempts: 3,
+		},
+		{
+			name:     "TooManyConflicts",
+			errs:     []error{deadlock, deadlock, deadlock, nil},
+			attempts: maxTransferTxAttempts,
+			err:      deadlock,
+		},
+		{
+			name:     "OtherError",
+			errs:     []error{ErrInsufficientFunds, nil},
+			attempts: 1,
+			err:      ErrInsufficientFunds,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			attempts := 0
+			err := retryTransferTx(func() error {
+				err := tc.errs[attempts]
+				attempts++
+				return err
+			})
+
+			require.ErrorIs(t, err, tc.err)
+			require.Equal(t, tc.attempts, attempts)
+		})
+	}
+}
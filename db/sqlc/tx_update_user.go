@@ -0,0 +1,33 @@
+package db
+
+import "context"
+
+type UpdateUserTxParams struct {
+	UpdateUserParams
+	Audit AuditInfo
+}
+
+type UpdateUserTxResult struct {
+	User User
+}
+
+// UpdateUserTx updates the profile or the password of a user and records the changed fields in the audit log
+func (store *SQLStore) UpdateUserTx(ctx context.Context, arg UpdateUserTxParams) (UpdateUserTxResult, error) {
+	var result UpdateUserTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		user, err := q.GetUserForUpdate(ctx, arg.Username)
+		if err != nil {
+			return err
+		}
+
+		result.User, err = q.UpdateUser(ctx, arg.UpdateUserParams)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, arg.Audit, AuditActionUserUpdated, AuditTargetUser, user.Username, user, result.User)
+	})
+
+	return result, err
+}
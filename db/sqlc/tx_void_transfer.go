@@ -0,0 +1,103 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// ErrHoldNotExpired is returned when expiring a hold before its expiry time
+var ErrHoldNotExpired = errors.New("hold has not expired yet")
+
+type VoidTransferTxParams struct {
+	HoldID int64 `json:"hold_id"`
+	// Audit.Actor is the username of the owner or banker voiding the hold
+	Audit AuditInfo `json:"audit"`
+}
+
+type VoidTransferTxResult struct {
+	Hold        Hold    `json:"hold"`
+	FromAccount Account `json:"from_account"`
+}
+
+// VoidTransferTx cancels an authorized hold and gives the amount back to the available balance
+func (store *SQLStore) VoidTransferTx(ctx context.Context, arg VoidTransferTxParams) (VoidTransferTxResult, error) {
+	var result VoidTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, arg.HoldID)
+		if err != nil {
+			return err
+		}
+
+		result, err = releaseHold(ctx, q, hold, HoldStatusVoided)
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, arg.Audit, AuditActionHoldVoided, AuditTargetHold, auditID(hold.ID), hold, result.Hold)
+	})
+
+	return result, err
+}
+
+type ExpireHoldTxParams struct {
+	HoldID int64     `json:"hold_id"`
+	Now    time.Time `json:"now"`
+}
+
+// ExpireHoldTx releases an authorized hold once its expiry time has passed,
+// the release is audited as done by the system
+func (store *SQLStore) ExpireHoldTx(ctx context.Context, arg ExpireHoldTxParams) (VoidTransferTxResult, error) {
+	var result VoidTransferTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		hold, err := q.GetHoldForUpdate(ctx, arg.HoldID)
+		if err != nil {
+			return err
+		}
+
+		if arg.Now.Before(hold.ExpiresAt) {
+			return ErrHoldNotExpired
+		}
+
+		result, err = releaseHold(ctx, q, hold, HoldStatusExpired)
+		if err != nil {
+			return err
+		}
+
+		audit := AuditInfo{Actor: AuditActorSystem}
+		return recordAudit(ctx, q, audit, AuditActionHoldExpired, AuditTargetHold, auditID(hold.ID), hold, result.Hold)
+	})
+
+	return result, err
+}
+
+// releaseHold gives the amount and the fee of a locked, authorized hold back to the available balance
+func releaseHold(ctx context.Context, q *Queries, hold Hold, status string) (VoidTransferTxResult, error) {
+	var result VoidTransferTxResult
+	var err error
+
+	if hold.Status != HoldStatusAuthorized {
+		return result, ErrHoldNotAuthorized
+	}
+
+	result.FromAccount, err = q.AddAccountHeldBalance(ctx, AddAccountHeldBalanceParams{
+		ID:     hold.FromAccountID,
+		Amount: -(hold.Amount + hold.Fee),
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Hold, err = q.UpdateHoldStatus(ctx, UpdateHoldStatusParams{
+		ID:     hold.ID,
+		Status: status,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	err = publishAccountUpdates(ctx, q, hold.FromAccountID)
+	return result, err
+}
@@ -0,0 +1,212 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TaskPayoutWithdrawal is the type of the outbox task paying out a withdrawal once its debit is committed
+const TaskPayoutWithdrawal = "task:payout_withdrawal"
+
+// payoutMaxRetry keeps retrying a payout whose outcome is unknown, the rail pays a payment out once
+const payoutMaxRetry = 10
+
+type PayloadPayoutWithdrawal struct {
+	PaymentID int64 `json:"payment_id"`
+}
+
+type WithdrawTxParams struct {
+	AccountID int64 `json:"account_id"`
+	Amount    int64 `json:"amount"`
+	// Rail is the name of the payment rail which pays the money out
+	Rail string `json:"rail"`
+	// Destination is the external account the money is paid out to
+	Destination string `json:"destination"`
+	// RiskEvaluator screens the withdrawal before the account is debited, nil skips the screening
+	RiskEvaluator RiskEvaluator `json:"-"`
+	// Audit records who requested the withdrawal, the client is also screened by the risk evaluator
+	Audit AuditInfo `json:"audit"`
+}
+
+type WithdrawTxResult struct {
+	Payment  Payment          `json:"payment"`
+	Transfer TransferTxResult `json:"transfer"`
+}
+
+// WithdrawTx debits an account by transferring the money to the clearing account of the same currency
+// and records a pending withdrawal. The payout task sends the money through the rail once the debit
+// is committed, so no money leaves the bank without a recorded debit.
+// The withdrawal is checked against the transfer limits and screened for risk like a transfer
+func (store *SQLStore) WithdrawTx(ctx context.Context, arg WithdrawTxParams) (WithdrawTxResult, error) {
+	var result WithdrawTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		clearingAccount, err := getClearingAccount(ctx, q, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		account, err := q.GetAccount(ctx, arg.AccountID)
+		if err != nil {
+			return err
+		}
+
+		assessment, err := screenTransfer(ctx, q, account, clearingAccount.ID, arg.Amount, arg.RiskEvaluator, arg.Audit, time.Now())
+		if err != nil {
+			return err
+		}
+
+		// the money leaves the bank once paid out, so withdrawals flagged for review are denied
+		if assessment.Decision == RiskDecisionReview {
+			return fmt.Errorf("%w: flagged for review: %s", ErrTransferDenied, strings.Join(assessment.Reasons, ", "))
+		}
+
+		result.Transfer, err = transfer(ctx, q, CreateTransferParams{
+			FromAccountID: arg.AccountID,
+			ToAccountID:   clearingAccount.ID,
+			Amount:        arg.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Payment, err = q.CreatePayment(ctx, CreatePaymentParams{
+			AccountID:       arg.AccountID,
+			Kind:            PaymentKindWithdrawal,
+			Amount:          arg.Amount,
+			Rail:            arg.Rail,
+			ExternalAccount: arg.Destination,
+			TransferID:      sql.NullInt64{Int64: result.Transfer.Transfer.ID, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		err = enqueueOutboxTasks(ctx, q, OutboxTask{
+			Type:     TaskPayoutWithdrawal,
+			Payload:  PayloadPayoutWithdrawal{PaymentID: result.Payment.ID},
+			MaxRetry: payoutMaxRetry,
+		})
+		if err != nil {
+			return err
+		}
+
+		return recordAudit(ctx, q, arg.Audit, AuditActionWithdrawalRequested,
+			AuditTargetPayment, auditID(result.Payment.ID), nil, result.Payment)
+	})
+
+	return result, err
+}
+
+type CompleteWithdrawalTxParams struct {
+	PaymentID int64 `json:"payment_id"`
+	// ExternalRef is the reference of the payout on the rail
+	ExternalRef string `json:"external_ref"`
+}
+
+type CompleteWithdrawalTxResult struct {
+	Payment Payment `json:"payment"`
+}
+
+// CompleteWithdrawalTx records that the rail paid a pending withdrawal out and emits the withdrawal.completed event
+func (store *SQLStore) CompleteWithdrawalTx(ctx context.Context, arg CompleteWithdrawalTxParams) (CompleteWithdrawalTxResult, error) {
+	var result CompleteWithdrawalTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		payment, err := getPendingPayment(ctx, q, arg.PaymentID, PaymentKindWithdrawal)
+		if err != nil {
+			return err
+		}
+
+		result.Payment, err = q.UpdatePayment(ctx, UpdatePaymentParams{
+			ID:          payment.ID,
+			Status:      PaymentStatusCompleted,
+			ExternalRef: sql.NullString{String: arg.ExternalRef, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+
+		err = recordAudit(ctx, q, AuditInfo{Actor: AuditActorSystem}, AuditActionWithdrawalCompleted,
+			AuditTargetPayment, auditID(payment.ID), payment, result.Payment)
+		if err != nil {
+			return err
+		}
+
+		account, err := q.GetAccount(ctx, payment.AccountID)
+		if err != nil {
+			return err
+		}
+
+		return emitPaymentEvent(ctx, q, EventWithdrawalCompleted, result.Payment, account)
+	})
+
+	return result, err
+}
+
+type FailWithdrawalTxParams struct {
+	PaymentID int64  `json:"payment_id"`
+	LastError string `json:"last_error"`
+}
+
+type FailWithdrawalTxResult struct {
+	Payment Payment `json:"payment"`
+	// Refund gives the debited money back from the clearing account
+	Refund TransferTxResult `json:"refund"`
+}
+
+// FailWithdrawalTx gives the money of a pending withdrawal back when the rail refuses to pay it out
+// and emits the withdrawal.failed event
+func (store *SQLStore) FailWithdrawalTx(ctx context.Context, arg FailWithdrawalTxParams) (FailWithdrawalTxResult, error) {
+	var result FailWithdrawalTxResult
+
+	err := store.execTx(ctx, func(q *Queries) error {
+		payment, err := getPendingPayment(ctx, q, arg.PaymentID, PaymentKindWithdrawal)
+		if err != nil {
+			return err
+		}
+
+		clearingAccount, err := getClearingAccount(ctx, q, payment.AccountID)
+		if err != nil {
+			return err
+		}
+
+		// the clearing account is allowed to go negative
+		result.Refund, err = postTransfer(ctx, q, CreateTransferParams{
+			FromAccountID: clearingAccount.ID,
+			ToAccountID:   payment.AccountID,
+			Amount:        payment.Amount,
+		})
+		if err != nil {
+			return err
+		}
+
+		result.Payment, err = q.UpdatePayment(ctx, UpdatePaymentParams{
+			ID:               payment.ID,
+			Status:           PaymentStatusFailed,
+			RefundTransferID: sql.NullInt64{Int64: result.Refund.Transfer.ID, Valid: true},
+			LastError:        arg.LastError,
+		})
+		if err != nil {
+			return err
+		}
+
+		err = recordAudit(ctx, q, AuditInfo{Actor: AuditActorSystem}, AuditActionWithdrawalFailed,
+			AuditTargetPayment, auditID(payment.ID), payment, result.Payment)
+		if err != nil {
+			return err
+		}
+
+		err = emitPaymentEvent(ctx, q, EventWithdrawalFailed, result.Payment, result.Refund.ToAccount)
+		if err != nil {
+			return err
+		}
+
+		return publishAccountUpdates(ctx, q, payment.AccountID)
+	})
+
+	return result, err
+}
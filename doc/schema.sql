@@ -1,6 +1,6 @@
 -- SQL dump generated using DBML (dbml-lang.org)
 -- Database: PostgreSQL
--- Generated at: 2023-06-14T07:29:49.736Z
+-- Generated at: 2026-10-20T03:51:22.930Z
 
 CREATE TABLE "users" (
   "username" varchar PRIMARY KEY,
@@ -8,7 +8,8 @@ CREATE TABLE "users" (
   "full_name" varchar NOT NULL,
   "email" varchar UNIQUE NOT NULL,
   "password_changed_at" timestamptz NOT NULL DEFAULT '0001-01-01',
-  "created_at" timestamptz NOT NULL DEFAULT (now())
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "role" varchar NOT NULL DEFAULT 'depositor'
 );
 
 CREATE TABLE "accounts" (
@@ -16,14 +17,23 @@ CREATE TABLE "accounts" (
   "owner" varchar NOT NULL,
   "balance" bigint NOT NULL,
   "currency" varchar NOT NULL,
-  "created_at" timestamptz NOT NULL DEFAULT (now())
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "held_balance" bigint NOT NULL DEFAULT 0,
+  "type" varchar NOT NULL DEFAULT 'checking',
+  "status" varchar NOT NULL DEFAULT 'active',
+  "number" varchar UNIQUE NOT NULL
 );
 
 CREATE TABLE "entries" (
   "id" bigserial PRIMARY KEY,
   "account_id" bigint NOT NULL,
   "amount" bigint NOT NULL,
-  "created_at" timestamptz NOT NULL DEFAULT (now())
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "transfer_id" bigint,
+  "prev_hash" bytea NOT NULL,
+  "hash" bytea NOT NULL,
+  "journal_id" bigint,
+  "hash_version" smallint NOT NULL DEFAULT 2
 );
 
 CREATE TABLE "transfers" (
@@ -31,7 +41,10 @@ CREATE TABLE "transfers" (
   "from_account_id" bigint NOT NULL,
   "to_account_id" bigint NOT NULL,
   "amount" bigint NOT NULL,
-  "created_at" timestamptz NOT NULL DEFAULT (now())
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "reversal_of_id" bigint,
+  "fee" bigint NOT NULL DEFAULT 0,
+  "fee_journal_id" bigint
 );
 
 CREATE TABLE "sessions" (
@@ -45,28 +58,470 @@ CREATE TABLE "sessions" (
   "created_at" timestamptz NOT NULL DEFAULT (now())
 );
 
+CREATE TABLE "scheduled_transfers" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "recurrence" varchar NOT NULL,
+  "start_at" timestamptz NOT NULL,
+  "end_at" timestamptz,
+  "next_run_at" timestamptz NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'active',
+  "failure_count" int NOT NULL DEFAULT 0,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "scheduled_transfer_executions" (
+  "id" bigserial PRIMARY KEY,
+  "scheduled_transfer_id" bigint NOT NULL,
+  "transfer_id" bigint,
+  "status" varchar NOT NULL,
+  "error" varchar NOT NULL DEFAULT '',
+  "executed_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "holds" (
+  "id" bigserial PRIMARY KEY,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "fee" bigint NOT NULL DEFAULT 0,
+  "status" varchar NOT NULL DEFAULT 'authorized',
+  "transfer_id" bigint,
+  "expires_at" timestamptz NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "payments" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "kind" varchar NOT NULL,
+  "amount" bigint NOT NULL,
+  "rail" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "external_account" varchar NOT NULL DEFAULT '',
+  "external_ref" varchar NOT NULL DEFAULT '',
+  "transfer_id" bigint,
+  "refund_transfer_id" bigint,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "reconciliation_runs" (
+  "id" bigserial PRIMARY KEY,
+  "accounts_checked" bigint NOT NULL,
+  "transfers_checked" bigint NOT NULL,
+  "account_discrepancies" bigint NOT NULL,
+  "transfer_discrepancies" bigint NOT NULL,
+  "details" jsonb NOT NULL,
+  "started_at" timestamptz NOT NULL,
+  "finished_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "journals" (
+  "id" bigserial PRIMARY KEY,
+  "description" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "fee_tiers" (
+  "id" bigserial PRIMARY KEY,
+  "currency" varchar NOT NULL,
+  "min_amount" bigint NOT NULL DEFAULT 0,
+  "flat_fee" bigint NOT NULL DEFAULT 0,
+  "percentage_bps" bigint NOT NULL DEFAULT 0,
+  "min_fee" bigint NOT NULL DEFAULT 0,
+  "max_fee" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "interest_accruals" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "accrual_date" date NOT NULL,
+  "balance" bigint NOT NULL,
+  "apr_bps" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "transfer_limits" (
+  "id" bigserial PRIMARY KEY,
+  "username" varchar UNIQUE,
+  "account_id" bigint UNIQUE,
+  "max_single_amount" bigint,
+  "daily_amount" bigint,
+  "monthly_amount" bigint,
+  "hourly_count" bigint,
+  "updated_at" timestamptz NOT NULL DEFAULT (now()),
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "transfer_reviews" (
+  "id" bigserial PRIMARY KEY,
+  "from_account_id" bigint NOT NULL,
+  "to_account_id" bigint NOT NULL,
+  "amount" bigint NOT NULL,
+  "reasons" varchar NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "reviewed_by" varchar,
+  "reviewed_at" timestamptz,
+  "transfer_id" bigint,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "account_status_changes" (
+  "id" bigserial PRIMARY KEY,
+  "account_id" bigint NOT NULL,
+  "from_status" varchar NOT NULL,
+  "to_status" varchar NOT NULL,
+  "reason" varchar NOT NULL,
+  "changed_by" varchar NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "payees" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "nickname" varchar NOT NULL,
+  "account_id" bigint NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "audit_events" (
+  "id" bigserial PRIMARY KEY,
+  "actor" varchar NOT NULL,
+  "action" varchar NOT NULL,
+  "target_type" varchar NOT NULL,
+  "target_id" varchar NOT NULL,
+  "before" jsonb NOT NULL DEFAULT '{}',
+  "after" jsonb NOT NULL DEFAULT '{}',
+  "client_ip" varchar NOT NULL DEFAULT '',
+  "user_agent" varchar NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "outbox" (
+  "id" bigserial PRIMARY KEY,
+  "task_type" varchar NOT NULL,
+  "payload" jsonb NOT NULL,
+  "queue" varchar NOT NULL,
+  "max_retry" integer NOT NULL,
+  "process_at" timestamptz NOT NULL,
+  "attempts" integer NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "sent_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now()),
+  "trace_context" jsonb NOT NULL DEFAULT '{}',
+  "request_id" varchar NOT NULL DEFAULT ''
+);
+
+CREATE TABLE "domain_events" (
+  "id" bigserial PRIMARY KEY,
+  "event_type" varchar NOT NULL,
+  "username" varchar NOT NULL,
+  "data" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_subscriptions" (
+  "id" bigserial PRIMARY KEY,
+  "owner" varchar NOT NULL,
+  "url" varchar NOT NULL,
+  "secret" varchar NOT NULL,
+  "event_types" varchar NOT NULL DEFAULT '',
+  "is_active" boolean NOT NULL DEFAULT true,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
+CREATE TABLE "webhook_deliveries" (
+  "id" bigserial PRIMARY KEY,
+  "subscription_id" bigint NOT NULL,
+  "event_id" bigint NOT NULL,
+  "status" varchar NOT NULL DEFAULT 'pending',
+  "attempts" integer NOT NULL DEFAULT 0,
+  "response_status" integer NOT NULL DEFAULT 0,
+  "last_error" varchar NOT NULL DEFAULT '',
+  "delivered_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT (now())
+);
+
 CREATE INDEX ON "accounts" ("owner");
 
-CREATE UNIQUE INDEX ON "accounts" ("owner", "currency");
+CREATE UNIQUE INDEX ON "accounts" ("owner", "currency", "type");
 
 CREATE INDEX ON "entries" ("account_id");
 
+CREATE INDEX ON "entries" ("transfer_id");
+
+CREATE INDEX ON "entries" ("journal_id");
+
 CREATE INDEX ON "transfers" ("from_account_id");
 
 CREATE INDEX ON "transfers" ("to_account_id");
 
 CREATE INDEX ON "transfers" ("from_account_id", "to_account_id");
 
+CREATE INDEX ON "transfers" ("reversal_of_id");
+
+CREATE INDEX ON "transfers" ("from_account_id", "created_at");
+
+CREATE INDEX ON "sessions" ("username", "created_at");
+
+CREATE INDEX ON "scheduled_transfers" ("owner");
+
+CREATE INDEX ON "scheduled_transfers" ("status", "next_run_at");
+
+CREATE INDEX ON "scheduled_transfer_executions" ("scheduled_transfer_id");
+
+CREATE INDEX ON "holds" ("from_account_id");
+
+CREATE INDEX ON "holds" ("to_account_id");
+
+CREATE INDEX ON "holds" ("status", "expires_at");
+
+CREATE INDEX ON "payments" ("account_id");
+
+CREATE UNIQUE INDEX ON "payments" ("rail", "external_ref");
+
+CREATE INDEX ON "payments" ("status", "created_at");
+
+CREATE INDEX ON "reconciliation_runs" ("started_at");
+
+CREATE UNIQUE INDEX ON "fee_tiers" ("currency", "min_amount");
+
+CREATE UNIQUE INDEX ON "interest_accruals" ("account_id", "accrual_date");
+
+CREATE INDEX ON "interest_accruals" ("transfer_id");
+
+CREATE INDEX ON "transfer_reviews" ("status", "created_at");
+
+CREATE INDEX ON "transfer_reviews" ("from_account_id");
+
+CREATE INDEX ON "account_status_changes" ("account_id");
+
+CREATE UNIQUE INDEX ON "payees" ("owner", "nickname");
+
+CREATE UNIQUE INDEX ON "payees" ("owner", "account_id");
+
+CREATE INDEX ON "audit_events" ("created_at");
+
+CREATE INDEX ON "audit_events" ("actor", "created_at");
+
+CREATE INDEX ON "audit_events" ("action", "created_at");
+
+CREATE INDEX ON "audit_events" ("target_type", "target_id", "created_at");
+
+CREATE INDEX ON "outbox" ("id");
+
+CREATE INDEX ON "domain_events" ("username", "created_at");
+
+CREATE INDEX ON "webhook_subscriptions" ("owner");
+
+CREATE INDEX ON "webhook_deliveries" ("subscription_id", "created_at");
+
+CREATE INDEX ON "webhook_deliveries" ("event_id");
+
+COMMENT ON COLUMN "users"."role" IS 'depositor or banker';
+
+COMMENT ON COLUMN "accounts"."held_balance" IS 'sum of the authorized holds with their fees and of the transfers pending review, the available balance is balance - held_balance';
+
+COMMENT ON COLUMN "accounts"."type" IS 'checking or savings';
+
+COMMENT ON COLUMN "accounts"."status" IS 'active, frozen or closed';
+
+COMMENT ON COLUMN "accounts"."number" IS 'IBAN-style number with check digits to share instead of the id';
+
 COMMENT ON COLUMN "entries"."amount" IS 'can be negative or positive';
 
+COMMENT ON COLUMN "entries"."transfer_id" IS 'transfer which posted the entry';
+
+COMMENT ON COLUMN "entries"."prev_hash" IS 'hash of the previous entry of the account, empty for the first one';
+
+COMMENT ON COLUMN "entries"."hash" IS 'sha256 of prev_hash and the entry contents';
+
+COMMENT ON COLUMN "entries"."journal_id" IS 'journal which posted the entry';
+
+COMMENT ON COLUMN "entries"."hash_version" IS '1 for the hashes of the contents without the journal, 2 for the hashes covering it';
+
 COMMENT ON COLUMN "transfers"."amount" IS 'must be positive';
 
+COMMENT ON COLUMN "transfers"."reversal_of_id" IS 'the transfer refunded by this one';
+
+COMMENT ON COLUMN "transfers"."fee" IS 'charged to the sender on top of the amount';
+
+COMMENT ON COLUMN "transfers"."fee_journal_id" IS 'journal which moved the fee to the revenue account, or part of it back for a reversal';
+
+COMMENT ON COLUMN "scheduled_transfers"."recurrence" IS 'cron expression or @every interval';
+
+COMMENT ON COLUMN "scheduled_transfers"."status" IS 'active, paused, completed or cancelled';
+
+COMMENT ON COLUMN "scheduled_transfers"."failure_count" IS 'consecutive insufficient funds failures';
+
+COMMENT ON COLUMN "scheduled_transfer_executions"."status" IS 'succeeded, failed or held for review';
+
+COMMENT ON COLUMN "holds"."amount" IS 'must be positive';
+
+COMMENT ON COLUMN "holds"."fee" IS 'quoted when authorized, held with the amount and charged on capture';
+
+COMMENT ON COLUMN "holds"."status" IS 'authorized, captured, voided or expired';
+
+COMMENT ON COLUMN "payments"."kind" IS 'deposit or withdrawal';
+
+COMMENT ON COLUMN "payments"."amount" IS 'must be positive';
+
+COMMENT ON COLUMN "payments"."status" IS 'pending, completed or failed';
+
+COMMENT ON COLUMN "payments"."external_account" IS 'funding source of a deposit or destination of a withdrawal';
+
+COMMENT ON COLUMN "payments"."external_ref" IS 'reference of the payment on the rail, empty until the rail accepts it';
+
+COMMENT ON COLUMN "payments"."transfer_id" IS 'transfer crediting a deposit once collected, or debiting a withdrawal before it is paid out';
+
+COMMENT ON COLUMN "payments"."refund_transfer_id" IS 'transfer giving the money of a failed withdrawal back';
+
+COMMENT ON COLUMN "reconciliation_runs"."details" IS 'mismatching accounts and transfers';
+
+COMMENT ON COLUMN "fee_tiers"."min_amount" IS 'smallest transfer amount the tier applies to';
+
+COMMENT ON COLUMN "fee_tiers"."percentage_bps" IS 'percentage of the amount in basis points';
+
+COMMENT ON COLUMN "fee_tiers"."max_fee" IS 'no maximum when null';
+
+COMMENT ON COLUMN "interest_accruals"."balance" IS 'balance the interest accrued on';
+
+COMMENT ON COLUMN "interest_accruals"."apr_bps" IS 'annual percentage rate in basis points';
+
+COMMENT ON COLUMN "interest_accruals"."amount" IS 'interest of the day rounded half to even';
+
+COMMENT ON COLUMN "interest_accruals"."transfer_id" IS 'monthly transfer which paid the interest, null until then';
+
+COMMENT ON COLUMN "transfer_limits"."username" IS 'limits all the accounts of the user together, null for account limits';
+
+COMMENT ON COLUMN "transfer_limits"."account_id" IS 'limits a single account, null for user limits';
+
+COMMENT ON COLUMN "transfer_limits"."max_single_amount" IS 'the limits are unlimited when null';
+
+COMMENT ON COLUMN "transfer_limits"."daily_amount" IS 'total amount per calendar day in UTC';
+
+COMMENT ON COLUMN "transfer_limits"."monthly_amount" IS 'total amount per calendar month in UTC';
+
+COMMENT ON COLUMN "transfer_limits"."hourly_count" IS 'number of transfers in the last hour';
+
+COMMENT ON COLUMN "transfer_reviews"."amount" IS 'held on the source account while pending';
+
+COMMENT ON COLUMN "transfer_reviews"."reasons" IS 'risk rules which flagged the transfer';
+
+COMMENT ON COLUMN "transfer_reviews"."status" IS 'pending, approved or rejected';
+
+COMMENT ON COLUMN "transfer_reviews"."transfer_id" IS 'transfer executed on approval';
+
+COMMENT ON COLUMN "account_status_changes"."changed_by" IS 'banker or owner who changed the status';
+
+COMMENT ON COLUMN "payees"."nickname" IS 'name the owner gave to the payee';
+
+COMMENT ON COLUMN "audit_events"."actor" IS 'username of the user who did the action, kept without a foreign key so the log is append only';
+
+COMMENT ON COLUMN "audit_events"."action" IS 'like user.updated or account.closed';
+
+COMMENT ON COLUMN "audit_events"."before" IS 'changed fields before the action, empty for creations';
+
+COMMENT ON COLUMN "audit_events"."after" IS 'changed fields after the action';
+
+COMMENT ON COLUMN "outbox"."process_at" IS 'when the task must be processed, it is published to the queue right away';
+
+COMMENT ON COLUMN "outbox"."attempts" IS 'failed attempts to publish the task';
+
+COMMENT ON COLUMN "outbox"."sent_at" IS 'when the task was published to the queue';
+
+COMMENT ON COLUMN "outbox"."trace_context" IS 'W3C trace context of the transaction which wrote the message, continued by its task';
+
+COMMENT ON COLUMN "outbox"."request_id" IS 'ID of the request which wrote the message, empty for the background tasks';
+
+COMMENT ON COLUMN "domain_events"."event_type" IS 'like transfer.completed';
+
+COMMENT ON COLUMN "domain_events"."username" IS 'user notified of the event';
+
+COMMENT ON COLUMN "webhook_subscriptions"."secret" IS 'key signing the payloads with HMAC-SHA256';
+
+COMMENT ON COLUMN "webhook_subscriptions"."event_types" IS 'comma separated event types to deliver, all of them when empty';
+
+COMMENT ON COLUMN "webhook_deliveries"."status" IS 'pending, succeeded, failed or replayed by a new delivery';
+
+COMMENT ON COLUMN "webhook_deliveries"."response_status" IS 'HTTP status of the last attempt, 0 when no response was received';
+
 ALTER TABLE "accounts" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
 
 ALTER TABLE "entries" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
 
+ALTER TABLE "entries" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "entries" ADD FOREIGN KEY ("journal_id") REFERENCES "journals" ("id");
+
 ALTER TABLE "transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
 
 ALTER TABLE "transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
 
+ALTER TABLE "transfers" ADD FOREIGN KEY ("reversal_of_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfers" ADD FOREIGN KEY ("fee_journal_id") REFERENCES "journals" ("id");
+
 ALTER TABLE "sessions" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfers" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "scheduled_transfer_executions" ADD FOREIGN KEY ("scheduled_transfer_id") REFERENCES "scheduled_transfers" ("id");
+
+ALTER TABLE "scheduled_transfer_executions" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "holds" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "payments" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "payments" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "payments" ADD FOREIGN KEY ("refund_transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "interest_accruals" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_limits" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_reviews" ADD FOREIGN KEY ("from_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_reviews" ADD FOREIGN KEY ("to_account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "transfer_reviews" ADD FOREIGN KEY ("reviewed_by") REFERENCES "users" ("username");
+
+ALTER TABLE "transfer_reviews" ADD FOREIGN KEY ("transfer_id") REFERENCES "transfers" ("id");
+
+ALTER TABLE "account_status_changes" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "account_status_changes" ADD FOREIGN KEY ("changed_by") REFERENCES "users" ("username");
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "payees" ADD FOREIGN KEY ("account_id") REFERENCES "accounts" ("id");
+
+ALTER TABLE "domain_events" ADD FOREIGN KEY ("username") REFERENCES "users" ("username");
+
+ALTER TABLE "webhook_subscriptions" ADD FOREIGN KEY ("owner") REFERENCES "users" ("username");
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("subscription_id") REFERENCES "webhook_subscriptions" ("id");
+
+ALTER TABLE "webhook_deliveries" ADD FOREIGN KEY ("event_id") REFERENCES "domain_events" ("id");
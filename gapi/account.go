@@ -0,0 +1,106 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// validAccount loads an account and checks that it holds the given currency
+func (s *Server) validAccount(ctx context.Context, accountID int64, currency string) (db.Account, error) {
+	account, err := s.store.GetAccount(ctx, accountID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return account, status.Errorf(codes.NotFound, "account [%d] not found", accountID)
+		}
+		return account, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	if account.Currency != currency {
+		return account, status.Errorf(codes.InvalidArgument, "account [%d] currency mismatch: %s vs %s", account.ID, account.Currency, currency)
+	}
+
+	return account, nil
+}
+
+// findAccount loads an account by its id, or by its number when the id is zero
+func (s *Server) findAccount(ctx context.Context, accountID int64, accountNumber string) (db.Account, error) {
+	var account db.Account
+	var err error
+
+	if accountID != 0 {
+		account, err = s.store.GetAccount(ctx, accountID)
+	} else {
+		account, err = s.store.GetAccountByNumber(ctx, accountNumber)
+	}
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return account, status.Errorf(codes.NotFound, "account not found")
+		}
+		return account, status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	return account, nil
+}
+
+// validDestination loads the destination account of a transfer by its id or its number,
+// and checks that it holds the given currency. The destination usually belongs to someone else,
+// so a missing account and a currency mismatch return the same error, which tells nothing about the account
+func (s *Server) validDestination(ctx context.Context, accountID int64, accountNumber string, currency string) (db.Account, error) {
+	account, err := s.findAccount(ctx, accountID, accountNumber)
+	if err != nil {
+		if status.Code(err) == codes.NotFound {
+			return account, status.Errorf(codes.InvalidArgument, "invalid destination")
+		}
+		return account, err
+	}
+
+	if account.Currency != currency {
+		return account, status.Errorf(codes.InvalidArgument, "invalid destination")
+	}
+
+	return account, nil
+}
+
+// validateDestination checks that the destination of a transfer is given either by its account id or by its number
+func validateDestination(accountID int64, accountNumber string) (violations []*errdetails.BadRequest_FieldViolation) {
+	switch {
+	case accountID != 0 && accountNumber != "":
+		violations = append(violations, fieldViolation("to_account_number", fmt.Errorf("cannot be set together with to_account_id")))
+	case accountNumber != "":
+		if err := val.ValidateAccountNumber(accountNumber); err != nil {
+			violations = append(violations, fieldViolation("to_account_number", err))
+		}
+	default:
+		if err := val.ValidateID(accountID); err != nil {
+			violations = append(violations, fieldViolation("to_account_id", err))
+		}
+	}
+
+	return violations
+}
+
+// changeAccountStatus changes the status of an account on behalf of the authenticated user
+func (s *Server) changeAccountStatus(ctx context.Context, arg db.ChangeAccountStatusTxParams) (db.Account, error) {
+	result, err := s.store.ChangeAccountStatusTx(ctx, arg)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return result.Account, status.Errorf(codes.NotFound, "account [%d] not found", arg.AccountID)
+		}
+		if errors.Is(err, db.ErrAccountFrozenByBanker) {
+			return result.Account, status.Errorf(codes.PermissionDenied, "cannot change account status: %s", err)
+		}
+		if errors.Is(err, db.ErrInvalidAccountStatusChange) || errors.Is(err, db.ErrAccountNotEmpty) {
+			return result.Account, status.Errorf(codes.FailedPrecondition, "cannot change account status: %s", err)
+		}
+		return result.Account, status.Errorf(codes.Internal, "failed to change account status: %s", err)
+	}
+
+	return result.Account, nil
+}
@@ -24,7 +24,11 @@ func (s *Server) authorizeUser(ctx context.Context) (*token.Payload, error) {
 		return nil, fmt.Errorf("missing authorization header")
 	}
 
-	authHeader := values[0]
+	return verifyAuthorizationHeader(s.tokenMaker, values[0])
+}
+
+// verifyAuthorizationHeader verifies the bearer access token of an authorization header
+func verifyAuthorizationHeader(tokenMaker token.Maker, authHeader string) (*token.Payload, error) {
 	fields := strings.Fields(authHeader)
 	if len(fields) < 2 {
 		return nil, fmt.Errorf("invalid authorization header format")
@@ -36,7 +40,7 @@ func (s *Server) authorizeUser(ctx context.Context) (*token.Payload, error) {
 	}
 
 	accessToken := fields[1]
-	payload, err := s.tokenMaker.VerifyToken(accessToken)
+	payload, err := tokenMaker.VerifyToken(accessToken)
 	if err != nil {
 		return nil, fmt.Errorf("invalid access token: %s", err)
 	}
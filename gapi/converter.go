@@ -4,6 +4,7 @@ import (
 	db "github.com/Dejan91/simple_bank/db/sqlc"
 	"github.com/Dejan91/simple_bank/pb"
 	"google.golang.org/protobuf/types/known/timestamppb"
+	"strings"
 )
 
 func convertUser(user db.User) *pb.User {
@@ -15,3 +16,205 @@ func convertUser(user db.User) *pb.User {
 		CreatedAt:         timestamppb.New(user.CreatedAt),
 	}
 }
+
+func convertAccount(account db.Account) *pb.Account {
+	return &pb.Account{
+		Id:               account.ID,
+		Owner:            account.Owner,
+		Balance:          account.Balance,
+		Currency:         account.Currency,
+		CreatedAt:        timestamppb.New(account.CreatedAt),
+		AvailableBalance: account.Balance - account.HeldBalance,
+		Type:             account.Type,
+		Status:           account.Status,
+		Number:           account.Number,
+	}
+}
+
+func convertEntry(entry db.Entry) *pb.Entry {
+	return &pb.Entry{
+		Id:        entry.ID,
+		AccountId: entry.AccountID,
+		Amount:    entry.Amount,
+		CreatedAt: timestamppb.New(entry.CreatedAt),
+	}
+}
+
+func convertScheduledTransfer(schedule db.ScheduledTransfer) *pb.ScheduledTransfer {
+	rsp := &pb.ScheduledTransfer{
+		Id:            schedule.ID,
+		Owner:         schedule.Owner,
+		FromAccountId: schedule.FromAccountID,
+		ToAccountId:   schedule.ToAccountID,
+		Amount:        schedule.Amount,
+		Recurrence:    schedule.Recurrence,
+		StartAt:       timestamppb.New(schedule.StartAt),
+		NextRunAt:     timestamppb.New(schedule.NextRunAt),
+		Status:        schedule.Status,
+		FailureCount:  schedule.FailureCount,
+		CreatedAt:     timestamppb.New(schedule.CreatedAt),
+	}
+
+	if schedule.EndAt.Valid {
+		rsp.EndAt = timestamppb.New(schedule.EndAt.Time)
+	}
+
+	return rsp
+}
+
+func convertScheduledTransferExecution(execution db.ScheduledTransferExecution) *pb.ScheduledTransferExecution {
+	return &pb.ScheduledTransferExecution{
+		Id:                  execution.ID,
+		ScheduledTransferId: execution.ScheduledTransferID,
+		TransferId:          execution.TransferID.Int64,
+		Status:              execution.Status,
+		Error:               execution.Error,
+		ExecutedAt:          timestamppb.New(execution.ExecutedAt),
+	}
+}
+
+func convertTransfer(transfer db.Transfer) *pb.Transfer {
+	return &pb.Transfer{
+		Id:            transfer.ID,
+		FromAccountId: transfer.FromAccountID,
+		ToAccountId:   transfer.ToAccountID,
+		Amount:        transfer.Amount,
+		CreatedAt:     timestamppb.New(transfer.CreatedAt),
+		ReversalOfId:  transfer.ReversalOfID.Int64,
+		Fee:           transfer.Fee,
+	}
+}
+
+func convertTransferReview(review db.TransferReview) *pb.TransferReview {
+	rsp := &pb.TransferReview{
+		Id:            review.ID,
+		FromAccountId: review.FromAccountID,
+		ToAccountId:   review.ToAccountID,
+		Amount:        review.Amount,
+		Reasons:       review.Reasons,
+		Status:        review.Status,
+		ReviewedBy:    review.ReviewedBy.String,
+		TransferId:    review.TransferID.Int64,
+		CreatedAt:     timestamppb.New(review.CreatedAt),
+	}
+
+	if review.ReviewedAt.Valid {
+		rsp.ReviewedAt = timestamppb.New(review.ReviewedAt.Time)
+	}
+
+	return rsp
+}
+
+func convertPayee(payee db.Payee, account db.Account) *pb.Payee {
+	return &pb.Payee{
+		Id:            payee.ID,
+		Nickname:      payee.Nickname,
+		AccountId:     payee.AccountID,
+		AccountNumber: account.Number,
+		Currency:      account.Currency,
+		CreatedAt:     timestamppb.New(payee.CreatedAt),
+	}
+}
+
+func convertPayeeRow(payee db.ListPayeesRow) *pb.Payee {
+	return &pb.Payee{
+		Id:            payee.ID,
+		Nickname:      payee.Nickname,
+		AccountId:     payee.AccountID,
+		AccountNumber: payee.AccountNumber,
+		Currency:      payee.Currency,
+		CreatedAt:     timestamppb.New(payee.CreatedAt),
+	}
+}
+
+func convertHold(hold db.Hold) *pb.Hold {
+	return &pb.Hold{
+		Id:            hold.ID,
+		FromAccountId: hold.FromAccountID,
+		ToAccountId:   hold.ToAccountID,
+		Amount:        hold.Amount,
+		Fee:           hold.Fee,
+		Status:        hold.Status,
+		TransferId:    hold.TransferID.Int64,
+		ExpiresAt:     timestamppb.New(hold.ExpiresAt),
+		CreatedAt:     timestamppb.New(hold.CreatedAt),
+	}
+}
+
+func convertPayment(payment db.Payment) *pb.Payment {
+	return &pb.Payment{
+		Id:          payment.ID,
+		AccountId:   payment.AccountID,
+		Kind:        payment.Kind,
+		Amount:      payment.Amount,
+		Rail:        payment.Rail,
+		ExternalRef: payment.ExternalRef,
+		TransferId:  payment.TransferID.Int64,
+		CreatedAt:   timestamppb.New(payment.CreatedAt),
+		Status:      payment.Status,
+		LastError:   payment.LastError,
+	}
+}
+
+func convertTransferLimits(usages []db.TransferLimitUsage) []*pb.TransferLimit {
+	limits := make([]*pb.TransferLimit, len(usages))
+	for i, usage := range usages {
+		limits[i] = &pb.TransferLimit{
+			Name:      usage.Name,
+			Max:       usage.Max,
+			Used:      usage.Used,
+			Remaining: usage.Remaining,
+		}
+	}
+
+	return limits
+}
+
+func convertAuditEvent(event db.AuditEvent) *pb.AuditEvent {
+	return &pb.AuditEvent{
+		Id:         event.ID,
+		Actor:      event.Actor,
+		Action:     event.Action,
+		TargetType: event.TargetType,
+		TargetId:   event.TargetID,
+		Before:     string(event.Before),
+		After:      string(event.After),
+		ClientIp:   event.ClientIp,
+		UserAgent:  event.UserAgent,
+		CreatedAt:  timestamppb.New(event.CreatedAt),
+	}
+}
+
+func convertWebhookSubscription(subscription db.WebhookSubscription) *pb.WebhookSubscription {
+	rsp := &pb.WebhookSubscription{
+		Id:        subscription.ID,
+		Url:       subscription.Url,
+		IsActive:  subscription.IsActive,
+		CreatedAt: timestamppb.New(subscription.CreatedAt),
+	}
+
+	if subscription.EventTypes != "" {
+		rsp.EventTypes = strings.Split(subscription.EventTypes, ",")
+	}
+
+	return rsp
+}
+
+func convertWebhookDelivery(delivery db.WebhookDelivery) *pb.WebhookDelivery {
+	rsp := &pb.WebhookDelivery{
+		Id:             delivery.ID,
+		SubscriptionId: delivery.SubscriptionID,
+		EventId:        delivery.EventID,
+		Status:         delivery.Status,
+		Attempts:       delivery.Attempts,
+		ResponseStatus: delivery.ResponseStatus,
+		LastError:      delivery.LastError,
+		CreatedAt:      timestamppb.New(delivery.CreatedAt),
+	}
+
+	if delivery.DeliveredAt.Valid {
+		rsp.DeliveredAt = timestamppb.New(delivery.DeliveredAt.Time)
+	}
+
+	return rsp
+}
@@ -1,9 +1,13 @@
 package gapi
 
 import (
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"time"
 )
 
 func fieldViolation(field string, err error) *errdetails.BadRequest_FieldViolation {
@@ -28,3 +32,36 @@ func invalidArgumentError(violations []*errdetails.BadRequest_FieldViolation) er
 func unauthenticatedError(err error) error {
 	return status.Errorf(codes.Unauthenticated, "unauthorized: %s", err)
 }
+
+// transferLimitError tells the client which limit blocked the transfer with a quota failure detail
+func transferLimitError(limitErr *db.TransferLimitError) error {
+	quotaFailure := &errdetails.QuotaFailure{
+		Violations: []*errdetails.QuotaFailure_Violation{
+			{
+				Subject:     fmt.Sprintf("%s:%s:%s", limitErr.Scope, limitErr.Subject, limitErr.Usage.Name),
+				Description: limitErr.Error(),
+			},
+		},
+	}
+	statusExhausted := status.New(codes.ResourceExhausted, "transfer limit exceeded")
+
+	statusDetails, err := statusExhausted.WithDetails(quotaFailure)
+	if err != nil {
+		return statusExhausted.Err()
+	}
+
+	return statusDetails.Err()
+}
+
+// rateLimitError tells the client when to retry with a retry info detail
+func rateLimitError(retryAfter time.Duration) error {
+	retryInfo := &errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}
+	statusExhausted := status.New(codes.ResourceExhausted, "rate limit exceeded")
+
+	statusDetails, err := statusExhausted.WithDetails(retryInfo)
+	if err != nil {
+		return statusExhausted.Err()
+	}
+
+	return statusDetails.Err()
+}
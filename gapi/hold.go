@@ -0,0 +1,39 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/token"
+	"github.com/Dejan91/simple_bank/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// getHold loads a hold and checks that the user is a banker or owns one of its accounts
+func (s *Server) getHold(ctx context.Context, id int64, authPayload *token.Payload) (db.Hold, error) {
+	hold, err := s.store.GetHold(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return hold, status.Errorf(codes.NotFound, "hold not found")
+		}
+		return hold, status.Errorf(codes.Internal, "failed to get hold: %s", err)
+	}
+
+	if authPayload.Role == util.BankerRole {
+		return hold, nil
+	}
+
+	for _, accountID := range []int64{hold.FromAccountID, hold.ToAccountID} {
+		account, err := s.store.GetAccount(ctx, accountID)
+		if err != nil {
+			return hold, status.Errorf(codes.Internal, "failed to get account: %s", err)
+		}
+
+		if account.Owner == authPayload.Username {
+			return hold, nil
+		}
+	}
+
+	return hold, status.Errorf(codes.PermissionDenied, "hold doesn't belong to the authenticated user")
+}
@@ -2,6 +2,7 @@ package gapi
 
 import (
 	"context"
+	"github.com/Dejan91/simple_bank/redact"
 	"github.com/rs/zerolog/log"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
@@ -25,9 +26,9 @@ func GrpcLogger(
 		statusCode = st.Code()
 	}
 
-	logger := log.Info()
+	logger := log.Ctx(ctx).Info()
 	if err != nil {
-		logger = log.Error().Err(err)
+		logger = log.Ctx(ctx).Error().Err(err)
 	}
 
 	logger.
@@ -40,6 +41,36 @@ func GrpcLogger(
 	return result, err
 }
 
+func GrpcStreamLogger(
+	srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	startTime := time.Now()
+	err := handler(srv, stream)
+	duration := time.Since(startTime)
+
+	statusCode := codes.Unknown
+	if st, ok := status.FromError(err); ok {
+		statusCode = st.Code()
+	}
+
+	logger := log.Ctx(stream.Context()).Info()
+	if err != nil {
+		logger = log.Ctx(stream.Context()).Error().Err(err)
+	}
+
+	logger.
+		Str("protocol", "grpc").
+		Str("method", info.FullMethod).
+		Int("status_code", int(statusCode)).
+		Str("status_text", statusCode.String()).
+		Dur("duration", duration).
+		Msg("received a gRPC stream")
+	return err
+}
+
 type ResponseRecorder struct {
 	http.ResponseWriter
 	StatusCode int
@@ -66,9 +97,9 @@ func HttpLogger(handler http.Handler) http.Handler {
 		handler.ServeHTTP(rr, r)
 		duration := time.Since(startTime)
 
-		logger := log.Info()
+		logger := log.Ctx(r.Context()).Info()
 		if rr.StatusCode != http.StatusOK {
-			logger = log.Error().Bytes("body", rr.Body)
+			logger = log.Ctx(r.Context()).Error().Bytes("body", redact.JSON(rr.Body))
 		}
 
 		logger.
@@ -2,8 +2,11 @@ package gapi
 
 import (
 	"context"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/util"
 	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
+	"strings"
 )
 
 const (
@@ -18,7 +21,14 @@ type Metadata struct {
 }
 
 func (s *Server) extractMetadata(ctx context.Context) *Metadata {
+	return incomingMetadata(ctx, s.clientIPs)
+}
+
+// incomingMetadata resolves the user agent and the IP of the client from the incoming gRPC metadata,
+// the forwarded addresses are only believed when they come from a trusted proxy
+func incomingMetadata(ctx context.Context, clientIPs *util.ClientIPResolver) *Metadata {
 	mtdt := &Metadata{}
+	var forwardedFor []string
 
 	if md, ok := metadata.FromIncomingContext(ctx); ok {
 		if userAgents := md.Get(grpcGatewayUserAgentHeader); len(userAgents) > 0 {
@@ -29,14 +39,29 @@ func (s *Server) extractMetadata(ctx context.Context) *Metadata {
 			mtdt.UserAgent = userAgents[0]
 		}
 
-		if clientIPs := md.Get(xForwardedForHeader); len(clientIPs) > 0 {
-			mtdt.ClientIP = clientIPs[0]
-		}
+		forwardedFor = md.Get(xForwardedForHeader)
 	}
 
 	if p, ok := peer.FromContext(ctx); ok {
-		mtdt.ClientIP = p.Addr.String()
+		mtdt.ClientIP = clientIPs.Resolve(forwardedFor, p.Addr.String())
+	} else if len(forwardedFor) > 0 {
+		// the gateway calls the server in process without a peer,
+		// it appends the remote address of the HTTP request to the forwarded addresses instead
+		hops := strings.Split(strings.Join(forwardedFor, ","), ",")
+		remoteAddr := strings.TrimSpace(hops[len(hops)-1])
+		mtdt.ClientIP = clientIPs.Resolve(hops[:len(hops)-1], remoteAddr)
 	}
 
 	return mtdt
 }
+
+// auditInfo describes who does an audited action and from which client
+func (s *Server) auditInfo(ctx context.Context, username string) db.AuditInfo {
+	mtdt := s.extractMetadata(ctx)
+
+	return db.AuditInfo{
+		Actor:     username,
+		ClientIP:  mtdt.ClientIP,
+		UserAgent: mtdt.UserAgent,
+	}
+}
@@ -0,0 +1,80 @@
+package gapi
+
+import (
+	"context"
+	"github.com/Dejan91/simple_bank/metrics"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// unmatchedRoute labels the HTTP requests that are not routed to an RPC, like swagger and the metrics itself
+const unmatchedRoute = "unmatched"
+
+type routeKey struct{}
+
+func GrpcMetrics(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp interface{}, err error) {
+	startTime := time.Now()
+	result, err := handler(ctx, req)
+
+	metrics.ObserveRequest("grpc", info.FullMethod, status.Code(err).String(), time.Since(startTime))
+	return result, err
+}
+
+func GrpcStreamMetrics(
+	srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	startTime := time.Now()
+	err := handler(srv, stream)
+
+	metrics.ObserveRequest("grpc", info.FullMethod, status.Code(err).String(), time.Since(startTime))
+	return err
+}
+
+// HttpRouteAnnotator records the RPC a gateway request is routed to, so HttpMetrics can label
+// the request and its span is named by the method instead of the raw path; register it with runtime.WithMetadata
+func HttpRouteAnnotator(ctx context.Context, _ *http.Request) metadata.MD {
+	method, ok := runtime.RPCMethod(ctx)
+	if !ok {
+		return nil
+	}
+
+	trace.SpanFromContext(ctx).SetName(method)
+	recordRoute(ctx, method)
+
+	return nil
+}
+
+// recordRoute labels the HTTP request of ctx with the RPC it is routed to
+func recordRoute(ctx context.Context, method string) {
+	if route, ok := ctx.Value(routeKey{}).(*string); ok {
+		*route = method
+	}
+}
+
+func HttpMetrics(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startTime := time.Now()
+		route := unmatchedRoute
+		rr := &ResponseRecorder{
+			ResponseWriter: w,
+			StatusCode:     http.StatusOK,
+		}
+		handler.ServeHTTP(rr, r.WithContext(context.WithValue(r.Context(), routeKey{}, &route)))
+
+		metrics.ObserveRequest("http", route, strconv.Itoa(rr.StatusCode), time.Since(startTime))
+	})
+}
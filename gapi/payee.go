@@ -0,0 +1,26 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// getPayee loads a payee and checks that it belongs to the user
+func (s *Server) getPayee(ctx context.Context, id int64, username string) (db.Payee, error) {
+	payee, err := s.store.GetPayee(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return payee, status.Errorf(codes.NotFound, "payee not found")
+		}
+		return payee, status.Errorf(codes.Internal, "failed to get payee: %s", err)
+	}
+
+	if payee.Owner != username {
+		return payee, status.Errorf(codes.PermissionDenied, "payee doesn't belong to the authenticated user")
+	}
+
+	return payee, nil
+}
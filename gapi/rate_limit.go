@@ -0,0 +1,242 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/ratelimit"
+	"github.com/Dejan91/simple_bank/token"
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/api/annotations"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const retryAfterHeader = "retry-after"
+
+// RateLimiter limits the requests to the methods of the bank per client, the clients are told apart
+// by the username of their access token, or by their IP while they are not logged in
+type RateLimiter struct {
+	limiter      ratelimit.Limiter
+	defaultLimit *ratelimit.Limit
+	methodLimits map[string]ratelimit.Limit
+	tokenMaker   token.Maker
+	clientIPs    *util.ClientIPResolver
+	// routes maps the HTTP method and path of the gateway to the full gRPC method
+	routes map[string]string
+}
+
+// NewRateLimiter creates a rate limiter with the limits of the config, methods without a limit
+// use the default limit, or are not limited if there is no default limit
+func NewRateLimiter(config util.Config, limiter ratelimit.Limiter) (*RateLimiter, error) {
+	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
+	if err != nil {
+		return nil, fmt.Errorf("cannot create token maker: %w", err)
+	}
+
+	methodLimits, err := ratelimit.ParseMethodLimits(config.RateLimits)
+	if err != nil {
+		return nil, err
+	}
+
+	clientIPs, err := util.NewClientIPResolver(config.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
+	service := pb.File_service_simple_bank_proto.Services().ByName("SimpleBank")
+	for method := range methodLimits {
+		if service.Methods().ByName(protoreflect.Name(method)) == nil {
+			return nil, fmt.Errorf("rate limit for unknown method %s", method)
+		}
+	}
+
+	rateLimiter := &RateLimiter{
+		limiter:      limiter,
+		methodLimits: methodLimits,
+		tokenMaker:   tokenMaker,
+		clientIPs:    clientIPs,
+		routes:       gatewayRoutes(),
+	}
+
+	if config.RateLimitDefault != "" {
+		defaultLimit, err := ratelimit.ParseLimit(config.RateLimitDefault)
+		if err != nil {
+			return nil, err
+		}
+		rateLimiter.defaultLimit = &defaultLimit
+	}
+
+	return rateLimiter, nil
+}
+
+func (l *RateLimiter) GrpcRateLimit(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp interface{}, err error) {
+	retryAfter, ok := l.allow(ctx, info.FullMethod, l.grpcClientKey(ctx))
+	if !ok {
+		if err := grpc.SetHeader(ctx, metadata.Pairs(retryAfterHeader, retryAfterSeconds(retryAfter))); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to send retry-after header")
+		}
+		return nil, rateLimitError(retryAfter)
+	}
+
+	return handler(ctx, req)
+}
+
+func (l *RateLimiter) GrpcStreamRateLimit(
+	srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	ctx := stream.Context()
+
+	retryAfter, ok := l.allow(ctx, info.FullMethod, l.grpcClientKey(ctx))
+	if !ok {
+		if err := stream.SetHeader(metadata.Pairs(retryAfterHeader, retryAfterSeconds(retryAfter))); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to send retry-after header")
+		}
+		return rateLimitError(retryAfter)
+	}
+
+	return handler(srv, stream)
+}
+
+func (l *RateLimiter) HttpRateLimit(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		method, ok := l.routes[r.Method+" "+r.URL.Path]
+		if !ok {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		ctx := r.Context()
+		retryAfter, ok := l.allow(ctx, method, l.httpClientKey(r))
+		if ok {
+			handler.ServeHTTP(w, r)
+			return
+		}
+
+		recordRoute(ctx, method)
+
+		err := requestIDError(rateLimitError(retryAfter), util.RequestIDFromContext(ctx))
+		body, marshalErr := protojson.Marshal(status.Convert(err).Proto())
+		if marshalErr != nil {
+			log.Ctx(ctx).Error().Err(marshalErr).Msg("failed to marshal rate limit error")
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set(retryAfterHeader, retryAfterSeconds(retryAfter))
+		w.WriteHeader(http.StatusTooManyRequests)
+		if _, err := w.Write(body); err != nil {
+			log.Ctx(ctx).Error().Err(err).Msg("failed to write rate limit error")
+		}
+	})
+}
+
+// allow takes a token of the client for the method, it lets the request through when the limiter fails
+func (l *RateLimiter) allow(ctx context.Context, fullMethod string, clientKey string) (time.Duration, bool) {
+	method, ok := bankMethod(fullMethod)
+	if !ok {
+		return 0, true
+	}
+
+	limit, ok := l.methodLimits[method]
+	if !ok {
+		if l.defaultLimit == nil {
+			return 0, true
+		}
+		limit = *l.defaultLimit
+	}
+
+	result, err := l.limiter.Allow(ctx, method+":"+clientKey, limit)
+	if err != nil {
+		log.Ctx(ctx).Error().Err(err).Str("method", method).Msg("failed to check rate limit")
+		return 0, true
+	}
+
+	return result.RetryAfter, result.Allowed
+}
+
+func (l *RateLimiter) grpcClientKey(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(authorizationHeader); len(values) > 0 {
+			if payload, err := verifyAuthorizationHeader(l.tokenMaker, values[0]); err == nil {
+				return "user:" + payload.Username
+			}
+		}
+	}
+
+	return "ip:" + incomingMetadata(ctx, l.clientIPs).ClientIP
+}
+
+// httpClientKey resolves the client like the gateway and extractMetadata do,
+// from the remote address or the addresses forwarded by the trusted proxies
+func (l *RateLimiter) httpClientKey(r *http.Request) string {
+	if authHeader := r.Header.Get(authorizationHeader); authHeader != "" {
+		if payload, err := verifyAuthorizationHeader(l.tokenMaker, authHeader); err == nil {
+			return "user:" + payload.Username
+		}
+	}
+
+	return "ip:" + l.clientIPs.Resolve(r.Header.Values(xForwardedForHeader), r.RemoteAddr)
+}
+
+// bankMethod returns the name of a method of the bank service, other services like health are not limited
+func bankMethod(fullMethod string) (string, bool) {
+	prefix := "/" + pb.SimpleBank_ServiceDesc.ServiceName + "/"
+	if !strings.HasPrefix(fullMethod, prefix) {
+		return "", false
+	}
+
+	return strings.TrimPrefix(fullMethod, prefix), true
+}
+
+// gatewayRoutes reads the HTTP routes of the methods from their google.api.http options
+func gatewayRoutes() map[string]string {
+	routes := make(map[string]string)
+
+	service := pb.File_service_simple_bank_proto.Services().ByName("SimpleBank")
+	methods := service.Methods()
+	for i := 0; i < methods.Len(); i++ {
+		method := methods.Get(i)
+
+		rule, ok := proto.GetExtension(method.Options(), annotations.E_Http).(*annotations.HttpRule)
+		if !ok || rule == nil {
+			continue
+		}
+
+		fullMethod := fmt.Sprintf("/%s/%s", service.FullName(), method.Name())
+		for httpMethod, path := range map[string]string{
+			http.MethodGet:    rule.GetGet(),
+			http.MethodPost:   rule.GetPost(),
+			http.MethodPut:    rule.GetPut(),
+			http.MethodPatch:  rule.GetPatch(),
+			http.MethodDelete: rule.GetDelete(),
+		} {
+			if path != "" {
+				routes[httpMethod+" "+path] = fullMethod
+			}
+		}
+	}
+
+	return routes
+}
+
+func retryAfterSeconds(retryAfter time.Duration) string {
+	return strconv.Itoa(int(math.Max(1, math.Ceil(retryAfter.Seconds()))))
+}
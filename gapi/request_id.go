@@ -0,0 +1,145 @@
+package gapi
+
+import (
+	"context"
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"net/textproto"
+)
+
+// requestIDStream overrides the context of a stream with the one carrying the request ID
+type requestIDStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *requestIDStream) Context() context.Context {
+	return s.ctx
+}
+
+func GrpcRequestID(
+	ctx context.Context,
+	req interface{},
+	info *grpc.UnaryServerInfo,
+	handler grpc.UnaryHandler,
+) (resp interface{}, err error) {
+	id := incomingRequestID(ctx)
+	ctx = withRequestID(ctx, id)
+
+	if err := grpc.SetHeader(ctx, metadata.Pairs(util.RequestIDHeader, id)); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to send request id header")
+	}
+
+	result, err := handler(ctx, req)
+	return result, requestIDError(err, id)
+}
+
+func GrpcStreamRequestID(
+	srv interface{},
+	stream grpc.ServerStream,
+	info *grpc.StreamServerInfo,
+	handler grpc.StreamHandler,
+) error {
+	id := incomingRequestID(stream.Context())
+	ctx := withRequestID(stream.Context(), id)
+
+	if err := stream.SetHeader(metadata.Pairs(util.RequestIDHeader, id)); err != nil {
+		log.Ctx(ctx).Error().Err(err).Msg("failed to send request id header")
+	}
+
+	err := handler(srv, &requestIDStream{ServerStream: stream, ctx: ctx})
+	return requestIDError(err, id)
+}
+
+// HttpRequestID accepts the X-Request-ID of the client or generates one, and returns it in the response;
+// the gateway forwards it to the handlers as gRPC metadata
+func HttpRequestID(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(util.RequestIDHeader)
+		if !util.ValidRequestID(id) {
+			id = util.NewRequestID()
+			r.Header.Set(util.RequestIDHeader, id)
+		}
+
+		w.Header().Set(util.RequestIDHeader, id)
+		handler.ServeHTTP(w, r.WithContext(withRequestID(r.Context(), id)))
+	})
+}
+
+// HttpHeaderMatcher forwards the request ID header to the handlers besides the default headers
+func HttpHeaderMatcher(key string) (string, bool) {
+	if key == textproto.CanonicalMIMEHeaderKey(util.RequestIDHeader) {
+		return util.RequestIDHeader, true
+	}
+
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// HttpErrorHandler adds the request ID to the details of the errors returned by the gateway
+func HttpErrorHandler(
+	ctx context.Context,
+	mux *runtime.ServeMux,
+	marshaler runtime.Marshaler,
+	w http.ResponseWriter,
+	r *http.Request,
+	err error,
+) {
+	err = requestIDError(err, util.RequestIDFromContext(r.Context()))
+	runtime.DefaultHTTPErrorHandler(ctx, mux, marshaler, w, r, err)
+}
+
+// incomingRequestID returns the request ID sent by the client in the gRPC metadata, or a new one
+func incomingRequestID(ctx context.Context) string {
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if ids := md.Get(util.RequestIDHeader); len(ids) > 0 && util.ValidRequestID(ids[0]) {
+			return ids[0]
+		}
+	}
+
+	return util.NewRequestID()
+}
+
+// withRequestID stores the request ID in ctx along with a logger tagged with it, for log.Ctx
+func withRequestID(ctx context.Context, id string) context.Context {
+	ctx = util.ContextWithRequestID(ctx, id)
+
+	logger := log.With().Str("request_id", id)
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		logger = logger.Str("trace_id", spanContext.TraceID().String())
+	}
+
+	return logger.Logger().WithContext(ctx)
+}
+
+// requestIDError adds the request ID to the details of a gRPC error, so clients can report it
+func requestIDError(err error, id string) error {
+	if err == nil || id == "" {
+		return err
+	}
+
+	st := status.Convert(err)
+	if st.Code() == codes.OK {
+		return err
+	}
+
+	for _, detail := range st.Details() {
+		if _, ok := detail.(*errdetails.RequestInfo); ok {
+			return err
+		}
+	}
+
+	statusDetails, detailsErr := st.WithDetails(&errdetails.RequestInfo{RequestId: id})
+	if detailsErr != nil {
+		return err
+	}
+
+	return statusDetails.Err()
+}
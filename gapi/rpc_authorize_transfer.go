@@ -0,0 +1,112 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+const (
+	// defaultHoldDuration is how long funds are held when the request has no expiry time
+	defaultHoldDuration = 7 * 24 * time.Hour
+	// maxHoldDuration is the longest time funds can be held
+	maxHoldDuration = 30 * 24 * time.Hour
+)
+
+func (s *Server) AuthorizeTransfer(ctx context.Context, req *pb.AuthorizeTransferRequest) (*pb.AuthorizeTransferResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateAuthorizeTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	fromAccount, err := s.validAccount(ctx, req.GetFromAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "from account doesn't belong to the authenticated user")
+	}
+
+	toAccount, err := s.validDestination(ctx, req.GetToAccountId(), req.GetToAccountNumber(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	if toAccount.ID == fromAccount.ID {
+		return nil, status.Errorf(codes.InvalidArgument, "to account must differ from from account")
+	}
+
+	expiresAt := time.Now().Add(defaultHoldDuration)
+	if req.ExpiresAt != nil {
+		expiresAt = req.GetExpiresAt().AsTime()
+	}
+
+	result, err := s.store.AuthorizeTransferTx(ctx, db.AuthorizeTransferTxParams{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   toAccount.ID,
+		Amount:        req.GetAmount(),
+		ExpiresAt:     expiresAt,
+		RiskEvaluator: s.riskEvaluator,
+		Audit:         s.auditInfo(ctx, authPayload.Username),
+	})
+	if err != nil {
+		var limitErr *db.TransferLimitError
+		if errors.As(err, &limitErr) {
+			return nil, transferLimitError(limitErr)
+		}
+		if errors.Is(err, db.ErrTransferDenied) {
+			return nil, status.Errorf(codes.PermissionDenied, "cannot authorize transfer: %s", err)
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAccountNotActive) {
+			return nil, status.Errorf(codes.FailedPrecondition, "cannot authorize transfer: %s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to authorize transfer: %s", err)
+	}
+
+	rsp := &pb.AuthorizeTransferResponse{
+		Hold:        convertHold(result.Hold),
+		FromAccount: convertAccount(result.FromAccount),
+	}
+
+	return rsp, nil
+}
+
+func validateAuthorizeTransferRequest(r *pb.AuthorizeTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(r.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+
+	violations = append(violations, validateDestination(r.GetToAccountId(), r.GetToAccountNumber())...)
+
+	if err := val.ValidateAmount(r.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := val.ValidateCurrency(r.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	if r.ExpiresAt != nil {
+		expiresAt := r.GetExpiresAt().AsTime()
+		if expiresAt.Before(time.Now()) {
+			violations = append(violations, fieldViolation("expires_at", fmt.Errorf("must be in the future")))
+		} else if expiresAt.After(time.Now().Add(maxHoldDuration)) {
+			violations = append(violations, fieldViolation("expires_at", fmt.Errorf("must be within %s", maxHoldDuration)))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Server) BlockSession(ctx context.Context, req *pb.BlockSessionRequest) (*pb.BlockSessionResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateBlockSessionRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	sessionID := uuid.MustParse(req.GetSessionId())
+	session, err := s.store.GetSession(ctx, sessionID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "session not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get session: %s", err)
+	}
+
+	if authPayload.Role != util.BankerRole && session.Username != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "session doesn't belong to the authenticated user")
+	}
+
+	result, err := s.store.BlockSessionTx(ctx, db.BlockSessionTxParams{
+		ID:    sessionID,
+		Audit: s.auditInfo(ctx, authPayload.Username),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrSessionBlocked) {
+			return nil, status.Errorf(codes.FailedPrecondition, "cannot block session: %s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to block session: %s", err)
+	}
+
+	rsp := &pb.BlockSessionResponse{
+		SessionId: result.Session.ID.String(),
+		Username:  result.Session.Username,
+		IsBlocked: result.Session.IsBlocked,
+		ExpiresAt: timestamppb.New(result.Session.ExpiresAt),
+	}
+
+	return rsp, nil
+}
+
+func validateBlockSessionRequest(r *pb.BlockSessionRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if _, err := uuid.Parse(r.GetSessionId()); err != nil {
+		violations = append(violations, fieldViolation("session_id", err))
+	}
+
+	return violations
+}
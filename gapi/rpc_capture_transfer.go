@@ -0,0 +1,58 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) CaptureTransfer(ctx context.Context, req *pb.CaptureTransferRequest) (*pb.CaptureTransferResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateCaptureTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	hold, err := s.getHold(ctx, req.GetHoldId(), authPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.store.CaptureTransferTx(ctx, db.CaptureTransferTxParams{
+		HoldID: hold.ID,
+		Audit:  s.auditInfo(ctx, authPayload.Username),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrHoldNotAuthorized) ||
+			errors.Is(err, db.ErrHoldExpired) ||
+			errors.Is(err, db.ErrInsufficientFunds) ||
+			errors.Is(err, db.ErrAccountNotActive) {
+			return nil, status.Errorf(codes.FailedPrecondition, "cannot capture transfer: %s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to capture transfer: %s", err)
+	}
+
+	rsp := &pb.CaptureTransferResponse{
+		Hold:     convertHold(result.Hold),
+		Transfer: convertTransfer(result.Transfer.Transfer),
+	}
+
+	return rsp, nil
+}
+
+func validateCaptureTransferRequest(r *pb.CaptureTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(r.GetHoldId()); err != nil {
+		violations = append(violations, fieldViolation("hold_id", err))
+	}
+
+	return violations
+}
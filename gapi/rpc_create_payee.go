@@ -0,0 +1,76 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/val"
+	"github.com/lib/pq"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) CreatePayee(ctx context.Context, req *pb.CreatePayeeRequest) (*pb.CreatePayeeResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateCreatePayeeRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := s.findAccount(ctx, req.GetAccountId(), req.GetAccountNumber())
+	if err != nil {
+		return nil, err
+	}
+
+	if account.Status == db.AccountStatusClosed {
+		return nil, status.Errorf(codes.FailedPrecondition, "account [%d] is closed", account.ID)
+	}
+
+	payee, err := s.store.CreatePayee(ctx, db.CreatePayeeParams{
+		Owner:     authPayload.Username,
+		Nickname:  req.GetNickname(),
+		AccountID: account.ID,
+	})
+	if err != nil {
+		if pqErr, ok := err.(*pq.Error); ok {
+			switch pqErr.Code.Name() {
+			case "unique_violation":
+				return nil, status.Errorf(codes.AlreadyExists, "payee nickname or account already saved: %s", err)
+			}
+		}
+		return nil, status.Errorf(codes.Internal, "failed to create payee: %s", err)
+	}
+
+	rsp := &pb.CreatePayeeResponse{
+		Payee: convertPayee(payee, account),
+	}
+
+	return rsp, nil
+}
+
+func validateCreatePayeeRequest(r *pb.CreatePayeeRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateNickname(r.GetNickname()); err != nil {
+		violations = append(violations, fieldViolation("nickname", err))
+	}
+
+	switch {
+	case r.GetAccountId() != 0 && r.GetAccountNumber() != "":
+		violations = append(violations, fieldViolation("account_number", fmt.Errorf("cannot be set together with account_id")))
+	case r.GetAccountNumber() != "":
+		if err := val.ValidateAccountNumber(r.GetAccountNumber()); err != nil {
+			violations = append(violations, fieldViolation("account_number", err))
+		}
+	default:
+		if err := val.ValidateID(r.GetAccountId()); err != nil {
+			violations = append(violations, fieldViolation("account_id", err))
+		}
+	}
+
+	return violations
+}
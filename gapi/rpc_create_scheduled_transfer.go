@@ -0,0 +1,108 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+func (s *Server) CreateScheduledTransfer(ctx context.Context, req *pb.CreateScheduledTransferRequest) (*pb.CreateScheduledTransferResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateCreateScheduledTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	fromAccount, err := s.validAccount(ctx, req.GetFromAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "from account doesn't belong to the authenticated user")
+	}
+
+	toAccount, err := s.validDestination(ctx, req.GetToAccountId(), req.GetToAccountNumber(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	if toAccount.ID == fromAccount.ID {
+		return nil, status.Errorf(codes.InvalidArgument, "to account must differ from from account")
+	}
+
+	startAt := time.Now()
+	if req.StartAt != nil {
+		startAt = req.GetStartAt().AsTime()
+	}
+
+	// the recurrence has already been validated
+	recurrence, _ := util.ParseRecurrence(req.GetRecurrence())
+
+	arg := db.CreateScheduledTransferParams{
+		Owner:         authPayload.Username,
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   toAccount.ID,
+		Amount:        req.GetAmount(),
+		Recurrence:    req.GetRecurrence(),
+		StartAt:       startAt,
+		EndAt: sql.NullTime{
+			Time:  req.GetEndAt().AsTime(),
+			Valid: req.EndAt != nil,
+		},
+		NextRunAt: util.FirstRun(recurrence, startAt),
+	}
+
+	if arg.EndAt.Valid && arg.NextRunAt.After(arg.EndAt.Time) {
+		return nil, status.Errorf(codes.InvalidArgument, "schedule never runs before its end date")
+	}
+
+	schedule, err := s.store.CreateScheduledTransfer(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create scheduled transfer: %s", err)
+	}
+
+	rsp := &pb.CreateScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(schedule),
+	}
+
+	return rsp, nil
+}
+
+func validateCreateScheduledTransferRequest(r *pb.CreateScheduledTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(r.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+
+	violations = append(violations, validateDestination(r.GetToAccountId(), r.GetToAccountNumber())...)
+
+	if err := val.ValidateAmount(r.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := val.ValidateCurrency(r.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	if err := val.ValidateRecurrence(r.GetRecurrence()); err != nil {
+		violations = append(violations, fieldViolation("recurrence", err))
+	}
+
+	if r.EndAt != nil && r.GetEndAt().AsTime().Before(time.Now()) {
+		violations = append(violations, fieldViolation("end_at", fmt.Errorf("must be in the future")))
+	}
+
+	return violations
+}
@@ -0,0 +1,110 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) CreateTransfer(ctx context.Context, req *pb.CreateTransferRequest) (*pb.CreateTransferResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateCreateTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	fromAccount, err := s.validAccount(ctx, req.GetFromAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	if fromAccount.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "from account doesn't belong to the authenticated user")
+	}
+
+	toAccountID := req.GetToAccountId()
+	if req.GetPayeeId() != 0 {
+		payee, err := s.getPayee(ctx, req.GetPayeeId(), authPayload.Username)
+		if err != nil {
+			return nil, err
+		}
+		toAccountID = payee.AccountID
+	}
+
+	toAccount, err := s.validDestination(ctx, toAccountID, req.GetToAccountNumber(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	if toAccount.ID == fromAccount.ID {
+		return nil, status.Errorf(codes.InvalidArgument, "to account must differ from from account")
+	}
+
+	result, err := s.store.TransferTx(ctx, db.TransferTxParams{
+		FromAccountID: req.GetFromAccountId(),
+		ToAccountID:   toAccount.ID,
+		Amount:        req.GetAmount(),
+		RiskEvaluator: s.riskEvaluator,
+		Audit:         s.auditInfo(ctx, authPayload.Username),
+	})
+	if err != nil {
+		var limitErr *db.TransferLimitError
+		if errors.As(err, &limitErr) {
+			return nil, transferLimitError(limitErr)
+		}
+		if errors.Is(err, db.ErrTransferDenied) {
+			return nil, status.Errorf(codes.PermissionDenied, "cannot transfer: %s", err)
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAccountNotActive) {
+			return nil, status.Errorf(codes.FailedPrecondition, "cannot transfer: %s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to transfer: %s", err)
+	}
+
+	rsp := &pb.CreateTransferResponse{
+		FromAccount: convertAccount(result.FromAccount),
+	}
+	if result.Review.ID != 0 {
+		rsp.Review = convertTransferReview(result.Review)
+	} else {
+		rsp.Transfer = convertTransfer(result.Transfer)
+	}
+
+	return rsp, nil
+}
+
+func validateCreateTransferRequest(r *pb.CreateTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(r.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+
+	if r.GetPayeeId() != 0 {
+		if r.GetToAccountId() != 0 || r.GetToAccountNumber() != "" {
+			violations = append(violations, fieldViolation("payee_id", fmt.Errorf("cannot be set together with the destination account")))
+		} else if err := val.ValidateID(r.GetPayeeId()); err != nil {
+			violations = append(violations, fieldViolation("payee_id", err))
+		}
+	} else {
+		violations = append(violations, validateDestination(r.GetToAccountId(), r.GetToAccountNumber())...)
+	}
+
+	if err := val.ValidateAmount(r.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := val.ValidateCurrency(r.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	return violations
+}
@@ -2,17 +2,16 @@ package gapi
 
 import (
 	"context"
+	"fmt"
 	db "github.com/Dejan91/simple_bank/db/sqlc"
 	"github.com/Dejan91/simple_bank/pb"
 	"github.com/Dejan91/simple_bank/util"
 	"github.com/Dejan91/simple_bank/val"
 	"github.com/Dejan91/simple_bank/worker"
-	"github.com/hibiken/asynq"
 	"github.com/lib/pq"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
-	"time"
 )
 
 func (s *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb.CreateUserResponse, error) {
@@ -33,15 +32,9 @@ func (s *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb
 			FullName:       req.GetFullName(),
 			Email:          req.GetEmail(),
 		},
-		AfterCreate: func(user db.User) error {
+		AfterCreate: func(user db.User) []db.OutboxTask {
 			taskPayload := &worker.PayloadSendVerifyEmail{Username: user.Username}
-			opts := []asynq.Option{
-				asynq.MaxRetry(10),
-				asynq.ProcessIn(10 * time.Second),
-				asynq.Queue(worker.QueueCritical),
-			}
-
-			return s.taskDistributor.DistributeTaskSendVerifyEmail(ctx, taskPayload, opts...)
+			return []db.OutboxTask{worker.NewTaskSendVerifyEmail(ctx, taskPayload)}
 		},
 	}
 
@@ -66,6 +59,9 @@ func (s *Server) CreateUser(ctx context.Context, req *pb.CreateUserRequest) (*pb
 func validateCreateUserRequest(r *pb.CreateUserRequest) (violations []*errdetails.BadRequest_FieldViolation) {
 	if err := val.ValidateUsername(r.GetUsername()); err != nil {
 		violations = append(violations, fieldViolation("username", err))
+	} else if db.IsSystemAccountOwner(r.GetUsername()) {
+		// the system users own the accounts the bank moves money through
+		violations = append(violations, fieldViolation("username", fmt.Errorf("is reserved")))
 	}
 
 	if err := val.ValidatePassword(r.GetPassword()); err != nil {
@@ -0,0 +1,72 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/val"
+	"github.com/Dejan91/simple_bank/webhook"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strings"
+)
+
+func (s *Server) CreateWebhookSubscription(ctx context.Context, req *pb.CreateWebhookSubscriptionRequest) (*pb.CreateWebhookSubscriptionResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateCreateWebhookSubscriptionRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	secret, err := webhook.NewSecret()
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to generate webhook secret: %s", err)
+	}
+
+	subscription, err := s.store.CreateWebhookSubscription(ctx, db.CreateWebhookSubscriptionParams{
+		Owner:      authPayload.Username,
+		Url:        req.GetUrl(),
+		Secret:     secret,
+		EventTypes: strings.Join(req.GetEventTypes(), ","),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create webhook subscription: %s", err)
+	}
+
+	rsp := &pb.CreateWebhookSubscriptionResponse{
+		Subscription: convertWebhookSubscription(subscription),
+		Secret:       secret,
+	}
+
+	return rsp, nil
+}
+
+func validateCreateWebhookSubscriptionRequest(r *pb.CreateWebhookSubscriptionRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateWebhookURL(r.GetUrl()); err != nil {
+		violations = append(violations, fieldViolation("url", err))
+	}
+
+	for _, eventType := range r.GetEventTypes() {
+		if !isEventType(eventType) {
+			violations = append(violations, fieldViolation("event_types", fmt.Errorf("unknown event type %q", eventType)))
+		}
+	}
+
+	return violations
+}
+
+func isEventType(value string) bool {
+	for _, eventType := range db.EventTypes {
+		if value == eventType {
+			return true
+		}
+	}
+
+	return false
+}
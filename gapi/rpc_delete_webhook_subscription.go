@@ -0,0 +1,44 @@
+package gapi
+
+import (
+	"context"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) DeleteWebhookSubscription(ctx context.Context, req *pb.DeleteWebhookSubscriptionRequest) (*pb.DeleteWebhookSubscriptionResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateDeleteWebhookSubscriptionRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	subscription, err := s.getWebhookSubscription(ctx, req.GetId(), authPayload.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	// the subscription is kept for its delivery log, its pending deliveries fail
+	_, err = s.store.DeactivateWebhookSubscription(ctx, subscription.ID)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to delete webhook subscription: %s", err)
+	}
+
+	rsp := &pb.DeleteWebhookSubscriptionResponse{}
+	return rsp, nil
+}
+
+func validateDeleteWebhookSubscriptionRequest(r *pb.DeleteWebhookSubscriptionRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(r.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
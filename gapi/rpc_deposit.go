@@ -0,0 +1,131 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/payment"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/val"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"strconv"
+)
+
+func (s *Server) Deposit(ctx context.Context, req *pb.DepositRequest) (*pb.DepositResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateDepositRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := s.validAccount(ctx, req.GetAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	if account.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "account doesn't belong to the authenticated user")
+	}
+
+	// checked before collecting, as the money of a failed deposit would have to be paid back
+	if account.Status != db.AccountStatusActive {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot deposit: account is %s", account.Status)
+	}
+
+	// the deposit is recorded before collecting, so collected money is never lost track of
+	pendingPayment, err := s.store.CreatePayment(ctx, db.CreatePaymentParams{
+		AccountID:       account.ID,
+		Kind:            db.PaymentKindDeposit,
+		Amount:          req.GetAmount(),
+		Rail:            s.paymentRail.Name(),
+		ExternalAccount: req.GetSource(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to create payment: %s", err)
+	}
+
+	audit := s.auditInfo(ctx, authPayload.Username)
+
+	externalRef, err := s.paymentRail.Collect(ctx, payment.Request{
+		Reference:       strconv.FormatInt(pendingPayment.ID, 10),
+		ExternalAccount: req.GetSource(),
+		Amount:          req.GetAmount(),
+		Currency:        req.GetCurrency(),
+	})
+	if err != nil {
+		s.failDeposit(ctx, pendingPayment, "", err, audit)
+
+		if errors.Is(err, payment.ErrDeclined) {
+			return nil, status.Errorf(codes.FailedPrecondition, "cannot deposit: %s", err)
+		}
+		return nil, status.Errorf(codes.Unavailable, "failed to collect payment: %s", err)
+	}
+
+	result, err := s.store.DepositTx(ctx, db.DepositTxParams{
+		PaymentID:   pendingPayment.ID,
+		ExternalRef: externalRef,
+		Audit:       audit,
+	})
+	if err != nil {
+		depositErr := err
+		if err = s.paymentRail.Refund(ctx, externalRef); err != nil {
+			depositErr = fmt.Errorf("%s, and failed to refund: %w", depositErr, err)
+		}
+		s.failDeposit(ctx, pendingPayment, externalRef, depositErr, audit)
+
+		return nil, status.Errorf(codes.Internal, "failed to deposit payment %s: %s", externalRef, depositErr)
+	}
+
+	rsp := &pb.DepositResponse{
+		Payment: convertPayment(result.Payment),
+		Account: convertAccount(result.Transfer.ToAccount),
+	}
+
+	return rsp, nil
+}
+
+// failDeposit records a deposit which was not collected or was refunded, a deposit which cannot be
+// recorded as failed stays pending until the payment reconciliation task settles it
+func (s *Server) failDeposit(ctx context.Context, pendingPayment db.Payment, externalRef string, depositErr error, audit db.AuditInfo) {
+	_, err := s.store.FailDepositTx(ctx, db.FailDepositTxParams{
+		PaymentID:   pendingPayment.ID,
+		ExternalRef: externalRef,
+		LastError:   depositErr.Error(),
+		Audit:       audit,
+	})
+	if err != nil {
+		log.Ctx(ctx).Error().
+			Err(err).
+			Int64("payment_id", pendingPayment.ID).
+			Str("external_ref", externalRef).
+			Msg("failed to record failed deposit")
+	}
+}
+
+func validateDepositRequest(r *pb.DepositRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(r.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if err := val.ValidateAmount(r.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := val.ValidateCurrency(r.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	if err := val.ValidateString(r.GetSource(), 1, 100); err != nil {
+		violations = append(violations, fieldViolation("source", err))
+	}
+
+	return violations
+}
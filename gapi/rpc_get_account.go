@@ -0,0 +1,57 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) GetAccount(ctx context.Context, req *pb.GetAccountRequest) (*pb.GetAccountResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateGetAccountRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := s.findAccount(ctx, req.GetId(), req.GetNumber())
+	if err != nil {
+		return nil, err
+	}
+
+	// the accounts of other users are reported as missing, so their ids and numbers cannot be probed
+	if authPayload.Role != util.BankerRole && account.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.NotFound, "account not found")
+	}
+
+	rsp := &pb.GetAccountResponse{
+		Account: convertAccount(account),
+	}
+
+	return rsp, nil
+}
+
+func validateGetAccountRequest(r *pb.GetAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	switch {
+	case r.GetId() != 0 && r.GetNumber() != "":
+		violations = append(violations, fieldViolation("number", fmt.Errorf("cannot be set together with id")))
+	case r.GetNumber() != "":
+		if err := val.ValidateAccountNumber(r.GetNumber()); err != nil {
+			violations = append(violations, fieldViolation("number", err))
+		}
+	default:
+		if err := val.ValidateID(r.GetId()); err != nil {
+			violations = append(violations, fieldViolation("id", err))
+		}
+	}
+
+	return violations
+}
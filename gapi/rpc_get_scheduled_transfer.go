@@ -0,0 +1,75 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// recentExecutionsLimit is the number of executions returned with a scheduled transfer
+const recentExecutionsLimit = 10
+
+func (s *Server) GetScheduledTransfer(ctx context.Context, req *pb.GetScheduledTransferRequest) (*pb.GetScheduledTransferResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateGetScheduledTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	schedule, err := s.getScheduledTransfer(ctx, req.GetId(), authPayload.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	executions, err := s.store.ListScheduledTransferExecutions(ctx, db.ListScheduledTransferExecutionsParams{
+		ScheduledTransferID: schedule.ID,
+		Limit:               recentExecutionsLimit,
+		Offset:              0,
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list executions: %s", err)
+	}
+
+	rsp := &pb.GetScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(schedule),
+	}
+	for _, execution := range executions {
+		rsp.RecentExecutions = append(rsp.RecentExecutions, convertScheduledTransferExecution(execution))
+	}
+
+	return rsp, nil
+}
+
+func validateGetScheduledTransferRequest(r *pb.GetScheduledTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(r.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	return violations
+}
+
+// getScheduledTransfer loads a scheduled transfer and checks that it belongs to the user
+func (s *Server) getScheduledTransfer(ctx context.Context, id int64, username string) (db.ScheduledTransfer, error) {
+	schedule, err := s.store.GetScheduledTransfer(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return schedule, status.Errorf(codes.NotFound, "scheduled transfer not found")
+		}
+		return schedule, status.Errorf(codes.Internal, "failed to get scheduled transfer: %s", err)
+	}
+
+	if schedule.Owner != username {
+		return schedule, status.Errorf(codes.PermissionDenied, "scheduled transfer doesn't belong to the authenticated user")
+	}
+
+	return schedule, nil
+}
@@ -0,0 +1,94 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+func (s *Server) ListAuditEvents(ctx context.Context, req *pb.ListAuditEventsRequest) (*pb.ListAuditEventsResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if authPayload.Role != util.BankerRole {
+		return nil, status.Errorf(codes.PermissionDenied, "only bankers can list audit events")
+	}
+
+	violations := validateListAuditEventsRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	arg := db.ListAuditEventsParams{
+		Actor:      req.GetActor(),
+		Action:     req.GetAction(),
+		TargetType: req.GetTargetType(),
+		TargetID:   req.GetTargetId(),
+		EndTime:    time.Now(),
+		MaxEvents:  req.GetPageSize(),
+		SkipEvents: (req.GetPageId() - 1) * req.GetPageSize(),
+	}
+	if req.StartTime != nil {
+		arg.StartTime = req.GetStartTime().AsTime()
+	}
+	if req.EndTime != nil {
+		arg.EndTime = req.GetEndTime().AsTime()
+	}
+
+	events, err := s.store.ListAuditEvents(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list audit events: %s", err)
+	}
+
+	rsp := &pb.ListAuditEventsResponse{}
+	for _, event := range events {
+		rsp.AuditEvents = append(rsp.AuditEvents, convertAuditEvent(event))
+	}
+
+	return rsp, nil
+}
+
+func validateListAuditEventsRequest(r *pb.ListAuditEventsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if r.Actor != nil {
+		if err := val.ValidateUsername(r.GetActor()); err != nil {
+			violations = append(violations, fieldViolation("actor", err))
+		}
+	}
+
+	if r.Action != nil {
+		if err := val.ValidateString(r.GetAction(), 1, 100); err != nil {
+			violations = append(violations, fieldViolation("action", err))
+		}
+	}
+
+	if r.TargetType != nil {
+		if err := val.ValidateString(r.GetTargetType(), 1, 100); err != nil {
+			violations = append(violations, fieldViolation("target_type", err))
+		}
+	}
+
+	if r.TargetId != nil {
+		if err := val.ValidateString(r.GetTargetId(), 1, 100); err != nil {
+			violations = append(violations, fieldViolation("target_id", err))
+		}
+	}
+
+	if r.StartTime != nil && r.EndTime != nil && !r.GetStartTime().AsTime().Before(r.GetEndTime().AsTime()) {
+		violations = append(violations, fieldViolation("end_time", fmt.Errorf("must be after start_time")))
+	}
+
+	if err := val.ValidatePage(r.GetPageId(), r.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,47 @@
+package gapi
+
+import (
+	"context"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) ListPayees(ctx context.Context, req *pb.ListPayeesRequest) (*pb.ListPayeesResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateListPayeesRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	payees, err := s.store.ListPayees(ctx, db.ListPayeesParams{
+		Owner:  authPayload.Username,
+		Limit:  req.GetPageSize(),
+		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list payees: %s", err)
+	}
+
+	rsp := &pb.ListPayeesResponse{}
+	for _, payee := range payees {
+		rsp.Payees = append(rsp.Payees, convertPayeeRow(payee))
+	}
+
+	return rsp, nil
+}
+
+func validateListPayeesRequest(r *pb.ListPayeesRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidatePage(r.GetPageId(), r.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,66 @@
+package gapi
+
+import (
+	"context"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) ListTransferReviews(ctx context.Context, req *pb.ListTransferReviewsRequest) (*pb.ListTransferReviewsResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if authPayload.Role != util.BankerRole {
+		return nil, status.Errorf(codes.PermissionDenied, "only bankers can list transfer reviews")
+	}
+
+	violations := validateListTransferReviewsRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	reviewStatus := db.TransferReviewStatusPending
+	if req.Status != nil {
+		reviewStatus = req.GetStatus()
+	}
+
+	reviews, err := s.store.ListTransferReviews(ctx, db.ListTransferReviewsParams{
+		Status: reviewStatus,
+		Limit:  req.GetPageSize(),
+		Offset: (req.GetPageId() - 1) * req.GetPageSize(),
+	})
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to list transfer reviews: %s", err)
+	}
+
+	rsp := &pb.ListTransferReviewsResponse{}
+	for _, review := range reviews {
+		rsp.TransferReviews = append(rsp.TransferReviews, convertTransferReview(review))
+	}
+
+	return rsp, nil
+}
+
+func validateListTransferReviewsRequest(r *pb.ListTransferReviewsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if r.Status != nil {
+		switch r.GetStatus() {
+		case db.TransferReviewStatusPending, db.TransferReviewStatusApproved, db.TransferReviewStatusRejected:
+		default:
+			violations = append(violations, fieldViolation("status", fmt.Errorf("must be pending, approved or rejected")))
+		}
+	}
+
+	if err := val.ValidatePage(r.GetPageId(), r.GetPageSize()); err != nil {
+		violations = append(violations, fieldViolation("page", err))
+	}
+
+	return violations
+}
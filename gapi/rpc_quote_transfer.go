@@ -0,0 +1,73 @@
+package gapi
+
+import (
+	"context"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) QuoteTransfer(ctx context.Context, req *pb.QuoteTransferRequest) (*pb.QuoteTransferResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateQuoteTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	fromAccount, err := s.validAccount(ctx, req.GetFromAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	if authPayload.Role != util.BankerRole && fromAccount.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "from account doesn't belong to the authenticated user")
+	}
+
+	toAccount, err := s.validDestination(ctx, req.GetToAccountId(), req.GetToAccountNumber(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	if toAccount.ID == fromAccount.ID {
+		return nil, status.Errorf(codes.InvalidArgument, "to account must differ from from account")
+	}
+
+	fee, err := s.store.QuoteFee(ctx, req.GetCurrency(), req.GetAmount())
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to quote fee: %s", err)
+	}
+
+	rsp := &pb.QuoteTransferResponse{
+		Amount:      req.GetAmount(),
+		Fee:         fee,
+		TotalAmount: req.GetAmount() + fee,
+		Currency:    req.GetCurrency(),
+	}
+
+	return rsp, nil
+}
+
+func validateQuoteTransferRequest(r *pb.QuoteTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(r.GetFromAccountId()); err != nil {
+		violations = append(violations, fieldViolation("from_account_id", err))
+	}
+
+	violations = append(violations, validateDestination(r.GetToAccountId(), r.GetToAccountNumber())...)
+
+	if err := val.ValidateAmount(r.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := val.ValidateCurrency(r.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	return violations
+}
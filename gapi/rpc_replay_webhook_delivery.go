@@ -0,0 +1,66 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) ReplayWebhookDelivery(ctx context.Context, req *pb.ReplayWebhookDeliveryRequest) (*pb.ReplayWebhookDeliveryResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateReplayWebhookDeliveryRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	delivery, err := s.store.GetWebhookDelivery(ctx, req.GetDeliveryId())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "webhook delivery not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get webhook delivery: %s", err)
+	}
+
+	subscription, err := s.getWebhookSubscription(ctx, delivery.SubscriptionID, authPayload.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	if !subscription.IsActive {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot replay webhook delivery: subscription is deleted")
+	}
+
+	result, err := s.store.ReplayWebhookDeliveryTx(ctx, db.ReplayWebhookDeliveryTxParams{
+		DeliveryID: delivery.ID,
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrWebhookDeliveryNotFailed) {
+			return nil, status.Errorf(codes.FailedPrecondition, "cannot replay webhook delivery: %s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to replay webhook delivery: %s", err)
+	}
+
+	rsp := &pb.ReplayWebhookDeliveryResponse{
+		Delivery: convertWebhookDelivery(result.Delivery),
+	}
+
+	return rsp, nil
+}
+
+func validateReplayWebhookDeliveryRequest(r *pb.ReplayWebhookDeliveryRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(r.GetDeliveryId()); err != nil {
+		violations = append(violations, fieldViolation("delivery_id", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,85 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) ReverseTransfer(ctx context.Context, req *pb.ReverseTransferRequest) (*pb.ReverseTransferResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateReverseTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	transfer, err := s.store.GetTransfer(ctx, req.GetTransferId())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, status.Errorf(codes.NotFound, "transfer not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to get transfer: %s", err)
+	}
+
+	// only bankers and the receiver, who gives the money back, can reverse a transfer
+	if authPayload.Role != util.BankerRole {
+		toAccount, err := s.store.GetAccount(ctx, transfer.ToAccountID)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "failed to get account: %s", err)
+		}
+
+		if toAccount.Owner != authPayload.Username {
+			return nil, status.Errorf(codes.PermissionDenied, "only a banker or the receiver can reverse the transfer")
+		}
+	}
+
+	result, err := s.store.ReverseTransferTx(ctx, db.ReverseTransferTxParams{
+		TransferID: transfer.ID,
+		Amount:     req.GetAmount(),
+		Audit:      s.auditInfo(ctx, authPayload.Username),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrTransferIsReversal) ||
+			errors.Is(err, db.ErrReversalExceedsAmount) ||
+			errors.Is(err, db.ErrSystemTransfer) ||
+			errors.Is(err, db.ErrSettlementTransfer) ||
+			errors.Is(err, db.ErrInsufficientFunds) ||
+			errors.Is(err, db.ErrAccountNotActive) {
+			return nil, status.Errorf(codes.FailedPrecondition, "cannot reverse transfer: %s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to reverse transfer: %s", err)
+	}
+
+	rsp := &pb.ReverseTransferResponse{
+		Reversal:        convertTransfer(result.Reversal.Transfer),
+		RemainingAmount: result.RemainingAmount,
+		FeeRefund:       result.FeeRefund,
+	}
+
+	return rsp, nil
+}
+
+func validateReverseTransferRequest(r *pb.ReverseTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(r.GetTransferId()); err != nil {
+		violations = append(violations, fieldViolation("transfer_id", err))
+	}
+
+	if r.Amount != nil {
+		if err := val.ValidateAmount(r.GetAmount()); err != nil {
+			violations = append(violations, fieldViolation("amount", err))
+		}
+	}
+
+	return violations
+}
@@ -0,0 +1,68 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) ReviewTransfer(ctx context.Context, req *pb.ReviewTransferRequest) (*pb.ReviewTransferResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if authPayload.Role != util.BankerRole {
+		return nil, status.Errorf(codes.PermissionDenied, "only bankers can review transfers")
+	}
+
+	violations := validateReviewTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	result, err := s.store.ReviewTransferTx(ctx, db.ReviewTransferTxParams{
+		ReviewID: req.GetReviewId(),
+		Approve:  req.GetApprove(),
+		Audit:    s.auditInfo(ctx, authPayload.Username),
+	})
+	if err != nil {
+		var limitErr *db.TransferLimitError
+		if errors.As(err, &limitErr) {
+			return nil, transferLimitError(limitErr)
+		}
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "transfer review not found")
+		}
+		if errors.Is(err, db.ErrTransferReviewNotPending) ||
+			errors.Is(err, db.ErrInsufficientFunds) ||
+			errors.Is(err, db.ErrAccountNotActive) {
+			return nil, status.Errorf(codes.FailedPrecondition, "cannot review transfer: %s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to review transfer: %s", err)
+	}
+
+	rsp := &pb.ReviewTransferResponse{
+		Review: convertTransferReview(result.Review),
+	}
+	if result.Transfer.Transfer.ID != 0 {
+		rsp.Transfer = convertTransfer(result.Transfer.Transfer)
+	}
+
+	return rsp, nil
+}
+
+func validateReviewTransferRequest(r *pb.ReviewTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(r.GetReviewId()); err != nil {
+		violations = append(violations, fieldViolation("review_id", err))
+	}
+
+	return violations
+}
@@ -0,0 +1,107 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *Server) SetLimits(ctx context.Context, req *pb.SetLimitsRequest) (*pb.SetLimitsResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	if authPayload.Role != util.BankerRole {
+		return nil, status.Errorf(codes.PermissionDenied, "only bankers can set transfer limits")
+	}
+
+	violations := validateSetLimitsRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	result, err := s.store.SetTransferLimitTx(ctx, db.SetTransferLimitTxParams{
+		Username:        req.GetUsername(),
+		AccountID:       req.GetAccountId(),
+		MaxSingleAmount: optionalInt64(req.MaxSingleAmount),
+		DailyAmount:     optionalInt64(req.DailyAmount),
+		MonthlyAmount:   optionalInt64(req.MonthlyAmount),
+		HourlyCount:     optionalInt64(req.HourlyCount),
+		Audit:           s.auditInfo(ctx, authPayload.Username),
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, status.Errorf(codes.NotFound, "user or account not found")
+		}
+		return nil, status.Errorf(codes.Internal, "failed to set transfer limits: %s", err)
+	}
+
+	limit := result.Limit
+	rsp := &pb.SetLimitsResponse{
+		Username:        limit.Username.String,
+		AccountId:       limit.AccountID.Int64,
+		MaxSingleAmount: nullableInt64(limit.MaxSingleAmount),
+		DailyAmount:     nullableInt64(limit.DailyAmount),
+		MonthlyAmount:   nullableInt64(limit.MonthlyAmount),
+		HourlyCount:     nullableInt64(limit.HourlyCount),
+		UpdatedAt:       timestamppb.New(limit.UpdatedAt),
+	}
+
+	return rsp, nil
+}
+
+func optionalInt64(value *int64) sql.NullInt64 {
+	if value == nil {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: *value, Valid: true}
+}
+
+func nullableInt64(value sql.NullInt64) *int64 {
+	if !value.Valid {
+		return nil
+	}
+	return &value.Int64
+}
+
+func validateSetLimitsRequest(r *pb.SetLimitsRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if (r.GetUsername() == "") == (r.GetAccountId() == 0) {
+		violations = append(violations, fieldViolation("username", fmt.Errorf("set either username or account_id")))
+	} else if r.GetUsername() != "" {
+		if err := val.ValidateUsername(r.GetUsername()); err != nil {
+			violations = append(violations, fieldViolation("username", err))
+		}
+	} else if err := val.ValidateID(r.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	limits := []struct {
+		field string
+		value *int64
+	}{
+		{"max_single_amount", r.MaxSingleAmount},
+		{"daily_amount", r.DailyAmount},
+		{"monthly_amount", r.MonthlyAmount},
+		{"hourly_count", r.HourlyCount},
+	}
+	for _, limit := range limits {
+		if limit.value == nil {
+			continue
+		}
+		if err := val.ValidateAmount(*limit.value); err != nil {
+			violations = append(violations, fieldViolation(limit.field, err))
+		}
+	}
+
+	return violations
+}
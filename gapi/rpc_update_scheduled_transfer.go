@@ -0,0 +1,127 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"time"
+)
+
+func (s *Server) UpdateScheduledTransfer(ctx context.Context, req *pb.UpdateScheduledTransferRequest) (*pb.UpdateScheduledTransferResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateUpdateScheduledTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	schedule, err := s.getScheduledTransfer(ctx, req.GetId(), authPayload.Username)
+	if err != nil {
+		return nil, err
+	}
+
+	if schedule.Status != db.ScheduledTransferStatusActive && schedule.Status != db.ScheduledTransferStatusPaused {
+		return nil, status.Errorf(codes.FailedPrecondition, "cannot update %s scheduled transfer", schedule.Status)
+	}
+
+	arg := db.UpdateScheduledTransferParams{
+		ID: schedule.ID,
+		Amount: sql.NullInt64{
+			Int64: req.GetAmount(),
+			Valid: req.Amount != nil,
+		},
+		Recurrence: sql.NullString{
+			String: req.GetRecurrence(),
+			Valid:  req.Recurrence != nil,
+		},
+		EndAt: sql.NullTime{
+			Time:  req.GetEndAt().AsTime(),
+			Valid: req.EndAt != nil,
+		},
+		Status: sql.NullString{
+			String: req.GetStatus(),
+			Valid:  req.Status != nil,
+		},
+	}
+
+	resumed := schedule.Status == db.ScheduledTransferStatusPaused && req.GetStatus() == db.ScheduledTransferStatusActive
+	if resumed {
+		arg.FailureCount = sql.NullInt32{Int32: 0, Valid: true}
+	}
+
+	// the next run is recomputed when the recurrence changes or the schedule is resumed
+	if req.Recurrence != nil || resumed {
+		spec := schedule.Recurrence
+		if req.Recurrence != nil {
+			spec = req.GetRecurrence()
+		}
+
+		recurrence, err := util.ParseRecurrence(spec)
+		if err != nil {
+			return nil, status.Errorf(codes.Internal, "invalid recurrence: %s", err)
+		}
+
+		from := time.Now()
+		if schedule.StartAt.After(from) {
+			from = schedule.StartAt
+		}
+
+		arg.NextRunAt = sql.NullTime{
+			Time:  util.FirstRun(recurrence, from),
+			Valid: true,
+		}
+	}
+
+	schedule, err = s.store.UpdateScheduledTransfer(ctx, arg)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "failed to update scheduled transfer: %s", err)
+	}
+
+	rsp := &pb.UpdateScheduledTransferResponse{
+		ScheduledTransfer: convertScheduledTransfer(schedule),
+	}
+
+	return rsp, nil
+}
+
+func validateUpdateScheduledTransferRequest(r *pb.UpdateScheduledTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(r.GetId()); err != nil {
+		violations = append(violations, fieldViolation("id", err))
+	}
+
+	if r.Amount != nil {
+		if err := val.ValidateAmount(r.GetAmount()); err != nil {
+			violations = append(violations, fieldViolation("amount", err))
+		}
+	}
+
+	if r.Recurrence != nil {
+		if err := val.ValidateRecurrence(r.GetRecurrence()); err != nil {
+			violations = append(violations, fieldViolation("recurrence", err))
+		}
+	}
+
+	if r.EndAt != nil && r.GetEndAt().AsTime().Before(time.Now()) {
+		violations = append(violations, fieldViolation("end_at", fmt.Errorf("must be in the future")))
+	}
+
+	if r.Status != nil {
+		switch r.GetStatus() {
+		case db.ScheduledTransferStatusActive, db.ScheduledTransferStatusPaused:
+		default:
+			violations = append(violations, fieldViolation("status", fmt.Errorf("must be %s or %s", db.ScheduledTransferStatusActive, db.ScheduledTransferStatusPaused)))
+		}
+	}
+
+	return violations
+}
@@ -61,7 +61,10 @@ func (s *Server) UpdateUser(ctx context.Context, r *pb.UpdateUserRequest) (*pb.U
 		}
 	}
 
-	user, err := s.store.UpdateUser(ctx, arg)
+	result, err := s.store.UpdateUserTx(ctx, db.UpdateUserTxParams{
+		UpdateUserParams: arg,
+		Audit:            s.auditInfo(ctx, authPayload.Username),
+	})
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, status.Errorf(codes.NotFound, "user not found")
@@ -70,7 +73,7 @@ func (s *Server) UpdateUser(ctx context.Context, r *pb.UpdateUserRequest) (*pb.U
 	}
 
 	rsp := &pb.UpdateUserResponse{
-		User: convertUser(user),
+		User: convertUser(result.User),
 	}
 
 	return rsp, nil
@@ -0,0 +1,54 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) VoidTransfer(ctx context.Context, req *pb.VoidTransferRequest) (*pb.VoidTransferResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateVoidTransferRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	hold, err := s.getHold(ctx, req.GetHoldId(), authPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.store.VoidTransferTx(ctx, db.VoidTransferTxParams{
+		HoldID: hold.ID,
+		Audit:  s.auditInfo(ctx, authPayload.Username),
+	})
+	if err != nil {
+		if errors.Is(err, db.ErrHoldNotAuthorized) {
+			return nil, status.Errorf(codes.FailedPrecondition, "cannot void transfer: %s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to void transfer: %s", err)
+	}
+
+	rsp := &pb.VoidTransferResponse{
+		Hold: convertHold(result.Hold),
+	}
+
+	return rsp, nil
+}
+
+func validateVoidTransferRequest(r *pb.VoidTransferRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(r.GetHoldId()); err != nil {
+		violations = append(violations, fieldViolation("hold_id", err))
+	}
+
+	return violations
+}
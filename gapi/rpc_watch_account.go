@@ -0,0 +1,138 @@
+package gapi
+
+import (
+	"database/sql"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// watchAccountBatchSize is the max number of entries loaded from the db at once
+const watchAccountBatchSize = 100
+
+func (s *Server) WatchAccount(req *pb.WatchAccountRequest, stream pb.SimpleBank_WatchAccountServer) error {
+	ctx := stream.Context()
+
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return unauthenticatedError(err)
+	}
+
+	violations := validateWatchAccountRequest(req)
+	if violations != nil {
+		return invalidArgumentError(violations)
+	}
+
+	account, err := s.store.GetAccount(ctx, req.GetAccountId())
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return status.Errorf(codes.NotFound, "account not found")
+		}
+		return status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	if account.Owner != authPayload.Username {
+		return status.Errorf(codes.PermissionDenied, "account doesn't belong to the authenticated user")
+	}
+
+	// subscribe before reading the entries so no update can slip in between
+	updated, unsubscribe := s.accountNotifier.Subscribe(account.ID)
+	defer unsubscribe()
+
+	// without a resume token the stream starts from the current balance instead of replaying the history
+	lastEntryID := req.GetResumeToken()
+	if lastEntryID == 0 {
+		lastEntryID, err = s.store.GetAccountLastEntryID(ctx, account.ID)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to get last entry: %s", err)
+		}
+	}
+
+	account, err = s.store.GetAccount(ctx, account.ID)
+	if err != nil {
+		return status.Errorf(codes.Internal, "failed to get account: %s", err)
+	}
+
+	err = stream.Send(&pb.WatchAccountResponse{
+		Account:     convertAccount(account),
+		ResumeToken: lastEntryID,
+	})
+	if err != nil {
+		return err
+	}
+	sentAccount := account
+
+	for {
+		entries, err := s.store.ListEntriesAfter(ctx, db.ListEntriesAfterParams{
+			AccountID:  account.ID,
+			AfterID:    lastEntryID,
+			MaxEntries: watchAccountBatchSize,
+		})
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to list entries: %s", err)
+		}
+
+		account, err = s.store.GetAccount(ctx, account.ID)
+		if err != nil {
+			return status.Errorf(codes.Internal, "failed to get account: %s", err)
+		}
+
+		for _, entry := range entries {
+			err = stream.Send(&pb.WatchAccountResponse{
+				Account:     convertAccount(account),
+				Entry:       convertEntry(entry),
+				ResumeToken: entry.ID,
+			})
+			if err != nil {
+				return err
+			}
+			lastEntryID = entry.ID
+			sentAccount = account
+		}
+
+		if len(entries) == watchAccountBatchSize {
+			continue
+		}
+
+		// holds, reviews and status changes update the account without any entry
+		if accountChanged(sentAccount, account) {
+			err = stream.Send(&pb.WatchAccountResponse{
+				Account:     convertAccount(account),
+				ResumeToken: lastEntryID,
+			})
+			if err != nil {
+				return err
+			}
+			sentAccount = account
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-updated:
+		}
+	}
+}
+
+// accountChanged tells whether the client needs a new snapshot of the account
+func accountChanged(sent db.Account, current db.Account) bool {
+	return sent.Balance != current.Balance ||
+		sent.HeldBalance != current.HeldBalance ||
+		sent.Status != current.Status
+}
+
+func validateWatchAccountRequest(r *pb.WatchAccountRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(r.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if r.GetResumeToken() < 0 {
+		violations = append(violations, fieldViolation("resume_token", fmt.Errorf("must not be negative")))
+	}
+
+	return violations
+}
@@ -0,0 +1,83 @@
+package gapi
+
+import (
+	"context"
+	"errors"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/val"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func (s *Server) Withdraw(ctx context.Context, req *pb.WithdrawRequest) (*pb.WithdrawResponse, error) {
+	authPayload, err := s.authorizeUser(ctx)
+	if err != nil {
+		return nil, unauthenticatedError(err)
+	}
+
+	violations := validateWithdrawRequest(req)
+	if violations != nil {
+		return nil, invalidArgumentError(violations)
+	}
+
+	account, err := s.validAccount(ctx, req.GetAccountId(), req.GetCurrency())
+	if err != nil {
+		return nil, err
+	}
+
+	if account.Owner != authPayload.Username {
+		return nil, status.Errorf(codes.PermissionDenied, "account doesn't belong to the authenticated user")
+	}
+
+	// the money is paid out by a task once the debit is committed
+	result, err := s.store.WithdrawTx(ctx, db.WithdrawTxParams{
+		AccountID:     account.ID,
+		Amount:        req.GetAmount(),
+		Rail:          s.paymentRail.Name(),
+		Destination:   req.GetDestination(),
+		RiskEvaluator: s.riskEvaluator,
+		Audit:         s.auditInfo(ctx, authPayload.Username),
+	})
+	if err != nil {
+		var limitErr *db.TransferLimitError
+		if errors.As(err, &limitErr) {
+			return nil, transferLimitError(limitErr)
+		}
+		if errors.Is(err, db.ErrTransferDenied) {
+			return nil, status.Errorf(codes.PermissionDenied, "cannot withdraw: %s", err)
+		}
+		if errors.Is(err, db.ErrInsufficientFunds) || errors.Is(err, db.ErrAccountNotActive) {
+			return nil, status.Errorf(codes.FailedPrecondition, "cannot withdraw: %s", err)
+		}
+		return nil, status.Errorf(codes.Internal, "failed to withdraw: %s", err)
+	}
+
+	rsp := &pb.WithdrawResponse{
+		Payment: convertPayment(result.Payment),
+		Account: convertAccount(result.Transfer.FromAccount),
+	}
+
+	return rsp, nil
+}
+
+func validateWithdrawRequest(r *pb.WithdrawRequest) (violations []*errdetails.BadRequest_FieldViolation) {
+	if err := val.ValidateID(r.GetAccountId()); err != nil {
+		violations = append(violations, fieldViolation("account_id", err))
+	}
+
+	if err := val.ValidateAmount(r.GetAmount()); err != nil {
+		violations = append(violations, fieldViolation("amount", err))
+	}
+
+	if err := val.ValidateCurrency(r.GetCurrency()); err != nil {
+		violations = append(violations, fieldViolation("currency", err))
+	}
+
+	if err := val.ValidateString(r.GetDestination(), 1, 100); err != nil {
+		violations = append(violations, fieldViolation("destination", err))
+	}
+
+	return violations
+}
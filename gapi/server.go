@@ -3,10 +3,11 @@ package gapi
 import (
 	"fmt"
 	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/notifier"
+	"github.com/Dejan91/simple_bank/payment"
 	"github.com/Dejan91/simple_bank/pb"
 	"github.com/Dejan91/simple_bank/token"
 	"github.com/Dejan91/simple_bank/util"
-	"github.com/Dejan91/simple_bank/worker"
 )
 
 // Server serves gRPS requests for our banking service
@@ -15,21 +16,38 @@ type Server struct {
 	config          util.Config
 	store           db.Store
 	tokenMaker      token.Maker
-	taskDistributor worker.TaskDistributor
+	accountNotifier notifier.AccountNotifier
+	paymentRail     payment.PaymentRail
+	riskEvaluator   db.RiskEvaluator
+	clientIPs       *util.ClientIPResolver
 }
 
 // NewServer creates a new gRPC server
-func NewServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor) (*Server, error) {
+func NewServer(
+	config util.Config,
+	store db.Store,
+	accountNotifier notifier.AccountNotifier,
+	paymentRail payment.PaymentRail,
+	riskEvaluator db.RiskEvaluator,
+) (*Server, error) {
 	tokenMaker, err := token.NewPasetoMaker(config.TokenSymmetricKey)
 	if err != nil {
 		return nil, fmt.Errorf("cannot create token maker: %w", err)
 	}
 
+	clientIPs, err := util.NewClientIPResolver(config.TrustedProxies)
+	if err != nil {
+		return nil, err
+	}
+
 	server := &Server{
 		config:          config,
 		store:           store,
 		tokenMaker:      tokenMaker,
-		taskDistributor: taskDistributor,
+		accountNotifier: accountNotifier,
+		paymentRail:     paymentRail,
+		riskEvaluator:   riskEvaluator,
+		clientIPs:       clientIPs,
 	}
 
 	return server, nil
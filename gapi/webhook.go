@@ -0,0 +1,26 @@
+package gapi
+
+import (
+	"context"
+	"database/sql"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// getWebhookSubscription loads a webhook subscription and checks that it belongs to the user
+func (s *Server) getWebhookSubscription(ctx context.Context, id int64, username string) (db.WebhookSubscription, error) {
+	subscription, err := s.store.GetWebhookSubscription(ctx, id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return subscription, status.Errorf(codes.NotFound, "webhook subscription not found")
+		}
+		return subscription, status.Errorf(codes.Internal, "failed to get webhook subscription: %s", err)
+	}
+
+	if subscription.Owner != username {
+		return subscription, status.Errorf(codes.PermissionDenied, "webhook subscription doesn't belong to the authenticated user")
+	}
+
+	return subscription, nil
+}
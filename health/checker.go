@@ -0,0 +1,174 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/rs/zerolog/log"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ErrShuttingDown is reported by the readiness check once the server started to shut down
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// Check verifies that a dependency of the server is usable
+type Check func(ctx context.Context) error
+
+type namedCheck struct {
+	name  string
+	check Check
+}
+
+// Checker runs the readiness checks for the HTTP probes and keeps the gRPC health service up to date
+type Checker struct {
+	checks       []namedCheck
+	timeout      time.Duration
+	services     []string
+	shuttingDown atomic.Bool
+	grpcServer   *health.Server
+}
+
+// NewChecker creates a checker whose checks are cancelled after the timeout,
+// the services are reported by the gRPC health service besides the overall "" service
+func NewChecker(timeout time.Duration, services ...string) *Checker {
+	return &Checker{
+		timeout:    timeout,
+		services:   append([]string{""}, services...),
+		grpcServer: health.NewServer(),
+	}
+}
+
+// AddCheck registers a readiness check, it must be called before the checker is used
+func (c *Checker) AddCheck(name string, check Check) {
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// Check runs all the checks and returns the errors of the failed ones by name
+func (c *Checker) Check(ctx context.Context) map[string]error {
+	failures := make(map[string]error)
+
+	if c.shuttingDown.Load() {
+		failures["shutdown"] = ErrShuttingDown
+		return failures
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	for _, check := range c.checks {
+		if err := check.check(ctx); err != nil {
+			failures[check.name] = err
+		}
+	}
+
+	return failures
+}
+
+// Shutdown reports the server as not ready from now on
+func (c *Checker) Shutdown() {
+	c.shuttingDown.Store(true)
+	c.grpcServer.Shutdown()
+}
+
+// Drain reports the server as not ready as soon as ctx is done, and returns a context which is done once
+// the drain period is over, so the servers only stop after the load balancers stopped routing to them
+func (c *Checker) Drain(ctx context.Context, period time.Duration) context.Context {
+	drained, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		defer cancel()
+
+		<-ctx.Done()
+		c.Shutdown()
+
+		log.Info().Dur("period", period).Msg("draining before shutdown")
+		time.Sleep(period)
+	}()
+
+	return drained
+}
+
+// GRPCServer is the grpc.health.v1 service to register on the gRPC server
+func (c *Checker) GRPCServer() healthpb.HealthServer {
+	return c.grpcServer
+}
+
+// Run updates the status of the gRPC health service every interval until ctx is done,
+// then reports the server as not ready
+func (c *Checker) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		c.updateServingStatus(ctx)
+
+		select {
+		case <-ctx.Done():
+			c.Shutdown()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Checker) updateServingStatus(ctx context.Context) {
+	servingStatus := healthpb.HealthCheckResponse_SERVING
+
+	failures := c.Check(ctx)
+	if len(failures) > 0 {
+		servingStatus = healthpb.HealthCheckResponse_NOT_SERVING
+		for name, err := range failures {
+			log.Warn().Err(err).Str("check", name).Msg("readiness check failed")
+		}
+	}
+
+	for _, service := range c.services {
+		c.grpcServer.SetServingStatus(service, servingStatus)
+	}
+}
+
+type readinessResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// LivenessHandler serves /healthz, it only tells that the process is able to handle requests
+func (c *Checker) LivenessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, readinessResponse{Status: "ok"})
+	})
+}
+
+// ReadinessHandler serves /readyz, it fails while a dependency is unusable or the server is shutting down
+func (c *Checker) ReadinessHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		failures := c.Check(r.Context())
+		if len(failures) == 0 {
+			writeJSON(w, http.StatusOK, readinessResponse{Status: "ok"})
+			return
+		}
+
+		rsp := readinessResponse{
+			Status: "unavailable",
+			Checks: make(map[string]string, len(failures)),
+		}
+		for name, err := range failures {
+			rsp.Checks[name] = err.Error()
+		}
+
+		writeJSON(w, http.StatusServiceUnavailable, rsp)
+	})
+}
+
+func writeJSON(w http.ResponseWriter, statusCode int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(statusCode)
+
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Error().Err(err).Msg("failed to write health response")
+	}
+}
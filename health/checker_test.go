@@ -0,0 +1,101 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+func TestReadinessHandler(t *testing.T) {
+	var redisErr error
+
+	checker := NewChecker(time.Second, "pb.SimpleBank")
+	checker.AddCheck("postgres", func(ctx context.Context) error { return nil })
+	checker.AddCheck("redis", func(ctx context.Context) error { return redisErr })
+
+	readyz := func() (int, readinessResponse) {
+		recorder := httptest.NewRecorder()
+		checker.ReadinessHandler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		var rsp readinessResponse
+		require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &rsp))
+		return recorder.Code, rsp
+	}
+
+	code, rsp := readyz()
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "ok", rsp.Status)
+
+	redisErr = errors.New("connection refused")
+	code, rsp = readyz()
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, map[string]string{"redis": "connection refused"}, rsp.Checks)
+
+	redisErr = nil
+	checker.Shutdown()
+	code, rsp = readyz()
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, map[string]string{"shutdown": ErrShuttingDown.Error()}, rsp.Checks)
+}
+
+func TestRunUpdatesGRPCHealth(t *testing.T) {
+	checker := NewChecker(time.Second, "pb.SimpleBank")
+	checker.AddCheck("postgres", func(ctx context.Context) error { return nil })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		checker.Run(ctx, time.Hour)
+		close(done)
+	}()
+
+	require.Eventually(t, func() bool {
+		rsp, err := checker.GRPCServer().Check(context.Background(), &healthpb.HealthCheckRequest{Service: "pb.SimpleBank"})
+		return err == nil && rsp.Status == healthpb.HealthCheckResponse_SERVING
+	}, time.Second, 10*time.Millisecond)
+
+	cancel()
+	<-done
+
+	rsp, err := checker.GRPCServer().Check(context.Background(), &healthpb.HealthCheckRequest{})
+	require.NoError(t, err)
+	require.Equal(t, healthpb.HealthCheckResponse_NOT_SERVING, rsp.Status)
+}
+
+func TestLatestMigrationVersion(t *testing.T) {
+	// the migrations are numbered sequentially
+	migrations, err := filepath.Glob("../db/migration/*.up.sql")
+	require.NoError(t, err)
+
+	version, err := LatestMigrationVersion("file://../db/migration")
+	require.NoError(t, err)
+	require.Equal(t, uint(len(migrations)), version)
+}
+
+func TestDrain(t *testing.T) {
+	checker := NewChecker(time.Second)
+	checker.AddCheck("postgres", func(ctx context.Context) error { return nil })
+
+	ctx, cancel := context.WithCancel(context.Background())
+	drained := checker.Drain(ctx, 100*time.Millisecond)
+	require.Empty(t, checker.Check(context.Background()))
+
+	start := time.Now()
+	cancel()
+
+	require.Eventually(t, func() bool {
+		return len(checker.Check(context.Background())) > 0
+	}, time.Second, time.Millisecond)
+	require.NoError(t, drained.Err())
+
+	<-drained.Done()
+	require.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+}
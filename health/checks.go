@@ -0,0 +1,78 @@
+package health
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"github.com/golang-migrate/migrate/v4/source"
+	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/redis/go-redis/v9"
+	"os"
+)
+
+// PostgresCheck pings the database
+func PostgresCheck(db *sql.DB) Check {
+	return func(ctx context.Context) error {
+		return db.PingContext(ctx)
+	}
+}
+
+// RedisCheck pings the redis server used by the task queue
+func RedisCheck(client redis.UniversalClient) Check {
+	return func(ctx context.Context) error {
+		return client.Ping(ctx).Err()
+	}
+}
+
+// MigrationCheck verifies that the database schema is at least at the expected migration version and not dirty,
+// a newer version is fine since the replicas of the previous release keep serving while a rollout migrates the schema
+func MigrationCheck(db *sql.DB, expectedVersion uint) Check {
+	return func(ctx context.Context) error {
+		var version uint
+		var dirty bool
+
+		err := db.QueryRowContext(ctx, "SELECT version, dirty FROM schema_migrations LIMIT 1").Scan(&version, &dirty)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("no migration applied, expected version %d", expectedVersion)
+			}
+			return err
+		}
+
+		if dirty {
+			return fmt.Errorf("migration %d failed and left the schema dirty", version)
+		}
+
+		if version < expectedVersion {
+			return fmt.Errorf("schema is at migration %d, expected at least version %d", version, expectedVersion)
+		}
+
+		return nil
+	}
+}
+
+// LatestMigrationVersion returns the version of the last migration in the source
+func LatestMigrationVersion(migrationURL string) (uint, error) {
+	src, err := source.Open(migrationURL)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open migration source: %w", err)
+	}
+	defer src.Close()
+
+	version, err := src.First()
+	if err != nil {
+		return 0, fmt.Errorf("failed to read first migration: %w", err)
+	}
+
+	for {
+		next, err := src.Next(version)
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				return version, nil
+			}
+			return 0, fmt.Errorf("failed to read migration after %d: %w", version, err)
+		}
+		version = next
+	}
+}
@@ -3,11 +3,20 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"github.com/Dejan91/simple_bank/api"
 	db "github.com/Dejan91/simple_bank/db/sqlc"
 	_ "github.com/Dejan91/simple_bank/doc/statik"
 	"github.com/Dejan91/simple_bank/gapi"
+	"github.com/Dejan91/simple_bank/health"
+	"github.com/Dejan91/simple_bank/metrics"
+	"github.com/Dejan91/simple_bank/notifier"
+	"github.com/Dejan91/simple_bank/payment"
 	"github.com/Dejan91/simple_bank/pb"
+	"github.com/Dejan91/simple_bank/ratelimit"
+	"github.com/Dejan91/simple_bank/redact"
+	"github.com/Dejan91/simple_bank/risk"
+	"github.com/Dejan91/simple_bank/tracing"
 	"github.com/Dejan91/simple_bank/util"
 	"github.com/Dejan91/simple_bank/worker"
 	"github.com/golang-migrate/migrate/v4"
@@ -17,16 +26,37 @@ import (
 	"github.com/hibiken/asynq"
 	_ "github.com/lib/pq"
 	"github.com/rakyll/statik/fs"
+	"github.com/redis/go-redis/v9"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"golang.org/x/sync/errgroup"
 	"google.golang.org/grpc"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/protobuf/encoding/protojson"
 	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+const (
+	// healthCheckTimeout bounds the readiness checks of the dependencies
+	healthCheckTimeout = 3 * time.Second
+	// healthCheckInterval is how often the gRPC health service is updated
+	healthCheckInterval = 10 * time.Second
+)
+
+var interruptSignals = []os.Signal{
+	os.Interrupt,
+	syscall.SIGTERM,
+	syscall.SIGINT,
+}
+
 func main() {
 	config, err := util.LoadConfig(".")
 	if err != nil {
@@ -36,6 +66,12 @@ func main() {
 	if config.Environment == "development" {
 		log.Logger = log.Output(zerolog.ConsoleWriter{Out: os.Stderr})
 	}
+	if len(config.LogRedactFields) > 0 {
+		redact.SetFields(config.LogRedactFields)
+	}
+
+	// log.Ctx falls back to the global logger for contexts without a request scoped logger
+	zerolog.DefaultContextLogger = &log.Logger
 
 	conn, err := sql.Open(config.DBDriver, config.DBSource)
 	if err != nil {
@@ -44,16 +80,107 @@ func main() {
 
 	runDBMigration(config.MigrationURL, config.DBSource)
 
+	metrics.RegisterDBStats(conn, "simple_bank")
+
+	shutdownTracing, err := tracing.Init(context.Background(), config)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot init tracing")
+	}
+
 	store := db.NewStore(conn)
 
+	if len(os.Args) > 1 {
+		runCommand(store, os.Args[1])
+		return
+	}
+
 	redisOpt := asynq.RedisClientOpt{
 		Addr: config.RedisAddress,
 	}
 
 	taskDistributor := worker.NewRedisTaskDistributor(redisOpt)
-	go runTaskProcessor(redisOpt, store)
-	go runGatewayServer(config, store, taskDistributor)
-	runGrpcServer(config, store, taskDistributor)
+
+	accountNotifier, err := notifier.NewPGAccountNotifier(config.DBSource)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create account notifier:")
+	}
+
+	paymentRail := payment.NewSimulatedRail()
+	riskEvaluator := risk.NewRuleEvaluator(risk.DefaultConfig())
+
+	ctx, stop := signal.NotifyContext(context.Background(), interruptSignals...)
+	defer stop()
+
+	waitGroup, ctx := errgroup.WithContext(ctx)
+
+	redisClient, ok := redisOpt.MakeRedisClient().(redis.UniversalClient)
+	if !ok {
+		log.Fatal().Msg("cannot create redis client")
+	}
+
+	healthChecker := newHealthChecker(config, conn, redisClient)
+	waitGroup.Go(func() error {
+		healthChecker.Run(ctx, healthCheckInterval)
+		return nil
+	})
+
+	// the buckets are shared through redis, each replica limits on its own while redis is down
+	rateLimiter, err := gapi.NewRateLimiter(config, ratelimit.WithFallback(
+		ratelimit.NewRedisLimiter(redisClient),
+		ratelimit.NewMemoryLimiter(),
+	))
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot create rate limiter")
+	}
+
+	// every component shares the same deadline, so the whole shutdown ends within the shutdown timeout
+	shutdownCtx := shutdownDeadline(ctx, config.ShutdownTimeout)
+
+	runTaskProcessor(ctx, shutdownCtx, waitGroup, config, redisOpt, store, paymentRail, riskEvaluator)
+	runOutboxRelay(ctx, waitGroup, config, store, taskDistributor)
+
+	// the servers keep serving while the load balancers see the pod as not ready,
+	// the drain period is validated to be shorter than the shutdown timeout
+	drainCtx := healthChecker.Drain(ctx, config.ShutdownDrainPeriod)
+	runMetricsServer(drainCtx, shutdownCtx, waitGroup, config)
+	runGatewayServer(drainCtx, shutdownCtx, waitGroup, config, store, accountNotifier, paymentRail, riskEvaluator, healthChecker, rateLimiter)
+	runGrpcServer(drainCtx, shutdownCtx, waitGroup, config, store, accountNotifier, paymentRail, riskEvaluator, healthChecker, rateLimiter)
+
+	err = waitGroup.Wait()
+	if err != nil {
+		log.Error().Err(err).Msg("error from wait group")
+	}
+
+	if err := redisClient.Close(); err != nil {
+		log.Error().Err(err).Msg("failed to close redis client")
+	}
+
+	if err := accountNotifier.Close(); err != nil {
+		log.Error().Err(err).Msg("failed to close account notifier")
+	}
+
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		log.Error().Err(err).Msg("failed to flush traces")
+	}
+
+	if err := conn.Close(); err != nil {
+		log.Error().Err(err).Msg("failed to close db connection pool")
+	}
+
+	log.Info().Msg("graceful shutdown completed")
+}
+
+// shutdownDeadline returns a context which is done once the timeout is over after ctx is done,
+// the time already spent draining or stopping a component is not available to the next one
+func shutdownDeadline(ctx context.Context, timeout time.Duration) context.Context {
+	deadline, cancel := context.WithCancel(context.Background())
+
+	go func() {
+		<-ctx.Done()
+		time.AfterFunc(timeout, cancel)
+	}()
+
+	return deadline
 }
 
 func runDBMigration(migrationURL, dbSource string) {
@@ -69,24 +196,198 @@ func runDBMigration(migrationURL, dbSource string) {
 	log.Info().Msg("db migrated successfully")
 }
 
-func runTaskProcessor(redisOpt asynq.RedisClientOpt, store db.Store) {
-	taskProcessor := worker.NewRedisTaskProcessor(redisOpt, store)
+// runCommand runs a one-off maintenance command instead of the servers
+func runCommand(store db.Store, command string) {
+	switch command {
+	case "reconcile":
+		runReconciliation(store)
+	case "verify-ledger":
+		runLedgerVerification(store)
+	default:
+		log.Fatal().Msgf("unknown command: %s", command)
+	}
+}
+
+func runReconciliation(store db.Store) {
+	result, err := store.ReconcileLedgerTx(context.Background())
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to reconcile ledger:")
+	}
+
+	for _, account := range result.Details.Accounts {
+		log.Warn().
+			Int64("account_id", account.AccountID).
+			Int64("balance", account.Balance).
+			Int64("entries_sum", account.EntriesSum).
+			Msg("account balance doesn't match its entries")
+	}
+
+	for _, transfer := range result.Details.Transfers {
+		log.Warn().
+			Int64("transfer_id", transfer.TransferID).
+			Int64("amount", transfer.Amount).
+			Int64("entry_count", transfer.EntryCount).
+			Int64("from_entries_sum", transfer.FromEntriesSum).
+			Int64("to_entries_sum", transfer.ToEntriesSum).
+			Msg("transfer doesn't match its entries")
+	}
+
+	run := result.Run
+	logger := log.Info()
+	if result.HasDiscrepancies() {
+		logger = log.Error()
+	}
+
+	logger.
+		Int64("reconciliation_run_id", run.ID).
+		Int64("accounts_checked", run.AccountsChecked).
+		Int64("transfers_checked", run.TransfersChecked).
+		Int64("account_discrepancies", run.AccountDiscrepancies).
+		Int64("transfer_discrepancies", run.TransferDiscrepancies).
+		Msg("ledger reconciled")
+
+	if result.HasDiscrepancies() {
+		os.Exit(1)
+	}
+}
+
+func runLedgerVerification(store db.Store) {
+	result, err := store.VerifyEntryChainTx(context.Background())
+	if err != nil {
+		log.Fatal().Err(err).Msg("failed to verify ledger:")
+	}
+
+	for _, violation := range result.Violations {
+		log.Warn().
+			Int64("entry_id", violation.EntryID).
+			Int64("account_id", violation.AccountID).
+			Msg(violation.Reason)
+	}
+
+	logger := log.Info()
+	if len(result.Violations) > 0 {
+		logger = log.Error()
+	}
+
+	logger.
+		Int64("entries_checked", result.EntriesChecked).
+		Int("violations", len(result.Violations)).
+		Msg("ledger verified")
+
+	if len(result.Violations) > 0 {
+		os.Exit(1)
+	}
+}
+
+// newHealthChecker checks the dependencies the server needs to handle requests
+func newHealthChecker(config util.Config, conn *sql.DB, redisClient redis.UniversalClient) *health.Checker {
+	migrationVersion, err := health.LatestMigrationVersion(config.MigrationURL)
+	if err != nil {
+		log.Fatal().Err(err).Msg("cannot read latest migration version")
+	}
+
+	checker := health.NewChecker(healthCheckTimeout, pb.SimpleBank_ServiceDesc.ServiceName)
+	checker.AddCheck("postgres", health.PostgresCheck(conn))
+	checker.AddCheck("redis", health.RedisCheck(redisClient))
+	checker.AddCheck("migration", health.MigrationCheck(conn, migrationVersion))
+
+	return checker
+}
+
+func runTaskProcessor(
+	ctx context.Context,
+	shutdownCtx context.Context,
+	waitGroup *errgroup.Group,
+	config util.Config,
+	redisOpt asynq.RedisClientOpt,
+	store db.Store,
+	paymentRail payment.PaymentRail,
+	riskEvaluator db.RiskEvaluator,
+) {
+	taskProcessor := worker.NewRedisTaskProcessor(redisOpt, store, config, paymentRail, riskEvaluator)
 	log.Info().Msg("start task processor")
 	err := taskProcessor.Start()
 	if err != nil {
 		log.Fatal().Err(err).Msg("failed to start task processor")
 	}
+
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		log.Info().Msg("graceful shutdown task processor")
+
+		// the processor waits up to the shutdown timeout for the running tasks,
+		// which started with the shutdown deadline, so stop waiting for it once the deadline is over
+		stopped := make(chan struct{})
+		go func() {
+			taskProcessor.Shutdown()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			log.Warn().Msg("task processor shutdown timed out")
+			return nil
+		}
+
+		log.Info().Msg("task processor is stopped")
+		return nil
+	})
+}
+
+// runOutboxRelay publishes the tasks committed to the outbox until the server shuts down
+func runOutboxRelay(
+	ctx context.Context,
+	waitGroup *errgroup.Group,
+	config util.Config,
+	store db.Store,
+	taskDistributor worker.TaskDistributor,
+) {
+	relay := worker.NewOutboxRelay(store, taskDistributor, config.OutboxRelayInterval)
+
+	waitGroup.Go(func() error {
+		log.Info().Msg("start outbox relay")
+		relay.Run(ctx)
+		log.Info().Msg("outbox relay is stopped")
+
+		return nil
+	})
 }
 
-func runGrpcServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor) {
-	server, err := gapi.NewServer(config, store, taskDistributor)
+func runGrpcServer(
+	ctx context.Context,
+	shutdownCtx context.Context,
+	waitGroup *errgroup.Group,
+	config util.Config,
+	store db.Store,
+	accountNotifier notifier.AccountNotifier,
+	paymentRail payment.PaymentRail,
+	riskEvaluator db.RiskEvaluator,
+	healthChecker *health.Checker,
+	rateLimiter *gapi.RateLimiter,
+) {
+	server, err := gapi.NewServer(config, store, accountNotifier, paymentRail, riskEvaluator)
 	if err != nil {
 		log.Fatal().Err(err).Msg("cannot create server:")
 	}
 
-	grpcLogger := grpc.UnaryInterceptor(gapi.GrpcLogger)
-	grpcServer := grpc.NewServer(grpcLogger)
+	unaryInterceptors := grpc.ChainUnaryInterceptor(
+		otelgrpc.UnaryServerInterceptor(),
+		gapi.GrpcRequestID,
+		gapi.GrpcLogger,
+		gapi.GrpcMetrics,
+		rateLimiter.GrpcRateLimit,
+	)
+	streamInterceptors := grpc.ChainStreamInterceptor(
+		otelgrpc.StreamServerInterceptor(),
+		gapi.GrpcStreamRequestID,
+		gapi.GrpcStreamLogger,
+		gapi.GrpcStreamMetrics,
+		rateLimiter.GrpcStreamRateLimit,
+	)
+	grpcServer := grpc.NewServer(unaryInterceptors, streamInterceptors)
 	pb.RegisterSimpleBankServer(grpcServer, server)
+	healthpb.RegisterHealthServer(grpcServer, healthChecker.GRPCServer())
 	reflection.Register(grpcServer)
 
 	listener, err := net.Listen("tcp", config.GRPCServerAddress)
@@ -94,15 +395,56 @@ func runGrpcServer(config util.Config, store db.Store, taskDistributor worker.Ta
 		log.Fatal().Err(err).Msg("cannot create listener:")
 	}
 
-	log.Info().Msgf("start gRPC server at %s", listener.Addr().String())
-	err = grpcServer.Serve(listener)
-	if err != nil {
-		log.Fatal().Err(err).Msg("cannot start gRPC server")
-	}
+	waitGroup.Go(func() error {
+		log.Info().Msgf("start gRPC server at %s", listener.Addr().String())
+		err = grpcServer.Serve(listener)
+		if err != nil {
+			if errors.Is(err, grpc.ErrServerStopped) {
+				return nil
+			}
+			log.Error().Err(err).Msg("gRPC server failed to serve")
+			return err
+		}
+
+		return nil
+	})
+
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		log.Info().Msg("graceful shutdown gRPC server")
+
+		// GracefulStop waits for the streaming calls too, so stop the server for good once the timeout is over
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+
+		select {
+		case <-stopped:
+		case <-shutdownCtx.Done():
+			log.Warn().Msg("gRPC server shutdown timed out")
+			grpcServer.Stop()
+		}
+
+		log.Info().Msg("gRPC server is stopped")
+		return nil
+	})
 }
 
-func runGatewayServer(config util.Config, store db.Store, taskDistributor worker.TaskDistributor) {
-	server, err := gapi.NewServer(config, store, taskDistributor)
+func runGatewayServer(
+	ctx context.Context,
+	shutdownCtx context.Context,
+	waitGroup *errgroup.Group,
+	config util.Config,
+	store db.Store,
+	accountNotifier notifier.AccountNotifier,
+	paymentRail payment.PaymentRail,
+	riskEvaluator db.RiskEvaluator,
+	healthChecker *health.Checker,
+	rateLimiter *gapi.RateLimiter,
+) {
+	server, err := gapi.NewServer(config, store, accountNotifier, paymentRail, riskEvaluator)
 	if err != nil {
 		log.Fatal().Err(err).Msg("cannot create server:")
 	}
@@ -116,9 +458,12 @@ func runGatewayServer(config util.Config, store db.Store, taskDistributor worker
 		},
 	})
 
-	grpcMux := runtime.NewServeMux(jsonOption)
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+	grpcMux := runtime.NewServeMux(
+		jsonOption,
+		runtime.WithMetadata(gapi.HttpRouteAnnotator),
+		runtime.WithIncomingHeaderMatcher(gapi.HttpHeaderMatcher),
+		runtime.WithErrorHandler(gapi.HttpErrorHandler),
+	)
 
 	err = pb.RegisterSimpleBankHandlerServer(ctx, grpcMux, server)
 	if err != nil {
@@ -136,18 +481,81 @@ func runGatewayServer(config util.Config, store db.Store, taskDistributor worker
 
 	swaggerHandler := http.StripPrefix("/swagger/", http.FileServer(statikFS))
 	mux.Handle("/swagger/", swaggerHandler)
+	mux.Handle("/healthz", healthChecker.LivenessHandler())
+	mux.Handle("/readyz", healthChecker.ReadinessHandler())
 
-	listener, err := net.Listen("tcp", config.HTTPServerAddress)
-	if err != nil {
-		log.Fatal().Err(err).Msg("cannot create listener")
+	handler := gapi.HttpRequestID(gapi.HttpLogger(gapi.HttpMetrics(rateLimiter.HttpRateLimit(mux))))
+	httpServer := &http.Server{
+		Handler: otelhttp.NewHandler(handler, "gateway"),
+		Addr:    config.HTTPServerAddress,
 	}
 
-	log.Printf("start HTTP gateway server at %s", listener.Addr().String())
-	handler := gapi.HttpLogger(mux)
-	err = http.Serve(listener, handler)
-	if err != nil {
-		log.Fatal().Err(err).Msg("cannot start HTTP gateway server")
+	waitGroup.Go(func() error {
+		log.Info().Msgf("start HTTP gateway server at %s", httpServer.Addr)
+		err = httpServer.ListenAndServe()
+		if err != nil {
+			if errors.Is(err, http.ErrServerClosed) {
+				return nil
+			}
+			log.Error().Err(err).Msg("HTTP gateway server failed to serve")
+			return err
+		}
+
+		return nil
+	})
+
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		log.Info().Msg("graceful shutdown HTTP gateway server")
+
+		err := httpServer.Shutdown(shutdownCtx)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to shutdown HTTP gateway server")
+			return err
+		}
+
+		log.Info().Msg("HTTP gateway server is stopped")
+		return nil
+	})
+}
+
+// runMetricsServer serves the metrics on their own port, which is kept off the public load balancer
+func runMetricsServer(ctx context.Context, shutdownCtx context.Context, waitGroup *errgroup.Group, config util.Config) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", metrics.Handler())
+
+	httpServer := &http.Server{
+		Handler: mux,
+		Addr:    config.MetricsServerAddress,
 	}
+
+	waitGroup.Go(func() error {
+		log.Info().Msgf("start metrics server at %s", httpServer.Addr)
+		err := httpServer.ListenAndServe()
+		if err != nil {
+			if errors.Is(err, http.ErrServerClosed) {
+				return nil
+			}
+			log.Error().Err(err).Msg("metrics server failed to serve")
+			return err
+		}
+
+		return nil
+	})
+
+	waitGroup.Go(func() error {
+		<-ctx.Done()
+		log.Info().Msg("graceful shutdown metrics server")
+
+		err := httpServer.Shutdown(shutdownCtx)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to shutdown metrics server")
+			return err
+		}
+
+		log.Info().Msg("metrics server is stopped")
+		return nil
+	})
 }
 
 func runGinServer(config util.Config, store db.Store) {
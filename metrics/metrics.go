@@ -0,0 +1,134 @@
+package metrics
+
+import (
+	"database/sql"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"net/http"
+	"time"
+)
+
+const namespace = "simple_bank"
+
+var (
+	requestsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "requests_total",
+		Help:      "Number of handled gRPC and HTTP requests.",
+	}, []string{"protocol", "method", "status"})
+
+	requestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "request_duration_seconds",
+		Help:      "Duration of the handled gRPC and HTTP requests.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"protocol", "method", "status"})
+
+	transfersTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfers_total",
+		Help:      "Number of completed transfers.",
+	}, []string{"currency"})
+
+	transferAmountTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfer_amount_total",
+		Help:      "Amount of money moved by the completed transfers, in the smallest unit of the currency.",
+	}, []string{"currency"})
+
+	transferTxRetriesTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfer_tx_retries_total",
+		Help:      "Number of transfer transactions retried after a serialization failure or a deadlock.",
+	})
+
+	transferTxFailuresTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "transfer_tx_failures_total",
+		Help:      "Number of failed transfer transactions.",
+	}, []string{"reason"})
+
+	tasksProcessedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tasks_processed_total",
+		Help:      "Number of processed worker tasks.",
+	}, []string{"task_type"})
+
+	tasksFailedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tasks_failed_total",
+		Help:      "Number of worker tasks that returned an error.",
+	}, []string{"task_type"})
+
+	tasksRetriedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "tasks_retried_total",
+		Help:      "Number of failed worker tasks that are scheduled to be retried.",
+	}, []string{"task_type"})
+
+	reconciliationDiscrepancies = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "reconciliation_discrepancies",
+		Help:      "Number of accounts whose balance and of transfers whose entries did not match in the last ledger reconciliation.",
+	}, []string{"kind"})
+
+	reconciliationLastRun = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "reconciliation_last_run_timestamp_seconds",
+		Help:      "Unix time of the last completed ledger reconciliation.",
+	})
+)
+
+// Handler serves the collected metrics in the Prometheus exposition format
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// RegisterDBStats exports the connection pool statistics of the database
+func RegisterDBStats(db *sql.DB, dbName string) {
+	prometheus.MustRegister(collectors.NewDBStatsCollector(db, dbName))
+}
+
+// ObserveRequest records a handled request with its duration
+func ObserveRequest(protocol string, method string, status string, duration time.Duration) {
+	requestsTotal.WithLabelValues(protocol, method, status).Inc()
+	requestDuration.WithLabelValues(protocol, method, status).Observe(duration.Seconds())
+}
+
+// ObserveTransfer records a completed transfer
+func ObserveTransfer(currency string, amount int64) {
+	transfersTotal.WithLabelValues(currency).Inc()
+	transferAmountTotal.WithLabelValues(currency).Add(float64(amount))
+}
+
+// ObserveTransferTxRetry records a retry of a transfer transaction
+func ObserveTransferTxRetry() {
+	transferTxRetriesTotal.Inc()
+}
+
+// ObserveTransferTxFailure records a failed transfer transaction
+func ObserveTransferTxFailure(reason string) {
+	transferTxFailuresTotal.WithLabelValues(reason).Inc()
+}
+
+// ObserveTask records a processed worker task, retried tells whether a failed task will run again
+func ObserveTask(taskType string, err error, retried bool) {
+	tasksProcessedTotal.WithLabelValues(taskType).Inc()
+	if err == nil {
+		return
+	}
+
+	tasksFailedTotal.WithLabelValues(taskType).Inc()
+	if retried {
+		tasksRetriedTotal.WithLabelValues(taskType).Inc()
+	}
+}
+
+// ObserveReconciliation records the mismatches found by a completed ledger reconciliation
+func ObserveReconciliation(accountDiscrepancies int64, transferDiscrepancies int64, finishedAt time.Time) {
+	reconciliationDiscrepancies.WithLabelValues("account").Set(float64(accountDiscrepancies))
+	reconciliationDiscrepancies.WithLabelValues("transfer").Set(float64(transferDiscrepancies))
+	reconciliationLastRun.Set(float64(finishedAt.Unix()))
+}
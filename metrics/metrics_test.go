@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveRequest(t *testing.T) {
+	method := util.RandomString(10)
+
+	ObserveRequest("grpc", method, "OK", time.Second)
+	ObserveRequest("grpc", method, "OK", time.Second)
+	ObserveRequest("grpc", method, "NotFound", time.Millisecond)
+
+	require.Equal(t, float64(2), testutil.ToFloat64(requestsTotal.WithLabelValues("grpc", method, "OK")))
+	require.Equal(t, float64(1), testutil.ToFloat64(requestsTotal.WithLabelValues("grpc", method, "NotFound")))
+}
+
+func TestObserveTransfer(t *testing.T) {
+	currency := util.RandomString(3)
+
+	ObserveTransfer(currency, 100)
+	ObserveTransfer(currency, 50)
+
+	require.Equal(t, float64(2), testutil.ToFloat64(transfersTotal.WithLabelValues(currency)))
+	require.Equal(t, float64(150), testutil.ToFloat64(transferAmountTotal.WithLabelValues(currency)))
+}
+
+func TestObserveTask(t *testing.T) {
+	taskType := util.RandomString(10)
+
+	ObserveTask(taskType, nil, false)
+	ObserveTask(taskType, errors.New("failed"), true)
+	ObserveTask(taskType, errors.New("failed"), false)
+
+	require.Equal(t, float64(3), testutil.ToFloat64(tasksProcessedTotal.WithLabelValues(taskType)))
+	require.Equal(t, float64(2), testutil.ToFloat64(tasksFailedTotal.WithLabelValues(taskType)))
+	require.Equal(t, float64(1), testutil.ToFloat64(tasksRetriedTotal.WithLabelValues(taskType)))
+}
+
+func TestObserveReconciliation(t *testing.T) {
+	finishedAt := time.Now()
+
+	ObserveReconciliation(3, 0, finishedAt)
+
+	require.Equal(t, float64(3), testutil.ToFloat64(reconciliationDiscrepancies.WithLabelValues("account")))
+	require.Equal(t, float64(0), testutil.ToFloat64(reconciliationDiscrepancies.WithLabelValues("transfer")))
+	require.Equal(t, float64(finishedAt.Unix()), testutil.ToFloat64(reconciliationLastRun))
+
+	// the gauges hold the outcome of the last run only
+	ObserveReconciliation(0, 1, finishedAt)
+
+	require.Equal(t, float64(0), testutil.ToFloat64(reconciliationDiscrepancies.WithLabelValues("account")))
+	require.Equal(t, float64(1), testutil.ToFloat64(reconciliationDiscrepancies.WithLabelValues("transfer")))
+}
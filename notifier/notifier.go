@@ -0,0 +1,140 @@
+package notifier
+
+import (
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/lib/pq"
+	"github.com/rs/zerolog/log"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	minReconnectInterval = 10 * time.Second
+	maxReconnectInterval = time.Minute
+	pingInterval         = 90 * time.Second
+)
+
+// AccountNotifier fans out account update notifications to subscribers
+type AccountNotifier interface {
+	// Subscribe registers interest in updates of an account.
+	// The returned channel receives a signal whenever the account may have changed,
+	// and the returned function must be called to release the subscription.
+	Subscribe(accountID int64) (<-chan struct{}, func())
+
+	// Close stops listening for notifications
+	Close() error
+}
+
+// PGAccountNotifier is an AccountNotifier driven by Postgres LISTEN/NOTIFY
+type PGAccountNotifier struct {
+	listener    *pq.Listener
+	mu          sync.Mutex
+	subscribers map[int64]map[chan struct{}]struct{}
+}
+
+// NewPGAccountNotifier creates a new PGAccountNotifier and starts listening on db.AccountUpdatedChannel
+func NewPGAccountNotifier(dbSource string) (AccountNotifier, error) {
+	listener := pq.NewListener(dbSource, minReconnectInterval, maxReconnectInterval, func(event pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Error().Err(err).Msg("account notifier connection error")
+		}
+	})
+
+	if err := listener.Listen(db.AccountUpdatedChannel); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("cannot listen on %s: %w", db.AccountUpdatedChannel, err)
+	}
+
+	n := &PGAccountNotifier{
+		listener:    listener,
+		subscribers: make(map[int64]map[chan struct{}]struct{}),
+	}
+
+	go n.run()
+	return n, nil
+}
+
+func (n *PGAccountNotifier) Subscribe(accountID int64) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	n.mu.Lock()
+	if n.subscribers[accountID] == nil {
+		n.subscribers[accountID] = make(map[chan struct{}]struct{})
+	}
+	n.subscribers[accountID][ch] = struct{}{}
+	n.mu.Unlock()
+
+	unsubscribe := func() {
+		n.mu.Lock()
+		defer n.mu.Unlock()
+
+		delete(n.subscribers[accountID], ch)
+		if len(n.subscribers[accountID]) == 0 {
+			delete(n.subscribers, accountID)
+		}
+	}
+
+	return ch, unsubscribe
+}
+
+func (n *PGAccountNotifier) Close() error {
+	return n.listener.Close()
+}
+
+func (n *PGAccountNotifier) run() {
+	for {
+		select {
+		case notification, ok := <-n.listener.Notify:
+			if !ok {
+				return
+			}
+
+			// a nil notification means the connection was re-established
+			// and notifications may have been lost, so wake everybody up
+			if notification == nil {
+				n.broadcast()
+				continue
+			}
+
+			accountID, err := strconv.ParseInt(notification.Extra, 10, 64)
+			if err != nil {
+				log.Error().Err(err).Str("payload", notification.Extra).Msg("invalid account notification payload")
+				continue
+			}
+
+			n.notify(accountID)
+		case <-time.After(pingInterval):
+			go n.listener.Ping()
+		}
+	}
+}
+
+func (n *PGAccountNotifier) notify(accountID int64) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for ch := range n.subscribers[accountID] {
+		signal(ch)
+	}
+}
+
+func (n *PGAccountNotifier) broadcast() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	for _, subscribers := range n.subscribers {
+		for ch := range subscribers {
+			signal(ch)
+		}
+	}
+}
+
+// signal wakes up a subscriber without blocking; pending signals are coalesced
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,32 @@
+package payment
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrDeclined is returned when the rail refuses to move the money
+var ErrDeclined = errors.New("payment declined")
+
+// Request describes money moved between the bank and an external account
+type Request struct {
+	// Reference identifies the payment in the bank, the rail moves the money of a reference
+	// once and returns the same payment again, so a request can be retried safely
+	Reference string
+	// ExternalAccount is the funding source of a collection or the destination of a payout
+	ExternalAccount string
+	Amount          int64
+	Currency        string
+}
+
+// PaymentRail moves money between the bank and external funding sources
+type PaymentRail interface {
+	// Name identifies the rail in the recorded payments
+	Name() string
+	// Collect pulls money from an external account and returns the reference of the payment
+	Collect(ctx context.Context, req Request) (string, error)
+	// Payout sends money to an external account and returns the reference of the payment
+	Payout(ctx context.Context, req Request) (string, error)
+	// Refund gives the money of a collection back to its external account
+	Refund(ctx context.Context, externalRef string) error
+}
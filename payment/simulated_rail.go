@@ -0,0 +1,73 @@
+package payment
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"strings"
+	"sync"
+)
+
+// declinedAccountPrefix marks the external accounts the simulated rail declines
+const declinedAccountPrefix = "decline"
+
+// SimulatedRail is a payment rail which doesn't move any real money,
+// it accepts every payment except for the external accounts starting with "decline"
+type SimulatedRail struct {
+	mutex sync.Mutex
+	// payments maps the references of the accepted payments to their external reference
+	payments map[string]string
+}
+
+// NewSimulatedRail creates a new SimulatedRail
+func NewSimulatedRail() PaymentRail {
+	return &SimulatedRail{
+		payments: make(map[string]string),
+	}
+}
+
+func (rail *SimulatedRail) Name() string {
+	return "simulated"
+}
+
+func (rail *SimulatedRail) Collect(ctx context.Context, req Request) (string, error) {
+	return rail.process("collect", req)
+}
+
+func (rail *SimulatedRail) Payout(ctx context.Context, req Request) (string, error) {
+	return rail.process("payout", req)
+}
+
+func (rail *SimulatedRail) Refund(ctx context.Context, externalRef string) error {
+	if !strings.HasPrefix(externalRef, "sim_") {
+		return fmt.Errorf("unknown payment %s", externalRef)
+	}
+
+	return nil
+}
+
+func (rail *SimulatedRail) process(kind string, req Request) (string, error) {
+	if strings.HasPrefix(req.ExternalAccount, declinedAccountPrefix) {
+		return "", fmt.Errorf("%w: external account %s", ErrDeclined, req.ExternalAccount)
+	}
+
+	rail.mutex.Lock()
+	defer rail.mutex.Unlock()
+
+	key := kind + ":" + req.Reference
+	if externalRef, ok := rail.payments[key]; ok && req.Reference != "" {
+		return externalRef, nil
+	}
+
+	ref, err := uuid.NewRandom()
+	if err != nil {
+		return "", err
+	}
+
+	externalRef := "sim_" + ref.String()
+	if req.Reference != "" {
+		rail.payments[key] = externalRef
+	}
+
+	return externalRef, nil
+}
@@ -0,0 +1,70 @@
+package payment
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSimulatedRail(t *testing.T) {
+	rail := NewSimulatedRail()
+
+	req := Request{
+		ExternalAccount: util.RandomString(10),
+		Amount:          util.RandomMoney(),
+		Currency:        util.RandomCurrency(),
+	}
+
+	ref1, err := rail.Collect(context.Background(), req)
+	require.NoError(t, err)
+	require.NotEmpty(t, ref1)
+
+	ref2, err := rail.Payout(context.Background(), req)
+	require.NoError(t, err)
+	require.NotEmpty(t, ref2)
+	require.NotEqual(t, ref1, ref2)
+}
+
+func TestSimulatedRailDeclined(t *testing.T) {
+	rail := NewSimulatedRail()
+
+	req := Request{
+		ExternalAccount: "declined_" + util.RandomString(6),
+		Amount:          util.RandomMoney(),
+		Currency:        util.RandomCurrency(),
+	}
+
+	_, err := rail.Collect(context.Background(), req)
+	require.ErrorIs(t, err, ErrDeclined)
+
+	_, err = rail.Payout(context.Background(), req)
+	require.ErrorIs(t, err, ErrDeclined)
+}
+
+func TestSimulatedRailIdempotent(t *testing.T) {
+	rail := NewSimulatedRail()
+
+	req := Request{
+		Reference:       util.RandomString(6),
+		ExternalAccount: util.RandomString(10),
+		Amount:          util.RandomMoney(),
+		Currency:        util.RandomCurrency(),
+	}
+
+	ref1, err := rail.Payout(context.Background(), req)
+	require.NoError(t, err)
+
+	// a retried payout returns the same payment
+	ref2, err := rail.Payout(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, ref1, ref2)
+
+	ref3, err := rail.Collect(context.Background(), req)
+	require.NoError(t, err)
+	require.NotEqual(t, ref1, ref3)
+
+	require.NoError(t, rail.Refund(context.Background(), ref3))
+	require.Error(t, rail.Refund(context.Background(), "unknown"))
+}
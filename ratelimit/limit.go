@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit is a token bucket refilled with Rate tokens per second and holding up to Burst tokens
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Result tells whether a request is allowed, and if not, when the next token is available
+type Result struct {
+	Allowed    bool
+	RetryAfter time.Duration
+}
+
+// Limiter takes a token from the bucket of the key
+type Limiter interface {
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+var limitPeriods = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+}
+
+// ParseLimit parses a limit written as count/period, like 5/m, the whole count can be used at once
+func ParseLimit(value string) (Limit, error) {
+	count, period, ok := strings.Cut(strings.TrimSpace(value), "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: must be count/period", value)
+	}
+
+	burst, err := strconv.Atoi(count)
+	if err != nil || burst < 1 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: count must be a positive integer", value)
+	}
+
+	duration, ok := limitPeriods[period]
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid rate limit %q: period must be s, m or h", value)
+	}
+
+	limit := Limit{
+		Rate:  float64(burst) / duration.Seconds(),
+		Burst: burst,
+	}
+
+	return limit, nil
+}
+
+// ParseMethodLimits parses the limits of the methods written as method=count/period
+func ParseMethodLimits(values []string) (map[string]Limit, error) {
+	limits := make(map[string]Limit, len(values))
+	for _, value := range values {
+		method, limitValue, ok := strings.Cut(value, "=")
+		if !ok || strings.TrimSpace(method) == "" {
+			return nil, fmt.Errorf("invalid method rate limit %q: must be method=count/period", value)
+		}
+
+		limit, err := ParseLimit(limitValue)
+		if err != nil {
+			return nil, err
+		}
+
+		limits[strings.TrimSpace(method)] = limit
+	}
+
+	return limits, nil
+}
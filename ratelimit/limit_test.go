@@ -0,0 +1,37 @@
+package ratelimit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	limit, err := ParseLimit("5/m")
+	require.NoError(t, err)
+	require.Equal(t, 5, limit.Burst)
+	require.InDelta(t, 5.0/60, limit.Rate, 1e-9)
+
+	limit, err = ParseLimit(" 10/s ")
+	require.NoError(t, err)
+	require.Equal(t, Limit{Rate: 10, Burst: 10}, limit)
+
+	for _, value := range []string{"", "5", "0/m", "-1/s", "x/m", "5/d"} {
+		_, err = ParseLimit(value)
+		require.Error(t, err, value)
+	}
+}
+
+func TestParseMethodLimits(t *testing.T) {
+	limits, err := ParseMethodLimits([]string{"LoginUser=5/m", "CreateUser=3/h"})
+	require.NoError(t, err)
+	require.Len(t, limits, 2)
+	require.Equal(t, 5, limits["LoginUser"].Burst)
+	require.Equal(t, 3, limits["CreateUser"].Burst)
+
+	_, err = ParseMethodLimits([]string{"LoginUser"})
+	require.Error(t, err)
+
+	_, err = ParseMethodLimits([]string{"=5/m"})
+	require.Error(t, err)
+}
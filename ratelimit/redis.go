@@ -0,0 +1,110 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"github.com/redis/go-redis/v9"
+	"github.com/rs/zerolog/log"
+	"strconv"
+	"time"
+)
+
+const redisKeyPrefix = "ratelimit:"
+
+// tokenBucketScript refills and takes a token atomically, using the clock of redis so the replicas agree;
+// it returns whether the token was taken and the seconds until the next token as a string, as lua numbers
+// are truncated to integers in the reply
+var tokenBucketScript = redis.NewScript(`
+local rate = tonumber(ARGV[1])
+local burst = tonumber(ARGV[2])
+local time = redis.call('TIME')
+local now = tonumber(time[1]) + tonumber(time[2]) / 1000000
+
+local bucket = redis.call('HMGET', KEYS[1], 'tokens', 'updated_at')
+local tokens = tonumber(bucket[1])
+local updated_at = tonumber(bucket[2])
+if tokens == nil or updated_at == nil then
+	tokens = burst
+	updated_at = now
+end
+
+tokens = math.min(burst, tokens + math.max(0, now - updated_at) * rate)
+
+local allowed = 0
+local retry_after = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+else
+	retry_after = (1 - tokens) / rate
+end
+
+redis.call('HSET', KEYS[1], 'tokens', tostring(tokens), 'updated_at', tostring(now))
+redis.call('EXPIRE', KEYS[1], math.ceil(burst / rate) + 1)
+
+return {allowed, tostring(retry_after)}
+`)
+
+// RedisLimiter shares the buckets between the replicas through redis
+type RedisLimiter struct {
+	client redis.UniversalClient
+}
+
+func NewRedisLimiter(client redis.UniversalClient) *RedisLimiter {
+	return &RedisLimiter{client: client}
+}
+
+func (l *RedisLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	reply, err := tokenBucketScript.Run(ctx, l.client, []string{redisKeyPrefix + key}, limit.Rate, limit.Burst).Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("failed to run token bucket script: %w", err)
+	}
+
+	if len(reply) != 2 {
+		return Result{}, fmt.Errorf("unexpected token bucket reply: %v", reply)
+	}
+
+	allowed, ok := reply[0].(int64)
+	if !ok {
+		return Result{}, fmt.Errorf("unexpected token bucket reply: %v", reply)
+	}
+
+	retryAfterText, ok := reply[1].(string)
+	if !ok {
+		return Result{}, fmt.Errorf("unexpected token bucket reply: %v", reply)
+	}
+
+	retryAfterSeconds, err := strconv.ParseFloat(retryAfterText, 64)
+	if err != nil {
+		return Result{}, fmt.Errorf("unexpected token bucket reply: %w", err)
+	}
+
+	result := Result{
+		Allowed:    allowed == 1,
+		RetryAfter: time.Duration(retryAfterSeconds * float64(time.Second)),
+	}
+
+	return result, nil
+}
+
+// fallbackLimiter uses the fallback limiter while the primary one fails
+type fallbackLimiter struct {
+	primary  Limiter
+	fallback Limiter
+}
+
+// WithFallback limits with the fallback limiter when the primary one fails, like when redis is down,
+// so requests are still limited per replica instead of being rejected or let through
+func WithFallback(primary Limiter, fallback Limiter) Limiter {
+	return &fallbackLimiter{primary: primary, fallback: fallback}
+}
+
+func (l *fallbackLimiter) Allow(ctx context.Context, key string, limit Limit) (Result, error) {
+	result, err := l.primary.Allow(ctx, key, limit)
+	if err == nil {
+		return result, nil
+	}
+
+	log.Ctx(ctx).Warn().Err(err).Msg("rate limiter failed, falling back")
+	return l.fallback.Allow(ctx, key, limit)
+}
@@ -0,0 +1,95 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+)
+
+// Mask replaces the values of the sensitive fields
+const Mask = "[REDACTED]"
+
+// DefaultFields are the sensitive fields masked when none are configured
+var DefaultFields = []string{"password", "refresh_token", "access_token", "email"}
+
+// Redactor masks the values of sensitive fields in JSON documents before they are logged
+type Redactor struct {
+	fields map[string]bool
+}
+
+// New creates a redactor for the given fields, the names match both the snake_case
+// and the camelCase form of the fields
+func New(fields []string) *Redactor {
+	r := &Redactor{fields: make(map[string]bool, len(fields))}
+	for _, field := range fields {
+		if field = normalizeField(field); field != "" {
+			r.fields[field] = true
+		}
+	}
+
+	return r
+}
+
+// IsSensitive tells whether the value of the field must not be logged
+func (r *Redactor) IsSensitive(field string) bool {
+	return r.fields[normalizeField(field)]
+}
+
+// JSON returns a copy of the JSON document with the sensitive fields masked at any depth,
+// data that is not valid JSON is masked entirely as it cannot be inspected
+func (r *Redactor) JSON(data []byte) []byte {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return data
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var document interface{}
+	if err := decoder.Decode(&document); err != nil {
+		return []byte(Mask)
+	}
+
+	redacted, err := json.Marshal(r.redact(document))
+	if err != nil {
+		return []byte(Mask)
+	}
+
+	return redacted
+}
+
+func (r *Redactor) redact(value interface{}) interface{} {
+	switch value := value.(type) {
+	case map[string]interface{}:
+		for key, field := range value {
+			if r.IsSensitive(key) {
+				value[key] = Mask
+				continue
+			}
+			value[key] = r.redact(field)
+		}
+	case []interface{}:
+		for i, item := range value {
+			value[i] = r.redact(item)
+		}
+	}
+
+	return value
+}
+
+func normalizeField(field string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(field), "_", ""))
+}
+
+var defaultRedactor = New(DefaultFields)
+
+// SetFields replaces the sensitive fields of the package level redactor,
+// it must be called at startup before anything is logged
+func SetFields(fields []string) {
+	defaultRedactor = New(fields)
+}
+
+// JSON masks the sensitive fields of the JSON document with the package level redactor
+func JSON(data []byte) []byte {
+	return defaultRedactor.JSON(data)
+}
@@ -0,0 +1,66 @@
+package redact
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedactorJSON(t *testing.T) {
+	redactor := New(DefaultFields)
+
+	testCases := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{
+			name:     "TopLevel",
+			input:    `{"username":"alice","password":"secret"}`,
+			expected: `{"password":"[REDACTED]","username":"alice"}`,
+		},
+		{
+			name:     "Nested",
+			input:    `{"user":{"email":"alice@email.com","full_name":"Alice"},"access_token":"v2.local.token"}`,
+			expected: `{"access_token":"[REDACTED]","user":{"email":"[REDACTED]","full_name":"Alice"}}`,
+		},
+		{
+			name:     "CamelCase",
+			input:    `{"refreshToken":"v2.local.token","refreshTokenExpiresAt":"2023-01-01T00:00:00Z"}`,
+			expected: `{"refreshToken":"[REDACTED]","refreshTokenExpiresAt":"2023-01-01T00:00:00Z"}`,
+		},
+		{
+			name:     "Array",
+			input:    `[{"email":"alice@email.com"},{"balance":12345678901234567890}]`,
+			expected: `[{"email":"[REDACTED]"},{"balance":12345678901234567890}]`,
+		},
+		{
+			name:     "NotJSON",
+			input:    `password=secret`,
+			expected: Mask,
+		},
+		{
+			name:     "Empty",
+			input:    ``,
+			expected: ``,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, string(redactor.JSON([]byte(tc.input))))
+		})
+	}
+}
+
+func TestRedactorIsSensitive(t *testing.T) {
+	redactor := New([]string{"refresh_token", " Email "})
+
+	require.True(t, redactor.IsSensitive("refresh_token"))
+	require.True(t, redactor.IsSensitive("refreshToken"))
+	require.True(t, redactor.IsSensitive("email"))
+	require.False(t, redactor.IsSensitive("password"))
+	require.False(t, redactor.IsSensitive("refresh_token_expires_at"))
+}
@@ -0,0 +1,102 @@
+package risk
+
+import (
+	"context"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"time"
+)
+
+// Config tunes the thresholds of the built-in rules
+type Config struct {
+	// LargeAmount is the smallest amount sent to a new payee which needs a review
+	LargeAmount int64
+	// BurstCount is the number of transfers within BurstWindow which needs a review
+	BurstCount  int64
+	BurstWindow time.Duration
+	// NewDeviceAge is how long a device or IP must have been used to log in before it is trusted
+	NewDeviceAge time.Duration
+	// DrainPercent is the share of the available balance a single transfer may move without a review
+	DrainPercent int64
+	// DrainMinAmount keeps small transfers from being flagged for draining small balances
+	DrainMinAmount int64
+	// DenyScore is the number of rules asking for a review which denies the transfer instead
+	DenyScore int
+}
+
+// DefaultConfig returns the thresholds used by the servers
+func DefaultConfig() Config {
+	return Config{
+		LargeAmount:    1_000,
+		BurstCount:     5,
+		BurstWindow:    10 * time.Minute,
+		NewDeviceAge:   24 * time.Hour,
+		DrainPercent:   90,
+		DrainMinAmount: 100,
+		DenyScore:      3,
+	}
+}
+
+// Rule inspects a single aspect of a transfer and returns the decision it asks for,
+// with the reason explaining it when the transfer is not allowed
+type Rule interface {
+	Check(ctx context.Context, q db.Querier, input db.RiskInput) (db.RiskDecision, string, error)
+}
+
+// RuleEvaluator is a RiskEvaluator combining the decisions of a list of rules
+type RuleEvaluator struct {
+	rules     []Rule
+	denyScore int
+}
+
+// NewRuleEvaluator creates a new RuleEvaluator with the built-in rules
+func NewRuleEvaluator(config Config) db.RiskEvaluator {
+	return NewRuleEvaluatorWithRules(config.DenyScore,
+		NewPayeeRule(config.LargeAmount),
+		BurstRule(config.BurstCount, config.BurstWindow),
+		NewDeviceRule(config.NewDeviceAge),
+		DrainRule(config.DrainPercent, config.DrainMinAmount),
+	)
+}
+
+// NewRuleEvaluatorWithRules creates a new RuleEvaluator with the given rules,
+// a denyScore of zero never escalates the reviews to a denial
+func NewRuleEvaluatorWithRules(denyScore int, rules ...Rule) *RuleEvaluator {
+	return &RuleEvaluator{
+		rules:     rules,
+		denyScore: denyScore,
+	}
+}
+
+// Evaluate returns the strictest decision of the rules,
+// and denies the transfer when enough rules ask for a review
+func (evaluator *RuleEvaluator) Evaluate(ctx context.Context, q db.Querier, input db.RiskInput) (db.RiskAssessment, error) {
+	assessment := db.RiskAssessment{Decision: db.RiskDecisionAllow}
+	reviews := 0
+
+	for _, rule := range evaluator.rules {
+		decision, reason, err := rule.Check(ctx, q, input)
+		if err != nil {
+			return assessment, err
+		}
+
+		switch decision {
+		case db.RiskDecisionDeny:
+			assessment.Decision = db.RiskDecisionDeny
+		case db.RiskDecisionReview:
+			reviews++
+			if assessment.Decision == db.RiskDecisionAllow {
+				assessment.Decision = db.RiskDecisionReview
+			}
+		default:
+			continue
+		}
+
+		assessment.Reasons = append(assessment.Reasons, reason)
+	}
+
+	if evaluator.denyScore > 0 && reviews >= evaluator.denyScore {
+		assessment.Decision = db.RiskDecisionDeny
+	}
+
+	return assessment, nil
+}
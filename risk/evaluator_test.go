@@ -0,0 +1,175 @@
+package risk
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	mockdb "github.com/Dejan91/simple_bank/db/mock"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func randomInput() db.RiskInput {
+	return db.RiskInput{
+		FromAccount: db.Account{
+			ID:       util.RandomInt(1, 1000),
+			Owner:    util.RandomOwner(),
+			Balance:  10_000,
+			Currency: util.RandomCurrency(),
+		},
+		ToAccountID: util.RandomInt(1001, 2000),
+		Amount:      50,
+		ClientIP:    "10.0.0.1:4321",
+		UserAgent:   "test-agent",
+		Now:         time.Now(),
+	}
+}
+
+func TestRuleEvaluator(t *testing.T) {
+	testCases := []struct {
+		name       string
+		buildInput func(input *db.RiskInput)
+		buildStubs func(store *mockdb.MockStore)
+		decision   db.RiskDecision
+		reasons    int
+	}{
+		{
+			name:       "Allow",
+			buildInput: func(input *db.RiskInput) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Any()).Times(0)
+				store.EXPECT().CountTransfersSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+				store.EXPECT().ListUserSessionsBefore(gomock.Any(), gomock.Any()).Times(1).
+					Return([]db.Session{{ClientIp: "10.0.0.1:1234", UserAgent: "test-agent"}}, nil)
+			},
+			decision: db.RiskDecisionAllow,
+		},
+		{
+			name: "NewPayeeLargeAmount",
+			buildInput: func(input *db.RiskInput) {
+				input.Amount = 5_000
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().CountTransfersSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().ListUserSessionsBefore(gomock.Any(), gomock.Any()).Times(1).
+					Return([]db.Session{{ClientIp: "10.0.0.1", UserAgent: "test-agent"}}, nil)
+			},
+			decision: db.RiskDecisionReview,
+			reasons:  1,
+		},
+		{
+			name: "KnownPayeeLargeAmount",
+			buildInput: func(input *db.RiskInput) {
+				input.Amount = 5_000
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Any()).Times(1).Return(int64(3), nil)
+				store.EXPECT().CountTransfersSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().ListUserSessionsBefore(gomock.Any(), gomock.Any()).Times(1).
+					Return([]db.Session{{ClientIp: "10.0.0.1", UserAgent: "test-agent"}}, nil)
+			},
+			decision: db.RiskDecisionAllow,
+		},
+		{
+			name:       "Burst",
+			buildInput: func(input *db.RiskInput) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(5), nil)
+				store.EXPECT().ListUserSessionsBefore(gomock.Any(), gomock.Any()).Times(1).
+					Return([]db.Session{{ClientIp: "10.0.0.1", UserAgent: "test-agent"}}, nil)
+			},
+			decision: db.RiskDecisionReview,
+			reasons:  1,
+		},
+		{
+			name:       "NewDevice",
+			buildInput: func(input *db.RiskInput) {},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().ListUserSessionsBefore(gomock.Any(), gomock.Any()).Times(1).
+					Return([]db.Session{{ClientIp: "10.0.0.2:1234", UserAgent: "test-agent"}}, nil)
+			},
+			decision: db.RiskDecisionReview,
+			reasons:  1,
+		},
+		{
+			name: "UnknownClient",
+			buildInput: func(input *db.RiskInput) {
+				input.ClientIP = ""
+				input.UserAgent = ""
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().ListUserSessionsBefore(gomock.Any(), gomock.Any()).Times(0)
+			},
+			decision: db.RiskDecisionAllow,
+		},
+		{
+			name: "Drain",
+			buildInput: func(input *db.RiskInput) {
+				input.FromAccount.HeldBalance = 9_900
+				input.Amount = 100
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().ListUserSessionsBefore(gomock.Any(), gomock.Any()).Times(1).
+					Return([]db.Session{{ClientIp: "10.0.0.1", UserAgent: "test-agent"}}, nil)
+			},
+			decision: db.RiskDecisionReview,
+			reasons:  1,
+		},
+		{
+			name: "DenyScore",
+			buildInput: func(input *db.RiskInput) {
+				input.Amount = 9_500
+			},
+			buildStubs: func(store *mockdb.MockStore) {
+				store.EXPECT().CountTransfersBetween(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().CountTransfersSince(gomock.Any(), gomock.Any()).Times(1).Return(int64(0), nil)
+				store.EXPECT().ListUserSessionsBefore(gomock.Any(), gomock.Any()).Times(1).Return([]db.Session{}, nil)
+			},
+			decision: db.RiskDecisionDeny,
+			reasons:  3,
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			store := mockdb.NewMockStore(ctrl)
+			tc.buildStubs(store)
+
+			input := randomInput()
+			tc.buildInput(&input)
+
+			evaluator := NewRuleEvaluator(DefaultConfig())
+			assessment, err := evaluator.Evaluate(context.Background(), store, input)
+			require.NoError(t, err)
+			require.Equal(t, tc.decision, assessment.Decision)
+			require.Len(t, assessment.Reasons, tc.reasons)
+		})
+	}
+}
+
+func TestRuleEvaluatorDenyRule(t *testing.T) {
+	deny := RuleFunc(func(ctx context.Context, q db.Querier, input db.RiskInput) (db.RiskDecision, string, error) {
+		return db.RiskDecisionDeny, "denied", nil
+	})
+	review := RuleFunc(func(ctx context.Context, q db.Querier, input db.RiskInput) (db.RiskDecision, string, error) {
+		return db.RiskDecisionReview, "review", nil
+	})
+
+	evaluator := NewRuleEvaluatorWithRules(0, deny, review)
+	assessment, err := evaluator.Evaluate(context.Background(), nil, randomInput())
+	require.NoError(t, err)
+	require.Equal(t, db.RiskDecisionDeny, assessment.Decision)
+	require.Equal(t, []string{"denied", "review"}, assessment.Reasons)
+}
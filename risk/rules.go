@@ -0,0 +1,119 @@
+package risk
+
+import (
+	"context"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"net"
+	"time"
+)
+
+// maxKnownSessions bounds the previous sessions compared against the client of a transfer
+const maxKnownSessions = 100
+
+// RuleFunc adapts a function to the Rule interface
+type RuleFunc func(ctx context.Context, q db.Querier, input db.RiskInput) (db.RiskDecision, string, error)
+
+func (f RuleFunc) Check(ctx context.Context, q db.Querier, input db.RiskInput) (db.RiskDecision, string, error) {
+	return f(ctx, q, input)
+}
+
+// NewPayeeRule reviews large transfers to an account the sender never sent money to before
+func NewPayeeRule(largeAmount int64) Rule {
+	return RuleFunc(func(ctx context.Context, q db.Querier, input db.RiskInput) (db.RiskDecision, string, error) {
+		if input.Amount < largeAmount {
+			return db.RiskDecisionAllow, "", nil
+		}
+
+		count, err := q.CountTransfersBetween(ctx, db.CountTransfersBetweenParams{
+			FromAccountID: input.FromAccount.ID,
+			ToAccountID:   input.ToAccountID,
+		})
+		if err != nil {
+			return "", "", err
+		}
+
+		if count > 0 {
+			return db.RiskDecisionAllow, "", nil
+		}
+
+		return db.RiskDecisionReview, fmt.Sprintf("large amount %d to a new payee", input.Amount), nil
+	})
+}
+
+// BurstRule reviews a transfer when the source account already sent count transfers within the window
+func BurstRule(count int64, window time.Duration) Rule {
+	return RuleFunc(func(ctx context.Context, q db.Querier, input db.RiskInput) (db.RiskDecision, string, error) {
+		recent, err := q.CountTransfersSince(ctx, db.CountTransfersSinceParams{
+			FromAccountID: input.FromAccount.ID,
+			Since:         input.Now.Add(-window),
+		})
+		if err != nil {
+			return "", "", err
+		}
+
+		if recent < count {
+			return db.RiskDecisionAllow, "", nil
+		}
+
+		return db.RiskDecisionReview, fmt.Sprintf("%d transfers in the last %s", recent, window), nil
+	})
+}
+
+// NewDeviceRule reviews transfers requested from an IP or user agent
+// the owner didn't log in from at least minAge ago
+func NewDeviceRule(minAge time.Duration) Rule {
+	return RuleFunc(func(ctx context.Context, q db.Querier, input db.RiskInput) (db.RiskDecision, string, error) {
+		clientIP := hostOf(input.ClientIP)
+		if clientIP == "" && input.UserAgent == "" {
+			return db.RiskDecisionAllow, "", nil
+		}
+
+		sessions, err := q.ListUserSessionsBefore(ctx, db.ListUserSessionsBeforeParams{
+			Username:    input.FromAccount.Owner,
+			Before:      input.Now.Add(-minAge),
+			MaxSessions: maxKnownSessions,
+		})
+		if err != nil {
+			return "", "", err
+		}
+
+		knownIP := clientIP == ""
+		knownUserAgent := input.UserAgent == ""
+		for _, session := range sessions {
+			knownIP = knownIP || hostOf(session.ClientIp) == clientIP
+			knownUserAgent = knownUserAgent || session.UserAgent == input.UserAgent
+		}
+
+		switch {
+		case !knownIP:
+			return db.RiskDecisionReview, fmt.Sprintf("new client ip %s", clientIP), nil
+		case !knownUserAgent:
+			return db.RiskDecisionReview, fmt.Sprintf("new device %s", input.UserAgent), nil
+		}
+
+		return db.RiskDecisionAllow, "", nil
+	})
+}
+
+// DrainRule reviews transfers moving at least percent of the available balance of the source account,
+// unless the amount is below minAmount
+func DrainRule(percent int64, minAmount int64) Rule {
+	return RuleFunc(func(ctx context.Context, q db.Querier, input db.RiskInput) (db.RiskDecision, string, error) {
+		available := input.FromAccount.Balance - input.FromAccount.HeldBalance
+		if input.Amount < minAmount || input.Amount*100 < available*percent {
+			return db.RiskDecisionAllow, "", nil
+		}
+
+		return db.RiskDecisionReview, fmt.Sprintf("amount %d drains the available balance %d", input.Amount, available), nil
+	})
+}
+
+// hostOf strips the port from a client address, the peer addresses of the gRPC server include one
+func hostOf(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
@@ -9,11 +9,12 @@ import (
 // CustomClaims contains the payload data of the token and jwt registered claims
 type CustomClaims struct {
 	Username string
+	Role     string
 	jwt.RegisteredClaims
 }
 
 // NewClaims creates a new custom claims
-func NewClaims(username string, duration time.Duration) (*CustomClaims, error) {
+func NewClaims(username string, role string, duration time.Duration) (*CustomClaims, error) {
 	tokenID, err := uuid.NewRandom()
 	if err != nil {
 		return nil, err
@@ -21,6 +22,7 @@ func NewClaims(username string, duration time.Duration) (*CustomClaims, error) {
 
 	claims := &CustomClaims{
 		username,
+		role,
 		jwt.RegisteredClaims{
 			// A usual scenario is to set the expiration time relative to the current time
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(duration)),
@@ -22,9 +22,9 @@ func NewJWTMaker(secretKey string) (Maker, error) {
 	return &JWTMaker{secretKey}, nil
 }
 
-// CreateToken creates a new token for a specific username and duration
-func (j *JWTMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
-	claims, err := NewClaims(username, duration)
+// CreateToken creates a new token for a specific username, role and duration
+func (j *JWTMaker) CreateToken(username string, role string, duration time.Duration) (string, *Payload, error) {
+	claims, err := NewClaims(username, role, duration)
 	uid, err := uuid.Parse(claims.ID)
 	if err != nil {
 		return "", nil, err
@@ -32,6 +32,7 @@ func (j *JWTMaker) CreateToken(username string, duration time.Duration) (string,
 	payload := &Payload{
 		ID:        uid,
 		Username:  claims.Username,
+		Role:      claims.Role,
 		IssuedAt:  claims.IssuedAt.Time,
 		ExpiredAt: claims.ExpiresAt.Time,
 	}
@@ -72,6 +73,7 @@ func (j *JWTMaker) VerifyToken(token string) (*Payload, error) {
 	payload := &Payload{
 		ID:        uID,
 		Username:  customClaims.Username,
+		Role:      customClaims.Role,
 		IssuedAt:  customClaims.IssuedAt.Time,
 		ExpiredAt: customClaims.ExpiresAt.Time,
 	}
@@ -26,9 +26,9 @@ func NewPasetoMaker(symmetricKey string) (Maker, error) {
 	return maker, nil
 }
 
-// CreateToken creates a new token for a specific username and duration
-func (p *PasetoMaker) CreateToken(username string, duration time.Duration) (string, *Payload, error) {
-	paylaod, err := NewPayload(username, duration)
+// CreateToken creates a new token for a specific username, role and duration
+func (p *PasetoMaker) CreateToken(username string, role string, duration time.Duration) (string, *Payload, error) {
+	paylaod, err := NewPayload(username, role, duration)
 	if err != nil {
 		return "", paylaod, err
 	}
@@ -0,0 +1,45 @@
+package util
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// AccountNumberCountry prefixes every account number like the country code of an IBAN
+	AccountNumberCountry = "SB"
+	// AccountNumberLength is the length of the country code, the check digits and the 12 digits of the account
+	AccountNumberLength = 16
+)
+
+// AccountNumber builds an IBAN-style account number from the 12 digits of an account,
+// with the ISO 7064 mod 97-10 check digits following the country code
+func AccountNumber(digits string) string {
+	check := 98 - mod97(digits+AccountNumberCountry+"00")
+	return fmt.Sprintf("%s%02d%s", AccountNumberCountry, check, digits)
+}
+
+// ValidAccountNumber returns true if the check digits of the account number are valid
+func ValidAccountNumber(number string) bool {
+	if len(number) != AccountNumberLength || !strings.HasPrefix(number, AccountNumberCountry) {
+		return false
+	}
+	return mod97(number[4:]+number[:4]) == 1
+}
+
+// mod97 returns the remainder of the division by 97 of the number made of the digits of s,
+// where the letters A to Z stand for the numbers 10 to 35
+func mod97(s string) int {
+	remainder := 0
+	for _, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			remainder = (remainder*10 + int(c-'0')) % 97
+		case c >= 'A' && c <= 'Z':
+			remainder = (remainder*100 + int(c-'A') + 10) % 97
+		default:
+			return -1
+		}
+	}
+	return remainder
+}
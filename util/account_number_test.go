@@ -0,0 +1,31 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestAccountNumber(t *testing.T) {
+	require.Equal(t, "SB77000000000001", AccountNumber("000000000001"))
+	require.Equal(t, "SB06123456789012", AccountNumber("123456789012"))
+
+	// the check digits of a real IBAN
+	require.Equal(t, 1, mod97("WEST12345698765432"+"GB82"))
+
+	for i := 0; i < 100; i++ {
+		require.True(t, ValidAccountNumber(RandomAccountNumber()))
+	}
+}
+
+func TestValidAccountNumber(t *testing.T) {
+	require.True(t, ValidAccountNumber("SB06123456789012"))
+
+	// a single changed digit or swapped digits change the remainder
+	require.False(t, ValidAccountNumber("SB06123456789013"))
+	require.False(t, ValidAccountNumber("SB06213456789012"))
+	require.False(t, ValidAccountNumber("SB07123456789012"))
+
+	require.False(t, ValidAccountNumber("GB06123456789012"))
+	require.False(t, ValidAccountNumber("SB0612345678901"))
+	require.False(t, ValidAccountNumber("SB06-23456789012"))
+}
@@ -0,0 +1,81 @@
+package util
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// ClientIPResolver finds the IP of a client behind the trusted proxies.
+// The X-Forwarded-For header can be forged by the clients, so its addresses are only
+// believed when they were appended by a trusted proxy.
+type ClientIPResolver struct {
+	trustedProxies []*net.IPNet
+}
+
+// NewClientIPResolver creates a resolver trusting the proxies in the CIDRs,
+// it trusts none of the forwarded addresses when there are no CIDRs
+func NewClientIPResolver(trustedProxies []string) (*ClientIPResolver, error) {
+	resolver := &ClientIPResolver{}
+
+	for _, cidr := range trustedProxies {
+		cidr = strings.TrimSpace(cidr)
+		if cidr == "" {
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", cidr, err)
+		}
+		resolver.trustedProxies = append(resolver.trustedProxies, network)
+	}
+
+	return resolver, nil
+}
+
+// Resolve returns the client IP, without port, from the X-Forwarded-For values and the address of the peer.
+// Walking from the peer towards the client, it returns the first address which is not a trusted proxy,
+// or the left-most address when all of them are trusted.
+func (r *ClientIPResolver) Resolve(forwardedFor []string, remoteAddr string) string {
+	clientIP := hostIP(remoteAddr)
+
+	var hops []string
+	for _, value := range forwardedFor {
+		for _, hop := range strings.Split(value, ",") {
+			if hop = strings.TrimSpace(hop); hop != "" {
+				hops = append(hops, hop)
+			}
+		}
+	}
+
+	for i := len(hops) - 1; i >= 0 && r.trusted(clientIP); i-- {
+		clientIP = hostIP(hops[i])
+	}
+
+	return clientIP
+}
+
+func (r *ClientIPResolver) trusted(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range r.trustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hostIP drops the port of an address, IPv6 addresses with a port are enclosed in brackets
+func hostIP(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+
+	return strings.Trim(addr, "[]")
+}
@@ -0,0 +1,76 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+)
+
+func TestClientIPResolver(t *testing.T) {
+	resolver, err := NewClientIPResolver([]string{"10.0.0.0/8", "::1/128"})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name         string
+		forwardedFor []string
+		remoteAddr   string
+		clientIP     string
+	}{
+		{
+			name:       "NoProxy",
+			remoteAddr: "203.0.113.7:51000",
+			clientIP:   "203.0.113.7",
+		},
+		{
+			name:         "UntrustedPeer",
+			forwardedFor: []string{"198.51.100.1"},
+			remoteAddr:   "203.0.113.7:51000",
+			clientIP:     "203.0.113.7",
+		},
+		{
+			name:         "TrustedProxy",
+			forwardedFor: []string{"203.0.113.7"},
+			remoteAddr:   "10.0.0.5:51000",
+			clientIP:     "203.0.113.7",
+		},
+		{
+			name:         "ForgedHop",
+			forwardedFor: []string{"198.51.100.1, 203.0.113.7"},
+			remoteAddr:   "10.0.0.5:51000",
+			clientIP:     "203.0.113.7",
+		},
+		{
+			name:         "ProxyChain",
+			forwardedFor: []string{"198.51.100.1, 203.0.113.7", "10.1.2.3"},
+			remoteAddr:   "[::1]:51000",
+			clientIP:     "203.0.113.7",
+		},
+		{
+			name:         "AllTrusted",
+			forwardedFor: []string{"10.1.2.3, 10.4.5.6"},
+			remoteAddr:   "10.0.0.5:51000",
+			clientIP:     "10.1.2.3",
+		},
+		{
+			name:         "ForwardedPort",
+			forwardedFor: []string{"[2001:db8::1]:443"},
+			remoteAddr:   "10.0.0.5:51000",
+			clientIP:     "2001:db8::1",
+		},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.clientIP, resolver.Resolve(tc.forwardedFor, tc.remoteAddr))
+		})
+	}
+
+	// without trusted proxies the forwarded addresses are ignored
+	resolver, err = NewClientIPResolver(nil)
+	require.NoError(t, err)
+	require.Equal(t, "10.0.0.5", resolver.Resolve([]string{"203.0.113.7"}, "10.0.0.5:51000"))
+
+	_, err = NewClientIPResolver([]string{"10.0.0.1"})
+	require.Error(t, err)
+}
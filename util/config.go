@@ -1,6 +1,7 @@
 package util
 
 import (
+	"fmt"
 	"github.com/spf13/viper"
 	"time"
 )
@@ -15,9 +16,20 @@ type Config struct {
 	RedisAddress         string        `mapstructure:"REDIS_ADDRESS"`
 	HTTPServerAddress    string        `mapstructure:"HTTP_SERVER_ADDRESS"`
 	GRPCServerAddress    string        `mapstructure:"GRPC_SERVER_ADDRESS"`
+	MetricsServerAddress string        `mapstructure:"METRICS_SERVER_ADDRESS"`
 	TokenSymmetricKey    string        `mapstructure:"TOKEN_SYMMETRIC_KEY"`
 	AccessTokenDuration  time.Duration `mapstructure:"ACCESS_TOKEN_DURATION"`
 	RefreshTokenDuration time.Duration `mapstructure:"REFRESH_TOKEN_DURATION"`
+	SavingsAPRBps        int64         `mapstructure:"SAVINGS_APR_BPS"`
+	ShutdownTimeout      time.Duration `mapstructure:"SHUTDOWN_TIMEOUT"`
+	ShutdownDrainPeriod  time.Duration `mapstructure:"SHUTDOWN_DRAIN_PERIOD"`
+	TracingExporter      string        `mapstructure:"TRACING_EXPORTER"`
+	OTLPEndpoint         string        `mapstructure:"OTLP_ENDPOINT"`
+	LogRedactFields      []string      `mapstructure:"LOG_REDACT_FIELDS"`
+	RateLimitDefault     string        `mapstructure:"RATE_LIMIT_DEFAULT"`
+	RateLimits           []string      `mapstructure:"RATE_LIMITS"`
+	TrustedProxies       []string      `mapstructure:"TRUSTED_PROXIES"`
+	OutboxRelayInterval  time.Duration `mapstructure:"OUTBOX_RELAY_INTERVAL"`
 }
 
 // LoadConfig reads configuration from file or environment variables
@@ -28,11 +40,45 @@ func LoadConfig(path string) (config Config, err error) {
 
 	viper.AutomaticEnv()
 
+	// the deployed app.env is rebuilt from the secrets and may lack the newer keys
+	viper.SetDefault("SHUTDOWN_TIMEOUT", 20*time.Second)
+	viper.SetDefault("METRICS_SERVER_ADDRESS", "0.0.0.0:9100")
+	viper.SetDefault("SHUTDOWN_DRAIN_PERIOD", 5*time.Second)
+	viper.SetDefault("OUTBOX_RELAY_INTERVAL", time.Second)
+
 	err = viper.ReadInConfig()
 	if err != nil {
 		return
 	}
 
 	err = viper.Unmarshal(&config)
+	if err != nil {
+		return
+	}
+
+	err = config.validate()
 	return
 }
+
+// validate rejects the values the servers cannot run with
+func (config Config) validate() error {
+	if config.ShutdownTimeout <= 0 {
+		return fmt.Errorf("SHUTDOWN_TIMEOUT must be positive, got %s", config.ShutdownTimeout)
+	}
+
+	if config.ShutdownDrainPeriod < 0 {
+		return fmt.Errorf("SHUTDOWN_DRAIN_PERIOD cannot be negative, got %s", config.ShutdownDrainPeriod)
+	}
+
+	// the drain counts toward the shutdown timeout, the servers need some time left to stop
+	if config.ShutdownDrainPeriod >= config.ShutdownTimeout {
+		return fmt.Errorf("SHUTDOWN_DRAIN_PERIOD must be shorter than SHUTDOWN_TIMEOUT, got %s and %s",
+			config.ShutdownDrainPeriod, config.ShutdownTimeout)
+	}
+
+	if config.OutboxRelayInterval <= 0 {
+		return fmt.Errorf("OUTBOX_RELAY_INTERVAL must be positive, got %s", config.OutboxRelayInterval)
+	}
+
+	return nil
+}
@@ -0,0 +1,43 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLoadConfigDefaults(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "app.env"), []byte("ENVIRONMENT=test\n"), 0600)
+	require.NoError(t, err)
+
+	config, err := LoadConfig(dir)
+	require.NoError(t, err)
+	require.Equal(t, "test", config.Environment)
+	require.Equal(t, 20*time.Second, config.ShutdownTimeout)
+	require.Equal(t, time.Second, config.OutboxRelayInterval)
+
+	t.Setenv("SHUTDOWN_TIMEOUT", "0s")
+	_, err = LoadConfig(dir)
+	require.ErrorContains(t, err, "SHUTDOWN_TIMEOUT")
+}
+
+func TestLoadConfigOutboxRelayInterval(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "app.env"), []byte("OUTBOX_RELAY_INTERVAL=0s\n"), 0600)
+	require.NoError(t, err)
+
+	_, err = LoadConfig(dir)
+	require.ErrorContains(t, err, "OUTBOX_RELAY_INTERVAL")
+}
+
+func TestLoadConfigShutdownDrainPeriod(t *testing.T) {
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "app.env"), []byte("SHUTDOWN_TIMEOUT=5s\nSHUTDOWN_DRAIN_PERIOD=5s\n"), 0600)
+	require.NoError(t, err)
+
+	_, err = LoadConfig(dir)
+	require.ErrorContains(t, err, "SHUTDOWN_DRAIN_PERIOD")
+}
@@ -50,3 +50,8 @@ func RandomCurrency() string {
 func RandomEmail() string {
 	return fmt.Sprintf("%s@email.com", RandomString(6))
 }
+
+// RandomAccountNumber generates a random account number with valid check digits
+func RandomAccountNumber() string {
+	return AccountNumber(fmt.Sprintf("%012d", RandomInt(0, 999_999_999_999)))
+}
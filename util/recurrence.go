@@ -0,0 +1,21 @@
+package util
+
+import (
+	"github.com/robfig/cron/v3"
+	"time"
+)
+
+// ParseRecurrence parses a standard 5-field cron expression,
+// a descriptor like @daily or an interval like "@every 24h"
+func ParseRecurrence(recurrence string) (cron.Schedule, error) {
+	return cron.ParseStandard(recurrence)
+}
+
+// FirstRun returns the first time a recurrence should fire at or after start.
+// Intervals fire immediately at start, cron expressions at their first matching time.
+func FirstRun(schedule cron.Schedule, start time.Time) time.Time {
+	if _, ok := schedule.(cron.ConstantDelaySchedule); ok {
+		return start
+	}
+	return schedule.Next(start.Add(-time.Second))
+}
@@ -0,0 +1,23 @@
+package util
+
+import (
+	"github.com/stretchr/testify/require"
+	"testing"
+	"time"
+)
+
+func TestParseRecurrence(t *testing.T) {
+	start := time.Date(2023, time.June, 15, 10, 30, 0, 0, time.UTC)
+
+	schedule, err := ParseRecurrence("@every 24h")
+	require.NoError(t, err)
+	require.Equal(t, start, FirstRun(schedule, start))
+	require.Equal(t, start.Add(24*time.Hour), schedule.Next(start))
+
+	schedule, err = ParseRecurrence("0 9 1 * *")
+	require.NoError(t, err)
+	require.Equal(t, time.Date(2023, time.July, 1, 9, 0, 0, 0, time.UTC), FirstRun(schedule, start))
+
+	_, err = ParseRecurrence("every day")
+	require.Error(t, err)
+}
@@ -0,0 +1,45 @@
+package util
+
+import (
+	"context"
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader is the HTTP header and the gRPC metadata key carrying the request ID
+const RequestIDHeader = "x-request-id"
+
+// maxRequestIDLength limits the request IDs accepted from the clients
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// NewRequestID generates a random request ID
+func NewRequestID() string {
+	return uuid.NewString()
+}
+
+// ValidRequestID checks that a request ID received from a client is safe to log and to send back
+func ValidRequestID(id string) bool {
+	if len(id) == 0 || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for _, ch := range id {
+		if ch <= ' ' || ch > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, or an empty string
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
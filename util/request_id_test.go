@@ -0,0 +1,27 @@
+package util
+
+import (
+	"context"
+	"github.com/stretchr/testify/require"
+	"strings"
+	"testing"
+)
+
+func TestValidRequestID(t *testing.T) {
+	require.True(t, ValidRequestID(NewRequestID()))
+	require.True(t, ValidRequestID("req-123_abc.DEF"))
+
+	require.False(t, ValidRequestID(""))
+	require.False(t, ValidRequestID("with space"))
+	require.False(t, ValidRequestID("line\nbreak"))
+	require.False(t, ValidRequestID("ünicode"))
+	require.False(t, ValidRequestID(strings.Repeat("a", maxRequestIDLength+1)))
+}
+
+func TestRequestIDFromContext(t *testing.T) {
+	require.Empty(t, RequestIDFromContext(context.Background()))
+
+	id := NewRequestID()
+	ctx := ContextWithRequestID(context.Background(), id)
+	require.Equal(t, id, RequestIDFromContext(ctx))
+}
@@ -2,13 +2,19 @@ package val
 
 import (
 	"fmt"
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/Dejan91/simple_bank/webhook"
+	"net"
 	"net/mail"
+	"net/url"
 	"regexp"
+	"strings"
 )
 
 var (
-	isValidUsername = regexp.MustCompile(`^[a-z0-9_]+$`).MatchString
-	isValidFullName = regexp.MustCompile(`^[a-zA-Z\s]+$`).MatchString
+	isValidUsername      = regexp.MustCompile(`^[a-z0-9_]+$`).MatchString
+	isValidFullName      = regexp.MustCompile(`^[a-zA-Z\s]+$`).MatchString
+	isValidAccountNumber = regexp.MustCompile(`^SB[0-9]{14}$`).MatchString
 )
 
 func ValidateString(value string, minLength, maxLength int) error {
@@ -52,3 +58,81 @@ func ValidateFullName(value string) error {
 	}
 	return nil
 }
+
+func ValidateReason(value string) error {
+	return ValidateString(value, 3, 200)
+}
+
+func ValidateID(value int64) error {
+	if value < 1 {
+		return fmt.Errorf("must be a positive integer")
+	}
+	return nil
+}
+
+func ValidateAmount(value int64) error {
+	if value <= 0 {
+		return fmt.Errorf("must be greater than 0")
+	}
+	return nil
+}
+
+func ValidateCurrency(value string) error {
+	if !util.IsSupportedCurrency(value) {
+		return fmt.Errorf("unsupported currency")
+	}
+	return nil
+}
+
+func ValidateRecurrence(value string) error {
+	if err := ValidateString(value, 1, 100); err != nil {
+		return err
+	}
+	if _, err := util.ParseRecurrence(value); err != nil {
+		return fmt.Errorf("must be a cron expression or an @every interval: %s", err)
+	}
+	return nil
+}
+
+func ValidatePage(pageID, pageSize int32) error {
+	if pageID < 1 {
+		return fmt.Errorf("page id must be a positive integer")
+	}
+	if pageSize < 5 || pageSize > 10 {
+		return fmt.Errorf("page size must be from 5-10")
+	}
+	return nil
+}
+
+func ValidateNickname(value string) error {
+	return ValidateString(value, 1, 50)
+}
+
+func ValidateAccountNumber(value string) error {
+	if !isValidAccountNumber(value) {
+		return fmt.Errorf("must be %s followed by 14 digits", util.AccountNumberCountry)
+	}
+	if !util.ValidAccountNumber(value) {
+		return fmt.Errorf("has invalid check digits")
+	}
+	return nil
+}
+
+func ValidateWebhookURL(value string) error {
+	if err := ValidateString(value, 1, 2000); err != nil {
+		return err
+	}
+	u, err := url.Parse(value)
+	if err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("must be an absolute https URL")
+	}
+	// the resolved addresses are checked again when delivering, since the DNS records can change
+	host := strings.ToLower(u.Hostname())
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("must not point to the internal network")
+	}
+	if ip := net.ParseIP(host); ip != nil && !webhook.PublicIP(ip) {
+		return fmt.Errorf("must not point to the internal network")
+	}
+	return nil
+}
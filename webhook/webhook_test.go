@@ -0,0 +1,156 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignVerify(t *testing.T) {
+	now := time.Now()
+	body := []byte(`{"id":1}`)
+	header := Sign("secret", now, body)
+
+	testCases := []struct {
+		name   string
+		secret string
+		header string
+		body   []byte
+		now    time.Time
+		err    error
+	}{
+		{name: "Valid", secret: "secret", header: header, body: body, now: now},
+		{name: "WrongSecret", secret: "other", header: header, body: body, now: now, err: ErrInvalidSignature},
+		{name: "TamperedBody", secret: "secret", header: header, body: []byte(`{"id":2}`), now: now, err: ErrInvalidSignature},
+		{name: "MalformedHeader", secret: "secret", header: "v1=abc", body: body, now: now, err: ErrInvalidSignature},
+		{name: "Expired", secret: "secret", header: header, body: body, now: now.Add(10 * time.Minute), err: ErrExpiredSignature},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.name, func(t *testing.T) {
+			err := Verify(tc.secret, tc.header, tc.body, 5*time.Minute, tc.now)
+			require.ErrorIs(t, err, tc.err)
+		})
+	}
+}
+
+func TestNewSecret(t *testing.T) {
+	secret1, err := NewSecret()
+	require.NoError(t, err)
+	require.Len(t, secret1, 64)
+
+	secret2, err := NewSecret()
+	require.NoError(t, err)
+	require.NotEqual(t, secret1, secret2)
+}
+
+func TestRetryDelay(t *testing.T) {
+	require.Equal(t, 10*time.Second, RetryDelay(0))
+	require.Equal(t, 20*time.Second, RetryDelay(1))
+	require.Equal(t, 80*time.Second, RetryDelay(3))
+	require.Equal(t, 6*time.Hour, RetryDelay(20))
+}
+
+func TestSenderSend(t *testing.T) {
+	event := Event{
+		ID:        1,
+		Type:      "transfer.completed",
+		CreatedAt: time.Now().UTC(),
+		Data:      json.RawMessage(`{"transfer_id":1}`),
+	}
+
+	status := http.StatusOK
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		require.NoError(t, Verify("secret", r.Header.Get(SignatureHeader), body, time.Minute, time.Now()))
+		require.Equal(t, event.Type, r.Header.Get(EventTypeHeader))
+		require.Equal(t, "7", r.Header.Get(DeliveryIDHeader))
+
+		var received Event
+		require.NoError(t, json.Unmarshal(body, &received))
+		require.Equal(t, event.ID, received.ID)
+		require.JSONEq(t, string(event.Data), string(received.Data))
+
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	// the test server listens on loopback, which NewSender refuses
+	sender := newSender(time.Second, func(ip net.IP) bool { return true })
+
+	responseStatus, err := sender.Send(context.Background(), server.URL, "secret", 7, event)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, responseStatus)
+
+	status = http.StatusInternalServerError
+	responseStatus, err = sender.Send(context.Background(), server.URL, "secret", 7, event)
+	require.Error(t, err)
+	require.Equal(t, http.StatusInternalServerError, responseStatus)
+}
+
+func TestSenderRefusesInternalAddresses(t *testing.T) {
+	hit := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hit = true
+	}))
+	defer server.Close()
+
+	_, err := NewSender(time.Second).Send(context.Background(), server.URL, "secret", 1, Event{ID: 1})
+	require.ErrorIs(t, err, ErrForbiddenAddress)
+	require.False(t, hit)
+}
+
+func TestSenderDoesNotFollowRedirects(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the redirect was followed")
+	}))
+	defer internal.Close()
+
+	server := httptest.NewServer(http.RedirectHandler(internal.URL, http.StatusTemporaryRedirect))
+	defer server.Close()
+
+	sender := newSender(time.Second, func(ip net.IP) bool { return true })
+	responseStatus, err := sender.Send(context.Background(), server.URL, "secret", 1, Event{ID: 1})
+	require.Error(t, err)
+	require.Equal(t, http.StatusTemporaryRedirect, responseStatus)
+}
+
+func TestPublicIP(t *testing.T) {
+	testCases := []struct {
+		ip     string
+		public bool
+	}{
+		{"93.184.216.34", true},
+		{"2606:2800:220:1:248:1893:25c8:1946", true},
+		{"127.0.0.1", false},
+		{"::1", false},
+		{"10.1.2.3", false},
+		{"172.16.0.1", false},
+		{"192.168.1.1", false},
+		{"169.254.169.254", false},
+		{"fe80::1", false},
+		{"fd00::1", false},
+		{"0.0.0.0", false},
+		{"100.64.0.1", false},
+		{"::ffff:127.0.0.1", false},
+	}
+
+	for i := range testCases {
+		tc := testCases[i]
+
+		t.Run(tc.ip, func(t *testing.T) {
+			require.Equal(t, tc.public, PublicIP(net.ParseIP(tc.ip)))
+		})
+	}
+}
@@ -2,15 +2,19 @@ package worker
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/redact"
 	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/codes"
 )
 
 type TaskDistributor interface {
-	DistributeTaskSendVerifyEmail(
-		ctx context.Context,
-		payload *PayloadSendVerifyEmail,
-		opts ...asynq.Option,
-	) error
+	// DistributeOutboxMessage publishes a task written to the outbox,
+	// publishing the same message again is a no-op while the task is queued
+	DistributeOutboxMessage(ctx context.Context, message db.Outbox) error
 }
 
 type RedisTaskDistributor struct {
@@ -23,3 +27,56 @@ func NewRedisTaskDistributor(redisOpt asynq.RedisClientOpt) TaskDistributor {
 		client: client,
 	}
 }
+
+func (d *RedisTaskDistributor) DistributeOutboxMessage(ctx context.Context, message db.Outbox) error {
+	ctx = withOutboxRequestID(ctx, message)
+	ctx, span := startOutboxEnqueueSpan(ctx, message)
+	defer span.End()
+
+	err := d.enqueueOutboxMessage(ctx, message)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+
+	return err
+}
+
+// enqueueOutboxMessage publishes the task of a message with the metadata of ctx in its payload
+func (d *RedisTaskDistributor) enqueueOutboxMessage(ctx context.Context, message db.Outbox) error {
+	payload, err := withTaskMetadata(ctx, message.Payload)
+	if err != nil {
+		return err
+	}
+
+	opts := []asynq.Option{
+		asynq.TaskID(fmt.Sprintf("outbox:%d", message.ID)),
+		asynq.MaxRetry(int(message.MaxRetry)),
+		asynq.ProcessAt(message.ProcessAt),
+	}
+	if message.Queue != "" {
+		opts = append(opts, asynq.Queue(message.Queue))
+	}
+
+	task := asynq.NewTask(message.TaskType, payload, opts...)
+
+	ctx = withTaskLogger(ctx, task)
+	info, err := d.client.EnqueueContext(ctx, task)
+	if err != nil {
+		// the message was published before the relay failed to mark it sent
+		if errors.Is(err, asynq.ErrTaskIDConflict) {
+			return nil
+		}
+		return fmt.Errorf("failed to enqueue task: %w", err)
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Bytes("payload", redact.JSON(task.Payload())).
+		Str("queue", info.Queue).
+		Int("max_retry", info.MaxRetry).
+		Int64("outbox_id", message.ID).
+		Msg("enqueued task")
+
+	return nil
+}
@@ -1,9 +1,13 @@
 package worker
 
 import (
+	"context"
 	"fmt"
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/hibiken/asynq"
 	"github.com/rs/zerolog"
 	"github.com/rs/zerolog/log"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Logger struct{}
@@ -41,3 +45,25 @@ func (l *Logger) Error(args ...interface{}) {
 func (l *Logger) Fatal(args ...interface{}) {
 	l.Print(zerolog.FatalLevel, args...)
 }
+
+// taskLogger attaches a logger tagged with the request that enqueued the task to its context, for log.Ctx
+func taskLogger(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		return next.ProcessTask(withTaskLogger(ctx, task), task)
+	})
+}
+
+func withTaskLogger(ctx context.Context, task *asynq.Task) context.Context {
+	logger := log.With()
+
+	if requestID := taskMetadata(task).RequestID; requestID != "" {
+		ctx = util.ContextWithRequestID(ctx, requestID)
+		logger = logger.Str("request_id", requestID)
+	}
+
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		logger = logger.Str("trace_id", spanContext.TraceID().String())
+	}
+
+	return logger.Logger().WithContext(ctx)
+}
@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// TaskMetadata carries the request ID and the trace of the request that enqueued a task inside its payload,
+// as asynq tasks have no headers
+type TaskMetadata struct {
+	RequestID    string                 `json:"request_id,omitempty"`
+	TraceContext propagation.MapCarrier `json:"trace_context,omitempty"`
+}
+
+// inject stores the request ID and the trace context of ctx in the payload
+func (m *TaskMetadata) inject(ctx context.Context) {
+	m.RequestID = util.RequestIDFromContext(ctx)
+	m.TraceContext = propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, m.TraceContext)
+}
+
+// withOutboxRequestID restores the ID of the request which wrote an outbox message,
+// so the task is enqueued and processed on behalf of that request
+func withOutboxRequestID(ctx context.Context, message db.Outbox) context.Context {
+	if message.RequestID == "" {
+		return ctx
+	}
+
+	return util.ContextWithRequestID(ctx, message.RequestID)
+}
+
+// withTaskMetadata stores the metadata of ctx in a payload marshalled without it, like the payloads
+// of the tasks written to the outbox by the store, keeping the other fields of the payload as they are
+func withTaskMetadata(ctx context.Context, payload []byte) ([]byte, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(payload, &fields); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal payload: %w", err)
+	}
+	if fields == nil {
+		fields = map[string]json.RawMessage{}
+	}
+
+	var metadata TaskMetadata
+	metadata.inject(ctx)
+
+	if metadata.RequestID != "" {
+		requestID, err := json.Marshal(metadata.RequestID)
+		if err != nil {
+			return nil, err
+		}
+		fields["request_id"] = requestID
+	}
+
+	traceContext, err := json.Marshal(metadata.TraceContext)
+	if err != nil {
+		return nil, err
+	}
+	fields["trace_context"] = traceContext
+
+	return json.Marshal(fields)
+}
+
+// taskMetadata reads the metadata from the payload of a task, periodic tasks have none
+func taskMetadata(task *asynq.Task) TaskMetadata {
+	var metadata TaskMetadata
+	if len(task.Payload()) > 0 {
+		// the payload is validated by the handler, an invalid one just has no metadata
+		_ = json.Unmarshal(task.Payload(), &metadata)
+	}
+
+	return metadata
+}
@@ -0,0 +1,28 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"github.com/Dejan91/simple_bank/metrics"
+	"github.com/hibiken/asynq"
+)
+
+// taskMetrics counts the processed, failed and retried tasks per task type
+func taskMetrics(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		err := next.ProcessTask(ctx, task)
+		metrics.ObserveTask(task.Type(), err, willRetry(ctx, err))
+		return err
+	})
+}
+
+// willRetry tells whether asynq runs a task again after it returned the given error
+func willRetry(ctx context.Context, err error) bool {
+	if err == nil || errors.Is(err, asynq.SkipRetry) {
+		return false
+	}
+
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+	return retried < maxRetry
+}
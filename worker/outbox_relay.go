@@ -0,0 +1,72 @@
+package worker
+
+import (
+	"context"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// outboxRelayBatchSize is how many outbox messages are published in one transaction
+const outboxRelayBatchSize = 100
+
+// OutboxRelay publishes the tasks written to the outbox to the task queue once their transactions commit
+type OutboxRelay struct {
+	store       db.Store
+	distributor TaskDistributor
+	interval    time.Duration
+}
+
+func NewOutboxRelay(store db.Store, distributor TaskDistributor, interval time.Duration) *OutboxRelay {
+	return &OutboxRelay{
+		store:       store,
+		distributor: distributor,
+		interval:    interval,
+	}
+}
+
+// Run relays the outbox every interval until ctx is done
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.relay(ctx)
+		}
+	}
+}
+
+// relay publishes batches of messages until the outbox is drained,
+// it stops at the first failure as the queue is likely unavailable
+func (r *OutboxRelay) relay(ctx context.Context) {
+	for {
+		result, err := r.store.RelayOutboxTx(ctx, db.RelayOutboxTxParams{
+			Limit: outboxRelayBatchSize,
+			Publish: func(message db.Outbox) error {
+				return r.distributor.DistributeOutboxMessage(ctx, message)
+			},
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error().Err(err).Msg("failed to relay outbox")
+			}
+			return
+		}
+
+		if result.Failed > 0 {
+			log.Warn().
+				Int("sent", result.Sent).
+				Int("failed", result.Failed).
+				Msg("failed to publish outbox messages")
+			return
+		}
+
+		if result.Sent < outboxRelayBatchSize {
+			return
+		}
+	}
+}
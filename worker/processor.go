@@ -2,9 +2,15 @@ package worker
 
 import (
 	"context"
+	"fmt"
 	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/payment"
+	"github.com/Dejan91/simple_bank/redact"
+	"github.com/Dejan91/simple_bank/util"
+	"github.com/Dejan91/simple_bank/webhook"
 	"github.com/hibiken/asynq"
 	"github.com/rs/zerolog/log"
+	"time"
 )
 
 const (
@@ -14,15 +20,34 @@ const (
 
 type TaskProcessor interface {
 	Start() error
+	Shutdown()
 	ProcessTaskSendVerifyEmail(ctx context.Context, task *asynq.Task) error
+	ProcessTaskExecuteScheduledTransfers(ctx context.Context, task *asynq.Task) error
+	ProcessTaskExpireHolds(ctx context.Context, task *asynq.Task) error
+	ProcessTaskReconcileLedger(ctx context.Context, task *asynq.Task) error
+	ProcessTaskAccrueInterest(ctx context.Context, task *asynq.Task) error
+	ProcessTaskDeliverWebhook(ctx context.Context, task *asynq.Task) error
+	ProcessTaskPayoutWithdrawal(ctx context.Context, task *asynq.Task) error
+	ProcessTaskReconcilePayments(ctx context.Context, task *asynq.Task) error
 }
 
 type RedisTaskProcessor struct {
-	server *asynq.Server
-	store  db.Store
+	server        *asynq.Server
+	scheduler     *asynq.Scheduler
+	store         db.Store
+	config        util.Config
+	webhookSender *webhook.Sender
+	paymentRail   payment.PaymentRail
+	riskEvaluator db.RiskEvaluator
 }
 
-func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt, store db.Store) TaskProcessor {
+func NewRedisTaskProcessor(
+	redisOpt asynq.RedisClientOpt,
+	store db.Store,
+	config util.Config,
+	paymentRail payment.PaymentRail,
+	riskEvaluator db.RiskEvaluator,
+) TaskProcessor {
 	server := asynq.NewServer(
 		redisOpt,
 		asynq.Config{
@@ -31,26 +56,81 @@ func NewRedisTaskProcessor(redisOpt asynq.RedisClientOpt, store db.Store) TaskPr
 				QueueDefault:  5,
 			},
 			ErrorHandler: asynq.ErrorHandlerFunc(func(ctx context.Context, task *asynq.Task, err error) {
-				log.Error().
+				log.Ctx(withTaskLogger(ctx, task)).Error().
 					Err(err).
 					Str("type", task.Type()).
-					Bytes("payload", task.Payload()).
+					Bytes("payload", redact.JSON(task.Payload())).
 					Msg("process task failed")
 			}),
+			RetryDelayFunc:  retryDelay,
+			Logger:          NewLogger(),
+			ShutdownTimeout: config.ShutdownTimeout,
+		},
+	)
+
+	scheduler := asynq.NewScheduler(
+		redisOpt,
+		&asynq.SchedulerOpts{
 			Logger: NewLogger(),
 		},
 	)
 
 	return &RedisTaskProcessor{
-		server: server,
-		store:  store,
+		server:        server,
+		scheduler:     scheduler,
+		store:         store,
+		config:        config,
+		webhookSender: webhook.NewSender(webhookTimeout),
+		paymentRail:   paymentRail,
+		riskEvaluator: riskEvaluator,
 	}
 }
 
 func (p *RedisTaskProcessor) Start() error {
 	mux := asynq.NewServeMux()
+	mux.Use(taskTracing, taskLogger, taskMetrics)
 
 	mux.HandleFunc(TaskSendVerifyEmail, p.ProcessTaskSendVerifyEmail)
+	mux.HandleFunc(TaskExecuteScheduledTransfers, p.ProcessTaskExecuteScheduledTransfers)
+	mux.HandleFunc(TaskExpireHolds, p.ProcessTaskExpireHolds)
+	mux.HandleFunc(TaskReconcileLedger, p.ProcessTaskReconcileLedger)
+	mux.HandleFunc(TaskAccrueInterest, p.ProcessTaskAccrueInterest)
+	mux.HandleFunc(TaskDeliverWebhook, p.ProcessTaskDeliverWebhook)
+	mux.HandleFunc(TaskPayoutWithdrawal, p.ProcessTaskPayoutWithdrawal)
+	mux.HandleFunc(TaskReconcilePayments, p.ProcessTaskReconcilePayments)
+
+	periodicTasks := map[string]string{
+		TaskExecuteScheduledTransfers: executeScheduledTransfersInterval,
+		TaskExpireHolds:               expireHoldsInterval,
+		TaskReconcileLedger:           reconcileLedgerInterval,
+		TaskAccrueInterest:            accrueInterestInterval,
+		TaskReconcilePayments:         reconcilePaymentsInterval,
+	}
+
+	// every replica runs a scheduler, Unique drops the duplicate enqueues; it expires, so the
+	// tasks also guard their own work against running twice, e.g. scheduled transfers lock the due schedule
+	for taskType, interval := range periodicTasks {
+		_, err := p.scheduler.Register(
+			interval,
+			asynq.NewTask(taskType, nil),
+			asynq.Queue(QueueDefault),
+			asynq.Unique(time.Minute),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to register periodic task %s: %w", taskType, err)
+		}
+	}
+
+	if err := p.scheduler.Start(); err != nil {
+		return fmt.Errorf("failed to start scheduler: %w", err)
+	}
 
 	return p.server.Start(mux)
 }
+
+// Shutdown stops the scheduler and waits for the running tasks to finish,
+// the tasks still running after the shutdown timeout are pushed back to the queue
+func (p *RedisTaskProcessor) Shutdown() {
+	p.scheduler.Shutdown()
+	p.server.Shutdown()
+}
@@ -0,0 +1,128 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+const TaskAccrueInterest = "task:accrue_interest"
+
+const (
+	// accrueInterestInterval accrues the interest of the day every night at 1 AM
+	accrueInterestInterval = "0 1 * * *"
+	// accrueInterestBatchSize is the number of accounts loaded from the db at once
+	accrueInterestBatchSize = 100
+)
+
+// ProcessTaskAccrueInterest accrues the interest of the day on every savings account,
+// and pays the interest accrued in the previous months, so on the first day of every
+// month the interest of the previous month is posted
+func (p *RedisTaskProcessor) ProcessTaskAccrueInterest(ctx context.Context, task *asynq.Task) error {
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	firstOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+
+	accrued, err := p.accrueInterest(ctx, today)
+	if err != nil {
+		return err
+	}
+
+	posted, err := p.postInterest(ctx, firstOfMonth)
+	if err != nil {
+		return err
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Int("accrued", accrued).
+		Int("posted", posted).
+		Msg("processed task")
+
+	return nil
+}
+
+func (p *RedisTaskProcessor) accrueInterest(ctx context.Context, accrualDate time.Time) (int, error) {
+	var accrued int
+	var lastAccountID int64
+
+	for {
+		accounts, err := p.store.ListAccountsToAccrue(ctx, db.ListAccountsToAccrueParams{
+			AfterID:     lastAccountID,
+			AccrualDate: accrualDate,
+			MaxAccounts: accrueInterestBatchSize,
+		})
+		if err != nil {
+			return accrued, fmt.Errorf("failed to list accounts to accrue: %w", err)
+		}
+
+		for _, account := range accounts {
+			lastAccountID = account.ID
+
+			_, err := p.store.AccrueInterestTx(ctx, db.AccrueInterestTxParams{
+				AccountID:   account.ID,
+				AccrualDate: accrualDate,
+				AprBps:      p.config.SavingsAPRBps,
+			})
+			if err != nil {
+				log.Ctx(ctx).Error().
+					Err(err).
+					Int64("account_id", account.ID).
+					Msg("failed to accrue interest")
+				continue
+			}
+
+			accrued++
+		}
+
+		if len(accounts) < accrueInterestBatchSize {
+			return accrued, nil
+		}
+	}
+}
+
+func (p *RedisTaskProcessor) postInterest(ctx context.Context, before time.Time) (int, error) {
+	var posted int
+	var lastAccountID int64
+
+	for {
+		accountIDs, err := p.store.ListAccountsWithUnpostedInterest(ctx, db.ListAccountsWithUnpostedInterestParams{
+			AfterID:     lastAccountID,
+			Before:      before,
+			MaxAccounts: accrueInterestBatchSize,
+		})
+		if err != nil {
+			return posted, fmt.Errorf("failed to list accounts with unposted interest: %w", err)
+		}
+
+		for _, accountID := range accountIDs {
+			lastAccountID = accountID
+
+			result, err := p.store.PostInterestTx(ctx, db.PostInterestTxParams{
+				AccountID: accountID,
+				Before:    before,
+			})
+			if err != nil {
+				log.Ctx(ctx).Error().
+					Err(err).
+					Int64("account_id", accountID).
+					Msg("failed to post interest")
+				continue
+			}
+
+			log.Ctx(ctx).Info().
+				Int64("account_id", accountID).
+				Int64("transfer_id", result.Transfer.Transfer.ID).
+				Int64("amount", result.Transfer.Transfer.Amount).
+				Msg("posted interest")
+			posted++
+		}
+
+		if len(accountIDs) < accrueInterestBatchSize {
+			return posted, nil
+		}
+	}
+}
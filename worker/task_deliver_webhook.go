@@ -0,0 +1,113 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/webhook"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+// TaskDeliverWebhook posts a domain event to a webhook subscription,
+// the store enqueues it through the outbox in the transaction emitting the event
+const TaskDeliverWebhook = db.TaskDeliverWebhook
+
+// webhookTimeout bounds a delivery attempt, slower receivers are retried
+const webhookTimeout = 10 * time.Second
+
+func (p *RedisTaskProcessor) ProcessTaskDeliverWebhook(ctx context.Context, task *asynq.Task) error {
+	var payload db.PayloadDeliverWebhook
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	delivery, err := p.store.GetWebhookDelivery(ctx, payload.DeliveryID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("webhook delivery doesn't exist: %w", asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+
+	// the outbox relay may publish a delivery again after it has been attempted
+	if delivery.Status != db.WebhookDeliveryStatusPending {
+		return nil
+	}
+
+	subscription, err := p.store.GetWebhookSubscription(ctx, delivery.SubscriptionID)
+	if err != nil {
+		return fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+
+	if !subscription.IsActive {
+		_, err = p.store.UpdateWebhookDelivery(ctx, db.UpdateWebhookDeliveryParams{
+			ID:        delivery.ID,
+			Status:    db.WebhookDeliveryStatusFailed,
+			LastError: "webhook subscription is inactive",
+		})
+		if err != nil {
+			return fmt.Errorf("failed to update webhook delivery: %w", err)
+		}
+		return fmt.Errorf("webhook subscription is inactive: %w", asynq.SkipRetry)
+	}
+
+	event, err := p.store.GetDomainEvent(ctx, delivery.EventID)
+	if err != nil {
+		return fmt.Errorf("failed to get event: %w", err)
+	}
+
+	responseStatus, sendErr := p.webhookSender.Send(ctx, subscription.Url, subscription.Secret, delivery.ID, webhook.Event{
+		ID:        event.ID,
+		Type:      event.EventType,
+		CreatedAt: event.CreatedAt,
+		Data:      event.Data,
+	})
+
+	arg := db.UpdateWebhookDeliveryParams{
+		ID:             delivery.ID,
+		Status:         db.WebhookDeliveryStatusSucceeded,
+		ResponseStatus: int32(responseStatus),
+		DeliveredAt:    sql.NullTime{Time: time.Now(), Valid: true},
+	}
+	if sendErr != nil {
+		arg.LastError = sendErr.Error()
+		arg.DeliveredAt = sql.NullTime{}
+
+		// the delivery stays pending while it is retried, failed ones can be replayed
+		arg.Status = db.WebhookDeliveryStatusFailed
+		if willRetry(ctx, sendErr) {
+			arg.Status = db.WebhookDeliveryStatusPending
+		}
+	}
+
+	delivery, err = p.store.UpdateWebhookDelivery(ctx, arg)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook delivery: %w", err)
+	}
+
+	if sendErr != nil {
+		return fmt.Errorf("failed to deliver webhook: %w", sendErr)
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Int64("delivery_id", delivery.ID).
+		Str("event_type", event.EventType).
+		Int32("attempts", delivery.Attempts).
+		Msg("processed task")
+
+	return nil
+}
+
+// retryDelay backs off the webhook deliveries exponentially and the other tasks with the asynq default
+func retryDelay(n int, err error, task *asynq.Task) time.Duration {
+	if task.Type() == TaskDeliverWebhook {
+		return webhook.RetryDelay(n)
+	}
+
+	return asynq.DefaultRetryDelayFunc(n, err, task)
+}
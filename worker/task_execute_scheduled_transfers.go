@@ -0,0 +1,74 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+const TaskExecuteScheduledTransfers = "task:execute_scheduled_transfers"
+
+const (
+	// executeScheduledTransfersInterval is how often due scheduled transfers are looked up
+	executeScheduledTransfersInterval = "@every 1m"
+	// executeScheduledTransfersBatchSize is the max number of schedules executed by one task run
+	executeScheduledTransfersBatchSize = 100
+	// maxScheduledTransferFailures is the number of consecutive insufficient funds
+	// failures after which a scheduled transfer is paused
+	maxScheduledTransferFailures = 3
+)
+
+func (p *RedisTaskProcessor) ProcessTaskExecuteScheduledTransfers(ctx context.Context, task *asynq.Task) error {
+	now := time.Now()
+
+	schedules, err := p.store.ListDueScheduledTransfers(ctx, db.ListDueScheduledTransfersParams{
+		Now:          now,
+		MaxSchedules: executeScheduledTransfersBatchSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list due scheduled transfers: %w", err)
+	}
+
+	for _, schedule := range schedules {
+		result, err := p.store.ExecuteScheduledTransferTx(ctx, db.ExecuteScheduledTransferTxParams{
+			ID:            schedule.ID,
+			Now:           now,
+			MaxFailures:   maxScheduledTransferFailures,
+			RiskEvaluator: p.riskEvaluator,
+		})
+		if err != nil {
+			if errors.Is(err, db.ErrScheduleNotDue) {
+				continue
+			}
+
+			log.Ctx(ctx).Error().
+				Err(err).
+				Int64("scheduled_transfer_id", schedule.ID).
+				Msg("failed to execute scheduled transfer")
+			continue
+		}
+
+		logger := log.Ctx(ctx).Info()
+		if result.Execution.Status == db.ExecutionStatusFailed {
+			logger = log.Ctx(ctx).Warn().Str("error", result.Execution.Error)
+		}
+
+		logger.
+			Int64("scheduled_transfer_id", schedule.ID).
+			Str("execution_status", result.Execution.Status).
+			Str("schedule_status", result.ScheduledTransfer.Status).
+			Time("next_run_at", result.ScheduledTransfer.NextRunAt).
+			Msg("executed scheduled transfer")
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Int("schedules", len(schedules)).
+		Msg("processed task")
+
+	return nil
+}
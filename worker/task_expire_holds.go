@@ -0,0 +1,64 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"time"
+)
+
+const TaskExpireHolds = "task:expire_holds"
+
+const (
+	// expireHoldsInterval is how often expired holds are looked up
+	expireHoldsInterval = "@every 1m"
+	// expireHoldsBatchSize is the max number of holds released by one task run
+	expireHoldsBatchSize = 100
+)
+
+func (p *RedisTaskProcessor) ProcessTaskExpireHolds(ctx context.Context, task *asynq.Task) error {
+	now := time.Now()
+
+	holds, err := p.store.ListExpiredHolds(ctx, db.ListExpiredHoldsParams{
+		Now:      now,
+		MaxHolds: expireHoldsBatchSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list expired holds: %w", err)
+	}
+
+	for _, hold := range holds {
+		_, err := p.store.ExpireHoldTx(ctx, db.ExpireHoldTxParams{
+			HoldID: hold.ID,
+			Now:    now,
+		})
+		if err != nil {
+			// the hold has been captured or voided in the meantime
+			if errors.Is(err, db.ErrHoldNotAuthorized) {
+				continue
+			}
+
+			log.Ctx(ctx).Error().
+				Err(err).
+				Int64("hold_id", hold.ID).
+				Msg("failed to expire hold")
+			continue
+		}
+
+		log.Ctx(ctx).Info().
+			Int64("hold_id", hold.ID).
+			Int64("from_account_id", hold.FromAccountID).
+			Int64("amount", hold.Amount).
+			Msg("expired hold")
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Int("holds", len(holds)).
+		Msg("processed task")
+
+	return nil
+}
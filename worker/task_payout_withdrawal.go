@@ -0,0 +1,97 @@
+package worker
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/payment"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"strconv"
+)
+
+// TaskPayoutWithdrawal pays a pending withdrawal out through the payment rail,
+// the store enqueues it through the outbox in the transaction debiting the account
+const TaskPayoutWithdrawal = db.TaskPayoutWithdrawal
+
+func (p *RedisTaskProcessor) ProcessTaskPayoutWithdrawal(ctx context.Context, task *asynq.Task) error {
+	var payload db.PayloadPayoutWithdrawal
+	if err := json.Unmarshal(task.Payload(), &payload); err != nil {
+		return fmt.Errorf("failed to unmarshal payload: %w", asynq.SkipRetry)
+	}
+
+	withdrawal, err := p.store.GetPayment(ctx, payload.PaymentID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return fmt.Errorf("payment doesn't exist: %w", asynq.SkipRetry)
+		}
+		return fmt.Errorf("failed to get payment: %w", err)
+	}
+
+	// the outbox relay may publish a withdrawal again after it has been paid out
+	if withdrawal.Status != db.PaymentStatusPending {
+		return nil
+	}
+
+	externalRef, err := p.payoutWithdrawal(ctx, withdrawal)
+	if err != nil {
+		return err
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Int64("payment_id", withdrawal.ID).
+		Str("external_ref", externalRef).
+		Msg("processed task")
+
+	return nil
+}
+
+// payoutWithdrawal pays a pending withdrawal out and records the outcome, a declined payout is refunded.
+// It returns the reference of the payout, which is empty when it was declined
+func (p *RedisTaskProcessor) payoutWithdrawal(ctx context.Context, withdrawal db.Payment) (string, error) {
+	account, err := p.store.GetAccount(ctx, withdrawal.AccountID)
+	if err != nil {
+		return "", fmt.Errorf("failed to get account: %w", err)
+	}
+
+	// the rail pays a reference out once, so a payout whose outcome is unknown is retried
+	externalRef, err := p.paymentRail.Payout(ctx, payment.Request{
+		Reference:       strconv.FormatInt(withdrawal.ID, 10),
+		ExternalAccount: withdrawal.ExternalAccount,
+		Amount:          withdrawal.Amount,
+		Currency:        account.Currency,
+	})
+	if err != nil {
+		if !errors.Is(err, payment.ErrDeclined) {
+			return "", fmt.Errorf("failed to pay withdrawal out: %w", err)
+		}
+
+		_, failErr := p.store.FailWithdrawalTx(ctx, db.FailWithdrawalTxParams{
+			PaymentID: withdrawal.ID,
+			LastError: err.Error(),
+		})
+		if failErr != nil {
+			return "", fmt.Errorf("failed to refund declined withdrawal: %w", failErr)
+		}
+
+		log.Ctx(ctx).Warn().
+			Err(err).
+			Int64("payment_id", withdrawal.ID).
+			Msg("refunded declined withdrawal")
+		return "", nil
+	}
+
+	_, err = p.store.CompleteWithdrawalTx(ctx, db.CompleteWithdrawalTxParams{
+		PaymentID:   withdrawal.ID,
+		ExternalRef: externalRef,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to complete withdrawal: %w", err)
+	}
+
+	return externalRef, nil
+}
@@ -0,0 +1,38 @@
+package worker
+
+import (
+	"context"
+	"fmt"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+)
+
+const TaskReconcileLedger = "task:reconcile_ledger"
+
+// reconcileLedgerInterval runs the reconciliation every night at 3 AM
+const reconcileLedgerInterval = "0 3 * * *"
+
+func (p *RedisTaskProcessor) ProcessTaskReconcileLedger(ctx context.Context, task *asynq.Task) error {
+	result, err := p.store.ReconcileLedgerTx(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to reconcile ledger: %w", err)
+	}
+
+	run := result.Run
+	if result.HasDiscrepancies() {
+		log.Ctx(ctx).Error().
+			Int64("reconciliation_run_id", run.ID).
+			Int64("account_discrepancies", run.AccountDiscrepancies).
+			Int64("transfer_discrepancies", run.TransferDiscrepancies).
+			Msg("ledger reconciliation found discrepancies")
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Int64("reconciliation_run_id", run.ID).
+		Int64("accounts_checked", run.AccountsChecked).
+		Int64("transfers_checked", run.TransfersChecked).
+		Msg("processed task")
+
+	return nil
+}
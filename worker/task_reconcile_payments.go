@@ -0,0 +1,123 @@
+package worker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/payment"
+	"github.com/hibiken/asynq"
+	"github.com/rs/zerolog/log"
+	"strconv"
+	"time"
+)
+
+const TaskReconcilePayments = "task:reconcile_payments"
+
+const (
+	// reconcilePaymentsInterval is how often the payments stuck in pending are looked up
+	reconcilePaymentsInterval = "@every 5m"
+	// reconcilePaymentsBatchSize is the max number of payments settled by one task run
+	reconcilePaymentsBatchSize = 100
+	// stalePaymentAge is how long a payment stays pending before it is considered stuck,
+	// e.g. because the server crashed while collecting it or its payout ran out of retries
+	stalePaymentAge = 15 * time.Minute
+)
+
+// ProcessTaskReconcilePayments settles the payments left pending. The rail moves the money of a reference once,
+// so collecting or paying a payment out again returns the outcome of the first attempt when there was one
+func (p *RedisTaskProcessor) ProcessTaskReconcilePayments(ctx context.Context, task *asynq.Task) error {
+	payments, err := p.store.ListStalePendingPayments(ctx, db.ListStalePendingPaymentsParams{
+		CreatedBefore: time.Now().Add(-stalePaymentAge),
+		MaxPayments:   reconcilePaymentsBatchSize,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to list pending payments: %w", err)
+	}
+
+	for _, pendingPayment := range payments {
+		if pendingPayment.Kind == db.PaymentKindDeposit {
+			err = p.collectDeposit(ctx, pendingPayment)
+		} else {
+			_, err = p.payoutWithdrawal(ctx, pendingPayment)
+		}
+		if err != nil {
+			// the payment has been settled in the meantime
+			if errors.Is(err, db.ErrPaymentNotPending) {
+				continue
+			}
+
+			log.Ctx(ctx).Error().
+				Err(err).
+				Int64("payment_id", pendingPayment.ID).
+				Str("kind", pendingPayment.Kind).
+				Msg("failed to reconcile pending payment")
+			continue
+		}
+
+		log.Ctx(ctx).Info().
+			Int64("payment_id", pendingPayment.ID).
+			Str("kind", pendingPayment.Kind).
+			Msg("reconciled pending payment")
+	}
+
+	log.Ctx(ctx).Info().
+		Str("type", task.Type()).
+		Int("payments", len(payments)).
+		Msg("processed task")
+
+	return nil
+}
+
+// collectDeposit finishes a deposit interrupted before or after collecting its money:
+// it credits the account when the rail collected it, refunds it when it cannot be credited,
+// and records the deposit as failed when the rail declines it
+func (p *RedisTaskProcessor) collectDeposit(ctx context.Context, deposit db.Payment) error {
+	account, err := p.store.GetAccount(ctx, deposit.AccountID)
+	if err != nil {
+		return fmt.Errorf("failed to get account: %w", err)
+	}
+
+	audit := db.AuditInfo{Actor: db.AuditActorSystem}
+
+	externalRef, err := p.paymentRail.Collect(ctx, payment.Request{
+		Reference:       strconv.FormatInt(deposit.ID, 10),
+		ExternalAccount: deposit.ExternalAccount,
+		Amount:          deposit.Amount,
+		Currency:        account.Currency,
+	})
+	if err != nil {
+		if !errors.Is(err, payment.ErrDeclined) {
+			return fmt.Errorf("failed to collect deposit: %w", err)
+		}
+
+		_, err = p.store.FailDepositTx(ctx, db.FailDepositTxParams{
+			PaymentID: deposit.ID,
+			LastError: err.Error(),
+			Audit:     audit,
+		})
+		return err
+	}
+
+	_, err = p.store.DepositTx(ctx, db.DepositTxParams{
+		PaymentID:   deposit.ID,
+		ExternalRef: externalRef,
+		Audit:       audit,
+	})
+	if err == nil || errors.Is(err, db.ErrPaymentNotPending) {
+		return err
+	}
+
+	depositErr := err
+	if err = p.paymentRail.Refund(ctx, externalRef); err != nil {
+		return fmt.Errorf("failed to refund deposit %s: %w", externalRef, err)
+	}
+
+	_, err = p.store.FailDepositTx(ctx, db.FailDepositTxParams{
+		PaymentID:   deposit.ID,
+		ExternalRef: externalRef,
+		LastError:   depositErr.Error(),
+		Audit:       audit,
+	})
+	return err
+}
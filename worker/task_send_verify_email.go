@@ -5,40 +5,34 @@ import (
 	"database/sql"
 	"encoding/json"
 	"fmt"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/Dejan91/simple_bank/redact"
 	"github.com/hibiken/asynq"
 	"github.com/rs/zerolog/log"
+	"time"
 )
 
 const TaskSendVerifyEmail = "task:send_verify_email"
 
 type PayloadSendVerifyEmail struct {
 	Username string `json:"username"`
+	TaskMetadata
 }
 
-func (d *RedisTaskDistributor) DistributeTaskSendVerifyEmail(
-	ctx context.Context,
-	payload *PayloadSendVerifyEmail,
-	opts ...asynq.Option,
-) error {
-	jsonPayload, err := json.Marshal(payload)
-	if err != nil {
-		return fmt.Errorf("failed to marshal task payload: %w", err)
-	}
-
-	task := asynq.NewTask(TaskSendVerifyEmail, jsonPayload, opts...)
-	info, err := d.client.EnqueueContext(ctx, task)
-	if err != nil {
-		return fmt.Errorf("failed to enqueue task: %w", err)
+// NewTaskSendVerifyEmail returns the task sending the verification email to a new user,
+// to enqueue through the outbox in the transaction creating the user
+func NewTaskSendVerifyEmail(ctx context.Context, payload *PayloadSendVerifyEmail) db.OutboxTask {
+	ctx, span := startEnqueueSpan(ctx, TaskSendVerifyEmail)
+	defer span.End()
+
+	payload.inject(ctx)
+	return db.OutboxTask{
+		Type:      TaskSendVerifyEmail,
+		Payload:   payload,
+		Queue:     QueueCritical,
+		MaxRetry:  10,
+		ProcessIn: 10 * time.Second,
 	}
-
-	log.Info().
-		Str("type", task.Type()).
-		Bytes("payload", task.Payload()).
-		Str("queue", info.Queue).
-		Int("max_retry", info.MaxRetry).
-		Msg("enqueued task")
-
-	return nil
 }
 
 func (p *RedisTaskProcessor) ProcessTaskSendVerifyEmail(ctx context.Context, task *asynq.Task) error {
@@ -56,10 +50,10 @@ func (p *RedisTaskProcessor) ProcessTaskSendVerifyEmail(ctx context.Context, tas
 	}
 
 	// TODO: Send email to user
-	log.Info().
+	log.Ctx(ctx).Info().
 		Str("type", task.Type()).
-		Bytes("payload", task.Payload()).
-		Str("email", user.Email).
+		Bytes("payload", redact.JSON(task.Payload())).
+		Str("username", user.Username).
 		Msg("processed task")
 
 	return nil
@@ -0,0 +1,69 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	db "github.com/Dejan91/simple_bank/db/sqlc"
+	"github.com/hibiken/asynq"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/Dejan91/simple_bank/worker")
+
+// startEnqueueSpan starts the producer span of a task, the metadata must be injected
+// into the payload with the returned context
+func startEnqueueSpan(ctx context.Context, taskType string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, "enqueue "+taskType,
+		trace.WithSpanKind(trace.SpanKindProducer),
+		trace.WithAttributes(
+			semconv.MessagingSystem("asynq"),
+			semconv.MessagingDestinationName(taskType),
+		),
+	)
+}
+
+// startOutboxEnqueueSpan starts the producer span of the task of an outbox message in the trace
+// of the transaction which wrote the message, messages written without a trace start a new one
+func startOutboxEnqueueSpan(ctx context.Context, message db.Outbox) (context.Context, trace.Span) {
+	var traceContext propagation.MapCarrier
+	if len(message.TraceContext) > 0 {
+		// an invalid trace context just starts a new trace
+		_ = json.Unmarshal(message.TraceContext, &traceContext)
+	}
+
+	if traceContext != nil {
+		ctx = otel.GetTextMapPropagator().Extract(ctx, traceContext)
+	}
+
+	return startEnqueueSpan(ctx, message.TaskType)
+}
+
+// taskTracing continues the trace carried in the payload of a task, periodic tasks start a new trace
+func taskTracing(next asynq.Handler) asynq.Handler {
+	return asynq.HandlerFunc(func(ctx context.Context, task *asynq.Task) error {
+		if metadata := taskMetadata(task); metadata.TraceContext != nil {
+			ctx = otel.GetTextMapPropagator().Extract(ctx, metadata.TraceContext)
+		}
+
+		ctx, span := tracer.Start(ctx, task.Type(),
+			trace.WithSpanKind(trace.SpanKindConsumer),
+			trace.WithAttributes(
+				semconv.MessagingSystem("asynq"),
+				semconv.MessagingDestinationName(task.Type()),
+			),
+		)
+		defer span.End()
+
+		err := next.ProcessTask(ctx, task)
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+
+		return err
+	})
+}